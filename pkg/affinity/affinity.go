@@ -0,0 +1,116 @@
+// Package affinity binds the clients of the TCP and UDP load-balancers, identified by their IP, to a server.
+package affinity
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"net"
+	"strconv"
+)
+
+// Server is a server of a load-balancer, as seen by the affinity.
+type Server struct {
+	// Name is the name of the server, if any.
+	Name   string
+	Weight int
+	Up     bool
+}
+
+// Choose returns the index of the server of the given client IP, among the n servers of a load-balancer,
+// which must not change while it is called.
+// With a nil table, the server is chosen from a hash of the client IP, so that the clients only move when their server goes away.
+// Otherwise, the server bound to the client in the table is chosen if it is still up,
+// or else the one chosen by next, which is then recorded in the table.
+func Choose(table *Table, ip string, n int, server func(index int) Server, next func() (int, error)) (int, error) {
+	if table == nil {
+		return hashIndex(ip, n, server)
+	}
+
+	if key, ok := table.get(ip); ok {
+		for i := 0; i < n; i++ {
+			s := server(i)
+			if serverKey(i, s) == key && s.Up && s.Weight > 0 {
+				return i, nil
+			}
+		}
+	}
+
+	index, err := next()
+	if err != nil {
+		return -1, err
+	}
+
+	table.set(ip, serverKey(index, server(index)))
+	return index, nil
+}
+
+// hashIndex returns the index of the server for the given client IP, using a weighted rendezvous hashing,
+// so that only the clients of a server going down or away are moved to other servers.
+func hashIndex(ip string, n int, server func(index int) Server) (int, error) {
+	if n == 0 {
+		return -1, errors.New("no servers in the pool")
+	}
+
+	best := -1
+	var bestScore float64
+	for i := 0; i < n; i++ {
+		s := server(i)
+		if !s.Up || s.Weight <= 0 {
+			continue
+		}
+
+		score := affinityScore(ip, serverKey(i, s), s.Weight)
+		if best == -1 || score > bestScore {
+			best = i
+			bestScore = score
+		}
+	}
+
+	if best == -1 {
+		return -1, errors.New("all servers are down")
+	}
+	return best, nil
+}
+
+// serverKey identifies a server for the affinity: by its name, or by its index when it has no name.
+func serverKey(index int, s Server) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return "#" + strconv.Itoa(index)
+}
+
+// affinityScore returns the weighted rendezvous hashing score of the server identified by key, for the given client IP.
+func affinityScore(ip, key string, weight int) float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(ip))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+
+	// Mixes the bits of the hash, as the FNV hashes of close inputs are close.
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	// A uniform value in (0, 1), from the 53 upper bits of the hash.
+	u := (float64(x>>11) + 0.5) / (1 << 53)
+
+	return -float64(weight) / math.Log(u)
+}
+
+// ClientIP returns the IP of the given address, or the address itself if it has no port.
+func ClientIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,110 @@
+package affinity
+
+import (
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/generation"
+)
+
+// Table binds the clients, identified by their IP, to the servers, identified by their name,
+// until the clients have not opened any connection or session for the TTL of the table.
+// It can be shared by the load-balancers successively built for a service, so that the clients keep their server across configuration reloads.
+type Table struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*tableEntry
+	lastSweep time.Time
+}
+
+type tableEntry struct {
+	server   string
+	lastSeen time.Time
+}
+
+// NewTable creates a new Table.
+func NewTable(ttl time.Duration) *Table {
+	return &Table{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*tableEntry),
+	}
+}
+
+// TTL returns the TTL of the table.
+func (t *Table) TTL() time.Duration {
+	return t.ttl
+}
+
+// get returns the server bound to the given client, if any, and refreshes the binding.
+func (t *Table) get(ip string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	entry, ok := t.entries[ip]
+	if !ok || now.Sub(entry.lastSeen) >= t.ttl {
+		return "", false
+	}
+
+	entry.lastSeen = now
+	return entry.server, true
+}
+
+func (t *Table) set(ip, server string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.entries[ip] = &tableEntry{server: server, lastSeen: t.now()}
+}
+
+// sweep removes the expired entries, at most once per TTL.
+// It must be called with the lock held.
+func (t *Table) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.ttl {
+		return
+	}
+	t.lastSweep = now
+
+	for ip, entry := range t.entries {
+		if now.Sub(entry.lastSeen) >= t.ttl {
+			delete(t.entries, ip)
+		}
+	}
+}
+
+// Tables holds the affinity tables of the services across configuration reloads,
+// so that the clients keep their server when the load-balancers are rebuilt.
+// A nil Tables gives a new table on each call to Get.
+type Tables struct {
+	tables *generation.Map
+}
+
+// NewTables creates a new Tables.
+func NewTables() *Tables {
+	return &Tables{tables: generation.NewMap()}
+}
+
+// NewGeneration must be called before building the services of a new configuration.
+// It forgets the tables of the services that were not part of the previous configuration.
+func (t *Tables) NewGeneration() {
+	t.tables.NewGeneration()
+}
+
+// Get returns the affinity table of the given service, which is a new one if its TTL has changed.
+func (t *Tables) Get(serviceName string, ttl time.Duration) *Table {
+	if t == nil {
+		return NewTable(ttl)
+	}
+
+	table, _ := t.tables.Get(serviceName, func(value interface{}) bool {
+		return value.(*Table).TTL() == ttl
+	}, func() (interface{}, error) {
+		return NewTable(ttl), nil
+	})
+	return table.(*Table)
+}
@@ -0,0 +1,50 @@
+package affinity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTable(t *testing.T) {
+	now := time.Now()
+	table := NewTable(time.Minute)
+	table.now = func() time.Time { return now }
+
+	table.set("10.0.0.1", "h1")
+	table.set("10.0.0.2", "h2")
+
+	// The clients keep their server, as long as they are active.
+	for i := 0; i < 3; i++ {
+		now = now.Add(30 * time.Second)
+
+		server, ok := table.get("10.0.0.1")
+		assert.True(t, ok)
+		assert.Equal(t, "h1", server)
+	}
+
+	// The binding of an idle client expires, and is removed.
+	_, ok := table.get("10.0.0.2")
+	assert.False(t, ok)
+	assert.Len(t, table.entries, 1)
+}
+
+func TestTables(t *testing.T) {
+	tables := NewTables()
+
+	tables.NewGeneration()
+	foo := tables.Get("foo@file", time.Minute)
+	bar := tables.Get("bar@file", time.Minute)
+
+	// The tables are kept for the next configuration.
+	tables.NewGeneration()
+	assert.Same(t, foo, tables.Get("foo@file", time.Minute))
+
+	// A table is replaced when its TTL changes.
+	assert.NotSame(t, foo, tables.Get("foo@file", time.Hour))
+
+	// The tables of the services absent from the previous configuration are forgotten.
+	tables.NewGeneration()
+	assert.NotSame(t, bar, tables.Get("bar@file", time.Minute))
+}
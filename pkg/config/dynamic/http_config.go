@@ -2,7 +2,9 @@ package dynamic
 
 import (
 	"reflect"
+	"time"
 
+	"github.com/containous/traefik/v2/pkg/tls"
 	"github.com/containous/traefik/v2/pkg/types"
 )
 
@@ -10,10 +12,11 @@ import (
 
 // HTTPConfiguration contains all the HTTP configuration parameters.
 type HTTPConfiguration struct {
-	Routers     map[string]*Router     `json:"routers,omitempty" toml:"routers,omitempty" yaml:"routers,omitempty"`
-	Services    map[string]*Service    `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
-	Middlewares map[string]*Middleware `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
-	Models      map[string]*Model      `json:"models,omitempty" toml:"models,omitempty" yaml:"models,omitempty"`
+	Routers           map[string]*Router           `json:"routers,omitempty" toml:"routers,omitempty" yaml:"routers,omitempty"`
+	Services          map[string]*Service          `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
+	Middlewares       map[string]*Middleware       `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+	Models            map[string]*Model            `json:"models,omitempty" toml:"models,omitempty" yaml:"models,omitempty"`
+	ServersTransports map[string]*ServersTransport `json:"serversTransports,omitempty" toml:"serversTransports,omitempty" yaml:"serversTransports,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -31,6 +34,7 @@ type Service struct {
 	LoadBalancer *ServersLoadBalancer `json:"loadBalancer,omitempty" toml:"loadBalancer,omitempty" yaml:"loadBalancer,omitempty"`
 	Weighted     *WeightedRoundRobin  `json:"weighted,omitempty" toml:"weighted,omitempty" yaml:"weighted,omitempty" label:"-"`
 	Mirroring    *Mirroring           `json:"mirroring,omitempty" toml:"mirroring,omitempty" yaml:"mirroring,omitempty" label:"-"`
+	Failover     *Failover            `json:"failover,omitempty" toml:"failover,omitempty" yaml:"failover,omitempty" label:"-"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -61,6 +65,7 @@ type Mirroring struct {
 	Service     string          `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
 	MaxBodySize *int64          `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
 	Mirrors     []MirrorService `json:"mirrors,omitempty" toml:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+	Compare     *MirrorCompare  `json:"compare,omitempty" toml:"compare,omitempty" yaml:"compare,omitempty" label:"allowEmpty"`
 }
 
 // SetDefaults Default values for a WRRService.
@@ -71,6 +76,31 @@ func (m *Mirroring) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// Failover holds the Failover configuration.
+// The requests are sent to Service, unless all of its servers are down,
+// in which case they are sent to Fallback.
+type Failover struct {
+	Service  string          `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
+	Fallback string          `json:"fallback,omitempty" toml:"fallback,omitempty" yaml:"fallback,omitempty"`
+	Errors   *FailoverErrors `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// FailoverErrors holds the status codes of the Service responses which trigger the Fallback.
+type FailoverErrors struct {
+	Status      []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty"`
+	MaxBodySize *int64   `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+}
+
+// SetDefaults Default values for a FailoverErrors.
+func (f *FailoverErrors) SetDefaults() {
+	var defaultMaxBodySize int64 = -1
+	f.MaxBodySize = &defaultMaxBodySize
+}
+
+// +k8s:deepcopy-gen=true
+
 // MirrorService holds the MirrorService configuration.
 type MirrorService struct {
 	Name    string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
@@ -79,6 +109,23 @@ type MirrorService struct {
 
 // +k8s:deepcopy-gen=true
 
+// MirrorCompare holds the configuration of the comparison of the responses of the mirrors with the response of the main service.
+// The status codes are always compared, the headers and the body only when configured.
+type MirrorCompare struct {
+	Headers     []string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	Body        string   `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty"`
+	IgnorePaths []string `json:"ignorePaths,omitempty" toml:"ignorePaths,omitempty" yaml:"ignorePaths,omitempty"`
+	MaxBodySize *int64   `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty"`
+}
+
+// SetDefaults Default values for a MirrorCompare.
+func (m *MirrorCompare) SetDefaults() {
+	var defaultMaxBodySize int64 = 1 << 20
+	m.MaxBodySize = &defaultMaxBodySize
+}
+
+// +k8s:deepcopy-gen=true
+
 // WeightedRoundRobin is a weighted round robin load-balancer of services.
 type WeightedRoundRobin struct {
 	Services []WRRService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
@@ -120,11 +167,29 @@ type Cookie struct {
 
 // ServersLoadBalancer holds the ServersLoadBalancer configuration.
 type ServersLoadBalancer struct {
-	Sticky             *Sticky             `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty"`
-	Servers            []Server            `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
-	HealthCheck        *HealthCheck        `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	// Strategy is the algorithm used to pick a server for each request:
+	// "wrr" (weighted round robin, the default), "leastrequests" (least outstanding requests),
+	// "p2c" (least outstanding requests between two servers chosen at random),
+	// or "consistenthash" (hash ring keyed on the criterion defined in ConsistentHash).
+	Strategy       string          `json:"strategy,omitempty" toml:"strategy,omitempty" yaml:"strategy,omitempty"`
+	ConsistentHash *ConsistentHash `json:"consistentHash,omitempty" toml:"consistentHash,omitempty" yaml:"consistentHash,omitempty" label:"allowEmpty"`
+	Sticky         *Sticky         `json:"sticky,omitempty" toml:"sticky,omitempty" yaml:"sticky,omitempty" label:"allowEmpty"`
+	Servers        []Server        `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
+	HealthCheck    *HealthCheck    `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	// PassiveHealthCheck enables the ejection of the servers detected as failing from the responses they return.
+	PassiveHealthCheck *PassiveHealthCheck `json:"passiveHealthCheck,omitempty" toml:"passiveHealthCheck,omitempty" yaml:"passiveHealthCheck,omitempty" label:"allowEmpty"`
+	// Hedging sends copies of the requests outstanding for too long to other servers, and keeps the first response.
+	Hedging *Hedging `json:"hedging,omitempty" toml:"hedging,omitempty" yaml:"hedging,omitempty" label:"allowEmpty"`
+	// SlowStart is the duration over which the weight of a server newly added to the load-balancer,
+	// or returning to it after a failed health check, is raised linearly to its full value.
+	SlowStart          types.Duration      `json:"slowStart,omitempty" toml:"slowStart,omitempty" yaml:"slowStart,omitempty"`
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty"`
+	// ServersTransport is the name of the ServersTransport used to reach the servers.
+	// When empty, the default transport defined in the static configuration is used.
+	ServersTransport string `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty"`
+	// ProxyProtocol sends a PROXY protocol header, describing the client connection, on the connections to the servers.
+	ProxyProtocol *ProxyProtocol `json:"proxyProtocol,omitempty" toml:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty" label:"allowEmpty"`
 }
 
 // Mergeable tells if the given service is mergeable.
@@ -152,6 +217,18 @@ func (l *ServersLoadBalancer) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// ConsistentHash defines the criterion used as the hash key of the consistent hashing strategy.
+// If none are set, or if the criterion is missing from a request, the client IP (the request's remote address) is used.
+// All fields are mutually exclusive.
+type ConsistentHash struct {
+	IPStrategy            *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty"`
+	RequestHeaderName     string      `json:"requestHeaderName,omitempty" toml:"requestHeaderName,omitempty" yaml:"requestHeaderName,omitempty"`
+	RequestCookieName     string      `json:"requestCookieName,omitempty" toml:"requestCookieName,omitempty" yaml:"requestCookieName,omitempty"`
+	RequestQueryParameter string      `json:"requestQueryParameter,omitempty" toml:"requestQueryParameter,omitempty" yaml:"requestQueryParameter,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // ResponseForwarding holds configuration for the forward of the response.
 type ResponseForwarding struct {
 	FlushInterval string `json:"flushInterval,omitempty" toml:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
@@ -161,9 +238,15 @@ type ResponseForwarding struct {
 
 // Server holds the server configuration.
 type Server struct {
-	URL    string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty" label:"-"`
-	Scheme string `toml:"-" json:"-" yaml:"-"`
-	Port   string `toml:"-" json:"-" yaml:"-"`
+	URL string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty" label:"-"`
+	// Weight is the relative share of traffic sent to the server, defaulting to 1.
+	// A server with a zero weight does not receive any traffic.
+	Weight *int `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty"`
+	// DNS makes the server stand for all the addresses its host name resolves to,
+	// kept up to date with the DNS records.
+	DNS    *ServerDNS `json:"dns,omitempty" toml:"dns,omitempty" yaml:"dns,omitempty" label:"-"`
+	Scheme string     `toml:"-" json:"-" yaml:"-"`
+	Port   string     `toml:"-" json:"-" yaml:"-"`
 }
 
 // SetDefaults Default values for a Server.
@@ -173,11 +256,26 @@ func (s *Server) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// ServerDNS holds the DNS resolution of the host name of a server.
+type ServerDNS struct {
+	// Type is the type of the records the host name is resolved with:
+	// A (the default) for all its A and AAAA records, or SRV for the targets and ports of its SRV records.
+	Type string `json:"type,omitempty" toml:"type,omitempty" yaml:"type,omitempty"`
+	// RefreshInterval is the interval between two resolutions, defaulting to the TTL of the records.
+	RefreshInterval types.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // HealthCheck holds the HealthCheck configuration.
 type HealthCheck struct {
+	// Mode is the health check protocol: "http" (default), or "grpc" to use the gRPC health checking protocol.
+	Mode   string `json:"mode,omitempty" toml:"mode,omitempty" yaml:"mode,omitempty"`
 	Scheme string `json:"scheme,omitempty" toml:"scheme,omitempty" yaml:"scheme,omitempty"`
 	Path   string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`
-	Port   int    `json:"port,omitempty" toml:"port,omitempty,omitzero" yaml:"port,omitempty"`
+	// ServiceName is the name of the gRPC service whose health is checked in grpc mode (the overall server health if empty).
+	ServiceName string `json:"serviceName,omitempty" toml:"serviceName,omitempty" yaml:"serviceName,omitempty"`
+	Port        int    `json:"port,omitempty" toml:"port,omitempty,omitzero" yaml:"port,omitempty"`
 	// FIXME change string to types.Duration
 	Interval string `json:"interval,omitempty" toml:"interval,omitempty" yaml:"interval,omitempty"`
 	// FIXME change string to types.Duration
@@ -192,3 +290,83 @@ func (h *HealthCheck) SetDefaults() {
 	fr := true
 	h.FollowRedirects = &fr
 }
+
+// +k8s:deepcopy-gen=true
+
+// PassiveHealthCheck holds the passive health check (outlier detection) configuration.
+// A server is ejected from the load-balancer after ConsecutiveFailures failed requests in a row,
+// or when its failure rate over Window reaches FailureRate percent.
+// A request fails when the server returns a 5xx status code, or when it cannot be reached.
+// The ejection lasts BaseEjectionTime multiplied by the number of times the server has been ejected, up to MaxEjectionTime.
+type PassiveHealthCheck struct {
+	ConsecutiveFailures int            `json:"consecutiveFailures,omitempty" toml:"consecutiveFailures,omitempty" yaml:"consecutiveFailures,omitempty"`
+	FailureRate         int            `json:"failureRate,omitempty" toml:"failureRate,omitempty" yaml:"failureRate,omitempty"`
+	MinRequests         int            `json:"minRequests,omitempty" toml:"minRequests,omitempty" yaml:"minRequests,omitempty"`
+	Window              types.Duration `json:"window,omitempty" toml:"window,omitempty" yaml:"window,omitempty"`
+	BaseEjectionTime    types.Duration `json:"baseEjectionTime,omitempty" toml:"baseEjectionTime,omitempty" yaml:"baseEjectionTime,omitempty"`
+	MaxEjectionTime     types.Duration `json:"maxEjectionTime,omitempty" toml:"maxEjectionTime,omitempty" yaml:"maxEjectionTime,omitempty"`
+	MaxEjectionPercent  int            `json:"maxEjectionPercent,omitempty" toml:"maxEjectionPercent,omitempty" yaml:"maxEjectionPercent,omitempty"`
+}
+
+// SetDefaults Default values for a PassiveHealthCheck.
+func (p *PassiveHealthCheck) SetDefaults() {
+	p.ConsecutiveFailures = 5
+	p.MinRequests = 10
+	p.Window = types.Duration(10 * time.Second)
+	p.BaseEjectionTime = types.Duration(30 * time.Second)
+	p.MaxEjectionTime = types.Duration(300 * time.Second)
+	p.MaxEjectionPercent = 50
+}
+
+// +k8s:deepcopy-gen=true
+
+// Hedging holds the request hedging configuration.
+// A copy of a request (a hedge) is sent to another server once the request has been outstanding for Delay,
+// or for the Percentile of the latencies recently observed on the service (but not less than Delay),
+// up to MaxHedges copies, and the first response is kept.
+// Only the requests with an idempotent method and without a body are hedged.
+type Hedging struct {
+	Delay      types.Duration `json:"delay,omitempty" toml:"delay,omitempty" yaml:"delay,omitempty"`
+	Percentile float64        `json:"percentile,omitempty" toml:"percentile,omitempty" yaml:"percentile,omitempty"`
+	MaxHedges  int            `json:"maxHedges,omitempty" toml:"maxHedges,omitempty" yaml:"maxHedges,omitempty"`
+}
+
+// SetDefaults Default values for a Hedging.
+func (h *Hedging) SetDefaults() {
+	h.MaxHedges = 1
+}
+
+// +k8s:deepcopy-gen=true
+
+// ServersTransport options to configure communication between Traefik and the servers.
+type ServersTransport struct {
+	ServerName          string              `json:"serverName,omitempty" toml:"serverName,omitempty" yaml:"serverName,omitempty"`
+	InsecureSkipVerify  bool                `json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	RootCAs             []tls.FileOrContent `json:"rootCAs,omitempty" toml:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
+	Certificates        tls.Certificates    `json:"certificates,omitempty" toml:"certificates,omitempty" yaml:"certificates,omitempty"`
+	MaxIdleConnsPerHost int                 `json:"maxIdleConnsPerHost,omitempty" toml:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty"`
+	ForwardingTimeouts  *ForwardingTimeouts `json:"forwardingTimeouts,omitempty" toml:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty"`
+	DisableHTTP2        bool                `json:"disableHTTP2,omitempty" toml:"disableHTTP2,omitempty" yaml:"disableHTTP2,omitempty"`
+}
+
+// SetDefaults Default values for a ServersTransport.
+func (t *ServersTransport) SetDefaults() {
+	t.MaxIdleConnsPerHost = 200
+	t.ForwardingTimeouts = &ForwardingTimeouts{}
+	t.ForwardingTimeouts.SetDefaults()
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardingTimeouts contains timeout configurations for forwarding requests to the backend servers.
+type ForwardingTimeouts struct {
+	DialTimeout           types.Duration `json:"dialTimeout,omitempty" toml:"dialTimeout,omitempty" yaml:"dialTimeout,omitempty"`
+	ResponseHeaderTimeout types.Duration `json:"responseHeaderTimeout,omitempty" toml:"responseHeaderTimeout,omitempty" yaml:"responseHeaderTimeout,omitempty"`
+	IdleConnTimeout       types.Duration `json:"idleConnTimeout,omitempty" toml:"idleConnTimeout,omitempty" yaml:"idleConnTimeout,omitempty"`
+}
+
+// SetDefaults sets the default values.
+func (f *ForwardingTimeouts) SetDefaults() {
+	f.DialTimeout = types.Duration(30 * time.Second)
+	f.IdleConnTimeout = types.Duration(90 * time.Second)
+}
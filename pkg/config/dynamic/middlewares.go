@@ -16,28 +16,33 @@ import (
 
 // Middleware holds the Middleware configuration.
 type Middleware struct {
-	AddPrefix         *AddPrefix         `json:"addPrefix,omitempty" toml:"addPrefix,omitempty" yaml:"addPrefix,omitempty"`
-	StripPrefix       *StripPrefix       `json:"stripPrefix,omitempty" toml:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
-	StripPrefixRegex  *StripPrefixRegex  `json:"stripPrefixRegex,omitempty" toml:"stripPrefixRegex,omitempty" yaml:"stripPrefixRegex,omitempty"`
-	ReplacePath       *ReplacePath       `json:"replacePath,omitempty" toml:"replacePath,omitempty" yaml:"replacePath,omitempty"`
-	ReplacePathRegex  *ReplacePathRegex  `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty"`
-	Chain             *Chain             `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty"`
-	IPWhiteList       *IPWhiteList       `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty"`
-	Headers           *Headers           `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
-	Errors            *ErrorPage         `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty"`
-	RateLimit         *RateLimit         `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
-	RedirectRegex     *RedirectRegex     `json:"redirectRegex,omitempty" toml:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty"`
-	RedirectScheme    *RedirectScheme    `json:"redirectScheme,omitempty" toml:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty"`
-	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
-	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty"`
-	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
-	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty"`
-	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty"`
-	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
-	Compress          *Compress          `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty"`
-	PassTLSClientCert *PassTLSClientCert `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty"`
-	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty"`
-	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty"`
+	AddPrefix           *AddPrefix           `json:"addPrefix,omitempty" toml:"addPrefix,omitempty" yaml:"addPrefix,omitempty"`
+	StripPrefix         *StripPrefix         `json:"stripPrefix,omitempty" toml:"stripPrefix,omitempty" yaml:"stripPrefix,omitempty"`
+	StripPrefixRegex    *StripPrefixRegex    `json:"stripPrefixRegex,omitempty" toml:"stripPrefixRegex,omitempty" yaml:"stripPrefixRegex,omitempty"`
+	ReplacePath         *ReplacePath         `json:"replacePath,omitempty" toml:"replacePath,omitempty" yaml:"replacePath,omitempty"`
+	ReplacePathRegex    *ReplacePathRegex    `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty"`
+	Chain               *Chain               `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty"`
+	IPWhiteList         *IPWhiteList         `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty"`
+	Headers             *Headers             `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty"`
+	Errors              *ErrorPage           `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty"`
+	RateLimit           *RateLimit           `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	RedirectRegex       *RedirectRegex       `json:"redirectRegex,omitempty" toml:"redirectRegex,omitempty" yaml:"redirectRegex,omitempty"`
+	RedirectScheme      *RedirectScheme      `json:"redirectScheme,omitempty" toml:"redirectScheme,omitempty" yaml:"redirectScheme,omitempty"`
+	BasicAuth           *BasicAuth           `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+	DigestAuth          *DigestAuth          `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty"`
+	ForwardAuth         *ForwardAuth         `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
+	JWT                 *JWT                 `json:"jwt,omitempty" toml:"jwt,omitempty" yaml:"jwt,omitempty"`
+	OIDC                *OIDC                `json:"oidc,omitempty" toml:"oidc,omitempty" yaml:"oidc,omitempty"`
+	APIKey              *APIKey              `json:"apiKey,omitempty" toml:"apiKey,omitempty" yaml:"apiKey,omitempty"`
+	InFlightReq         *InFlightReq         `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty"`
+	AdaptiveInFlightReq *AdaptiveInFlightReq `json:"adaptiveInFlightReq,omitempty" toml:"adaptiveInFlightReq,omitempty" yaml:"adaptiveInFlightReq,omitempty" label:"allowEmpty"`
+	Buffering           *Buffering           `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty"`
+	Cache               *Cache               `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" label:"allowEmpty"`
+	CircuitBreaker      *CircuitBreaker      `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty"`
+	Compress            *Compress            `json:"compress,omitempty" toml:"compress,omitempty" yaml:"compress,omitempty" label:"allowEmpty"`
+	PassTLSClientCert   *PassTLSClientCert   `json:"passTLSClientCert,omitempty" toml:"passTLSClientCert,omitempty" yaml:"passTLSClientCert,omitempty"`
+	Retry               *Retry               `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty"`
+	ContentType         *ContentType         `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -64,6 +69,52 @@ type AddPrefix struct {
 
 // +k8s:deepcopy-gen=true
 
+// APIKey holds the API key authentication configuration.
+type APIKey struct {
+	// Keys is the list of the keys, as consumer:hash entries where hash is the hex encoded SHA-256 of the key,
+	// optionally followed by the name of the rate limiting tier of the key, as consumer:hash:tier.
+	Keys []string `json:"keys,omitempty" toml:"keys,omitempty" yaml:"keys,omitempty"`
+	// KeysFile is the path of a file holding keys, one per line, which is reloaded when it changes.
+	KeysFile string `json:"keysFile,omitempty" toml:"keysFile,omitempty" yaml:"keysFile,omitempty"`
+	// Header is the name of the request header holding the key.
+	Header string `json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty" export:"true"`
+	// Query is the name of the query parameter holding the key, looked up when the header is not set.
+	Query string `json:"query,omitempty" toml:"query,omitempty" yaml:"query,omitempty" export:"true"`
+	// HeaderField is the name of the request header set to the name of the consumer.
+	HeaderField string `json:"headerField,omitempty" toml:"headerField,omitempty" yaml:"headerField,omitempty" export:"true"`
+	// RemoveKey removes the key from the request header and query parameter before forwarding the request.
+	RemoveKey bool `json:"removeKey,omitempty" toml:"removeKey,omitempty" yaml:"removeKey,omitempty" export:"true"`
+	// Tiers defines the rate limits of the consumers, by tier name.
+	Tiers map[string]*APIKeyTier `json:"tiers,omitempty" toml:"tiers,omitempty" yaml:"tiers,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an APIKey.
+func (a *APIKey) SetDefaults() {
+	a.Header = "X-Api-Key"
+	a.HeaderField = "X-Api-Consumer"
+}
+
+// +k8s:deepcopy-gen=true
+
+// APIKeyTier holds the rate limit of the consumers of a tier, applied to each consumer like the RateLimit middleware.
+type APIKeyTier struct {
+	// Average is the maximum rate, by default in requests/s, allowed for each consumer.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+	// Period, in combination with Average, defines the actual maximum rate. It defaults to a second.
+	Period types.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+	// Burst is the maximum number of requests allowed to arrive in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an APIKeyTier.
+func (a *APIKeyTier) SetDefaults() {
+	a.Burst = 1
+	a.Period = types.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
 // Auth holds the authentication configuration (BASIC, DIGEST, users).
 type Auth struct {
 	Basic   *BasicAuth   `json:"basic,omitempty" toml:"basic,omitempty" yaml:"basic,omitempty" export:"true"`
@@ -95,6 +146,49 @@ type Buffering struct {
 
 // +k8s:deepcopy-gen=true
 
+// Cache holds the cache middleware configuration.
+// This middleware stores the responses of the services, and serves them back as long as the HTTP caching rules (RFC 7234) allow it.
+type Cache struct {
+	// MaxSize is the maximum size, in bytes, of the responses kept in memory.
+	MaxSize int64 `json:"maxSize,omitempty" toml:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+	// MaxEntrySize is the maximum size, in bytes, of the body of a stored response.
+	MaxEntrySize int64       `json:"maxEntrySize,omitempty" toml:"maxEntrySize,omitempty" yaml:"maxEntrySize,omitempty"`
+	Disk         *CacheDisk  `json:"disk,omitempty" toml:"disk,omitempty" yaml:"disk,omitempty"`
+	Purge        *CachePurge `json:"purge,omitempty" toml:"purge,omitempty" yaml:"purge,omitempty"`
+}
+
+// SetDefaults sets the default values on a Cache.
+func (c *Cache) SetDefaults() {
+	c.MaxSize = 64 * 1024 * 1024
+	c.MaxEntrySize = 1024 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
+// CacheDisk holds the configuration of the disk store of a cache,
+// which keeps the responses evicted from memory, and across restarts.
+type CacheDisk struct {
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`
+	// MaxSize is the maximum size, in bytes, of the responses kept on disk.
+	MaxSize int64 `json:"maxSize,omitempty" toml:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+}
+
+// SetDefaults sets the default values on a CacheDisk.
+func (c *CacheDisk) SetDefaults() {
+	c.MaxSize = 1024 * 1024 * 1024
+}
+
+// +k8s:deepcopy-gen=true
+
+// CachePurge holds the configuration of the PURGE requests, which remove a response from a cache.
+type CachePurge struct {
+	// SourceRange is the list of the IPs, or IP ranges, allowed to send PURGE requests.
+	SourceRange []string    `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty"  label:"allowEmpty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Chain holds a chain of middlewares.
 type Chain struct {
 	Middlewares []string `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty"`
@@ -142,6 +236,36 @@ type ForwardAuth struct {
 	TLS                 *ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty"`
 	TrustForwardHeader  bool       `json:"trustForwardHeader,omitempty" toml:"trustForwardHeader,omitempty" yaml:"trustForwardHeader,omitempty" export:"true"`
 	AuthResponseHeaders []string   `json:"authResponseHeaders,omitempty" toml:"authResponseHeaders,omitempty" yaml:"authResponseHeaders,omitempty"`
+	// AuthRequestHeaders is the list of the request headers sent to the authentication server.
+	// All the headers are sent if empty.
+	AuthRequestHeaders []string `json:"authRequestHeaders,omitempty" toml:"authRequestHeaders,omitempty" yaml:"authRequestHeaders,omitempty"`
+	// Method is the method of the requests to the authentication server (default: GET).
+	Method string `json:"method,omitempty" toml:"method,omitempty" yaml:"method,omitempty" export:"true"`
+	// ForwardBody sends the request body to the authentication server, up to MaxBodySize bytes.
+	ForwardBody bool              `json:"forwardBody,omitempty" toml:"forwardBody,omitempty" yaml:"forwardBody,omitempty" export:"true"`
+	MaxBodySize int64             `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	Cache       *ForwardAuthCache `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" label:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardAuthCache holds the configuration of the cache of the decisions of the authentication server.
+type ForwardAuthCache struct {
+	// TTL is how long the decisions are cached.
+	TTL types.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty" export:"true"`
+	// MaxEntries is the maximum number of cached decisions.
+	MaxEntries int `json:"maxEntries,omitempty" toml:"maxEntries,omitempty" yaml:"maxEntries,omitempty" export:"true"`
+	// CredentialHeaders is the list of the request headers holding the credentials, which the decisions are cached for.
+	CredentialHeaders []string `json:"credentialHeaders,omitempty" toml:"credentialHeaders,omitempty" yaml:"credentialHeaders,omitempty"`
+	// KeyHeaders is the list of the other request headers the decisions depend on, which the decisions are cached for too.
+	KeyHeaders []string `json:"keyHeaders,omitempty" toml:"keyHeaders,omitempty" yaml:"keyHeaders,omitempty"`
+}
+
+// SetDefaults sets the default values on a ForwardAuthCache.
+func (f *ForwardAuthCache) SetDefaults() {
+	f.TTL = types.Duration(time.Minute)
+	f.MaxEntries = 10000
+	f.CredentialHeaders = []string{"Authorization", "Cookie"}
 }
 
 // +k8s:deepcopy-gen=true
@@ -288,6 +412,112 @@ type InFlightReq struct {
 
 // +k8s:deepcopy-gen=true
 
+// AdaptiveInFlightReq limits the number of requests being processed and served concurrently,
+// with a limit adjusted from the observed latencies.
+type AdaptiveInFlightReq struct {
+	// Algorithm is the algorithm adjusting the limit: gradient (the default), or vegas.
+	Algorithm string `json:"algorithm,omitempty" toml:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	// InitialLimit is the limit used until enough latencies are observed.
+	InitialLimit int64 `json:"initialLimit,omitempty" toml:"initialLimit,omitempty" yaml:"initialLimit,omitempty"`
+	MinLimit     int64 `json:"minLimit,omitempty" toml:"minLimit,omitempty" yaml:"minLimit,omitempty"`
+	MaxLimit     int64 `json:"maxLimit,omitempty" toml:"maxLimit,omitempty" yaml:"maxLimit,omitempty"`
+	// MaxQueue is the number of requests above the limit waiting for a slot, instead of being rejected right away.
+	MaxQueue     int64          `json:"maxQueue,omitempty" toml:"maxQueue,omitempty" yaml:"maxQueue,omitempty"`
+	QueueTimeout types.Duration `json:"queueTimeout,omitempty" toml:"queueTimeout,omitempty" yaml:"queueTimeout,omitempty"`
+
+	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty"`
+}
+
+// SetDefaults sets the default values on an AdaptiveInFlightReq.
+func (a *AdaptiveInFlightReq) SetDefaults() {
+	a.Algorithm = "gradient"
+	a.InitialLimit = 20
+	a.MinLimit = 1
+	a.MaxLimit = 1000
+	a.QueueTimeout = types.Duration(time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// JWT holds the JWT middleware configuration.
+// This middleware validates the JSON Web Token sent as a bearer token in the Authorization header.
+type JWT struct {
+	// Keys is the list of the PEM encoded public keys, or certificates, the tokens can be signed with.
+	Keys []string `json:"keys,omitempty" toml:"keys,omitempty" yaml:"keys,omitempty"`
+	// JWKSFile is the path of a file holding a JSON Web Key Set (RFC 7517).
+	JWKSFile string `json:"jwksFile,omitempty" toml:"jwksFile,omitempty" yaml:"jwksFile,omitempty"`
+	// JWKSURL is the URL of a JSON Web Key Set, fetched again every JWKSRefreshInterval,
+	// and when a token is signed with an unknown key.
+	JWKSURL             string         `json:"jwksURL,omitempty" toml:"jwksURL,omitempty" yaml:"jwksURL,omitempty"`
+	JWKSRefreshInterval types.Duration `json:"jwksRefreshInterval,omitempty" toml:"jwksRefreshInterval,omitempty" yaml:"jwksRefreshInterval,omitempty"`
+	// Issuer is the expected value of the iss claim.
+	Issuer string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// Audience is the value the aud claim must contain.
+	Audience string `json:"audience,omitempty" toml:"audience,omitempty" yaml:"audience,omitempty"`
+	// ClockSkew is the leeway given to the exp, nbf and iat claims.
+	ClockSkew types.Duration `json:"clockSkew,omitempty" toml:"clockSkew,omitempty" yaml:"clockSkew,omitempty"`
+	// AllowMissingExpiration accepts the tokens without exp claim, which are otherwise rejected.
+	AllowMissingExpiration bool `json:"allowMissingExpiration,omitempty" toml:"allowMissingExpiration,omitempty" yaml:"allowMissingExpiration,omitempty"`
+	// RequiredScopes is the list of the scopes the scope (or scp) claim must contain.
+	RequiredScopes []string `json:"requiredScopes,omitempty" toml:"requiredScopes,omitempty" yaml:"requiredScopes,omitempty"`
+	// RequiredClaims maps claims to the value they must be equal to, or contain when they are arrays.
+	// Nested claims are separated by dots, such as realm_access.roles.
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty" toml:"requiredClaims,omitempty" yaml:"requiredClaims,omitempty"`
+	// ClaimsHeaders maps the names of request headers to the claims they are set to, before forwarding the request.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty" toml:"claimsHeaders,omitempty" yaml:"claimsHeaders,omitempty"`
+	Realm         string            `json:"realm,omitempty" toml:"realm,omitempty" yaml:"realm,omitempty"`
+}
+
+// SetDefaults sets the default values on a JWT.
+func (j *JWT) SetDefaults() {
+	j.JWKSRefreshInterval = types.Duration(time.Hour)
+	j.ClockSkew = types.Duration(time.Minute)
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDC holds the OpenID Connect middleware configuration.
+type OIDC struct {
+	// Issuer is the URL of the OpenID provider, which is discovered from its /.well-known/openid-configuration document.
+	Issuer       string   `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty"`
+	ClientID     string   `json:"clientID,omitempty" toml:"clientID,omitempty" yaml:"clientID,omitempty"`
+	ClientSecret string   `json:"clientSecret,omitempty" toml:"clientSecret,omitempty" yaml:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty" toml:"scopes,omitempty" yaml:"scopes,omitempty"`
+	// CallbackPath is the path the provider redirects the users to once authenticated.
+	CallbackPath string `json:"callbackPath,omitempty" toml:"callbackPath,omitempty" yaml:"callbackPath,omitempty"`
+	// LogoutPath is the path ending the sessions, if any.
+	LogoutPath string `json:"logoutPath,omitempty" toml:"logoutPath,omitempty" yaml:"logoutPath,omitempty"`
+	// Secret is the key the session cookies are encrypted with.
+	Secret          string         `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty"`
+	CookieName      string         `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty"`
+	CookieDomain    string         `json:"cookieDomain,omitempty" toml:"cookieDomain,omitempty" yaml:"cookieDomain,omitempty"`
+	CookiePath      string         `json:"cookiePath,omitempty" toml:"cookiePath,omitempty" yaml:"cookiePath,omitempty"`
+	SessionLifetime types.Duration `json:"sessionLifetime,omitempty" toml:"sessionLifetime,omitempty" yaml:"sessionLifetime,omitempty"`
+	// AllowedEmailDomains is the list of the domains the email claim can belong to.
+	AllowedEmailDomains []string `json:"allowedEmailDomains,omitempty" toml:"allowedEmailDomains,omitempty" yaml:"allowedEmailDomains,omitempty"`
+	// AllowedGroups is the list of the groups, one of which the GroupsClaim claim must contain.
+	AllowedGroups []string `json:"allowedGroups,omitempty" toml:"allowedGroups,omitempty" yaml:"allowedGroups,omitempty"`
+	GroupsClaim   string   `json:"groupsClaim,omitempty" toml:"groupsClaim,omitempty" yaml:"groupsClaim,omitempty"`
+	// RequiredClaims maps claims to the value they must be equal to, or contain when they are arrays.
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty" toml:"requiredClaims,omitempty" yaml:"requiredClaims,omitempty"`
+	// ClaimsHeaders maps the names of request headers to the claims they are set to, before forwarding the request.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty" toml:"claimsHeaders,omitempty" yaml:"claimsHeaders,omitempty"`
+	// ForwardAccessToken forwards the access token in the Authorization header of the request.
+	ForwardAccessToken bool `json:"forwardAccessToken,omitempty" toml:"forwardAccessToken,omitempty" yaml:"forwardAccessToken,omitempty"`
+}
+
+// SetDefaults sets the default values on an OIDC.
+func (o *OIDC) SetDefaults() {
+	o.Scopes = []string{"openid", "profile", "email"}
+	o.CallbackPath = "/oauth2/callback"
+	o.CookieName = "_traefik_oidc"
+	o.CookiePath = "/"
+	o.SessionLifetime = types.Duration(24 * time.Hour)
+	o.GroupsClaim = "groups"
+}
+
+// +k8s:deepcopy-gen=true
+
 // PassTLSClientCert holds the TLS client cert headers configuration.
 type PassTLSClientCert struct {
 	PEM  bool                      `json:"pem,omitempty" toml:"pem,omitempty" yaml:"pem,omitempty"`
@@ -324,6 +554,10 @@ type RateLimit struct {
 	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty"`
 
 	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty"`
+
+	// Redis is the Redis server the buckets are stored in, to share them between the Traefik instances.
+	// The buckets are kept in memory when it is not set, or cannot be reached.
+	Redis *RateLimitRedis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values on a RateLimit.
@@ -334,6 +568,22 @@ func (r *RateLimit) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// RateLimitRedis holds the configuration of the Redis server the buckets of a RateLimit are stored in.
+type RateLimitRedis struct {
+	Endpoints []string `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	Password  string   `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty"`
+	// KeyPrefix is the prefix of the keys of the buckets, which are followed by the middleware name and the source.
+	KeyPrefix string `json:"keyPrefix,omitempty" toml:"keyPrefix,omitempty" yaml:"keyPrefix,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a RateLimitRedis.
+func (r *RateLimitRedis) SetDefaults() {
+	r.Endpoints = []string{"127.0.0.1:6379"}
+	r.KeyPrefix = "traefik/ratelimit"
+}
+
+// +k8s:deepcopy-gen=true
+
 // RedirectRegex holds the redirection configuration.
 type RedirectRegex struct {
 	Regex       string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty"`
@@ -370,6 +620,33 @@ type ReplacePathRegex struct {
 // Retry holds the retry configuration.
 type Retry struct {
 	Attempts int `json:"attempts,omitempty" toml:"attempts,omitempty" yaml:"attempts,omitempty" export:"true"`
+	// InitialInterval is the wait before the first retry, which then grows exponentially with each retry.
+	// The retries are immediate when it is not set.
+	InitialInterval types.Duration `json:"initialInterval,omitempty" toml:"initialInterval,omitempty" yaml:"initialInterval,omitempty" export:"true"`
+	// Status is the list of the backend response status codes (or ranges) which are retried.
+	Status []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty" export:"true"`
+	// Methods is the list of the request methods which are retried once the request was sent to the backend.
+	// It defaults to the idempotent methods.
+	Methods []string `json:"methods,omitempty" toml:"methods,omitempty" yaml:"methods,omitempty" export:"true"`
+	// PerTryTimeout is the maximum duration of each attempt.
+	PerTryTimeout types.Duration `json:"perTryTimeout,omitempty" toml:"perTryTimeout,omitempty" yaml:"perTryTimeout,omitempty" export:"true"`
+	Budget        *RetryBudget   `json:"budget,omitempty" toml:"budget,omitempty" yaml:"budget,omitempty" label:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RetryBudget caps the number of retries, relative to the number of requests.
+type RetryBudget struct {
+	// Percent is the maximum number of retries, as a percentage of the requests of the last 10 seconds.
+	Percent int `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty" export:"true"`
+	// MinRetriesPerSecond is the number of retries per second which are always allowed, whatever the traffic.
+	MinRetriesPerSecond int `json:"minRetriesPerSecond,omitempty" toml:"minRetriesPerSecond,omitempty" yaml:"minRetriesPerSecond,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a RetryBudget.
+func (r *RetryBudget) SetDefaults() {
+	r.Percent = 20
+	r.MinRetriesPerSecond = 10
 }
 
 // +k8s:deepcopy-gen=true
@@ -2,6 +2,7 @@ package dynamic
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/types"
 )
@@ -20,6 +21,7 @@ type TCPConfiguration struct {
 type TCPService struct {
 	LoadBalancer *TCPServersLoadBalancer `json:"loadBalancer,omitempty" toml:"loadBalancer,omitempty" yaml:"loadBalancer,omitempty"`
 	Weighted     *TCPWeightedRoundRobin  `json:"weighted,omitempty" toml:"weighted,omitempty" yaml:"weighted,omitempty" label:"-"`
+	Mirroring    *TCPMirroring           `json:"mirroring,omitempty" toml:"mirroring,omitempty" yaml:"mirroring,omitempty" label:"-"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -27,6 +29,7 @@ type TCPService struct {
 // TCPWeightedRoundRobin is a weighted round robin tcp load-balancer of services.
 type TCPWeightedRoundRobin struct {
 	Services []TCPWRRService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
+	Affinity *TCPAffinity    `json:"affinity,omitempty" toml:"affinity,omitempty" yaml:"affinity,omitempty" label:"allowEmpty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -45,6 +48,24 @@ func (w *TCPWRRService) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// TCPMirroring holds the TCP Mirroring configuration.
+// The data sent by the clients is duplicated to the mirrors, and the data sent back by the mirrors is discarded.
+type TCPMirroring struct {
+	Service string             `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty"`
+	Mirrors []TCPMirrorService `json:"mirrors,omitempty" toml:"mirrors,omitempty" yaml:"mirrors,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPMirrorService holds the TCP mirror configuration.
+// Percent is the percentage of the connections mirrored to the service.
+type TCPMirrorService struct {
+	Name    string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty"`
+	Percent int    `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // TCPRouter holds the router configuration.
 type TCPRouter struct {
 	EntryPoints []string            `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty"`
@@ -72,8 +93,12 @@ type TCPServersLoadBalancer struct {
 	// connection, to close the reading capability as well, hence fully terminating the
 	// connection. It is a duration in milliseconds, defaulting to 100. A negative value
 	// means an infinite deadline (i.e. the reading capability is never closed).
-	TerminationDelay *int        `json:"terminationDelay,omitempty" toml:"terminationDelay,omitempty" yaml:"terminationDelay,omitempty"`
-	Servers          []TCPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
+	TerminationDelay *int            `json:"terminationDelay,omitempty" toml:"terminationDelay,omitempty" yaml:"terminationDelay,omitempty"`
+	Servers          []TCPServer     `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
+	HealthCheck      *TCPHealthCheck `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty" label:"allowEmpty"`
+	Affinity         *TCPAffinity    `json:"affinity,omitempty" toml:"affinity,omitempty" yaml:"affinity,omitempty" label:"allowEmpty"`
+	// ProxyProtocol sends a PROXY protocol header, describing the client connection, to the servers.
+	ProxyProtocol *ProxyProtocol `json:"proxyProtocol,omitempty" toml:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty" label:"allowEmpty"`
 }
 
 // SetDefaults Default values for a TCPServersLoadBalancer.
@@ -101,8 +126,63 @@ func (l *TCPServersLoadBalancer) Mergeable(loadBalancer *TCPServersLoadBalancer)
 
 // +k8s:deepcopy-gen=true
 
+// TCPHealthCheck holds the active health check configuration of a TCP load-balancer.
+// A server is healthy when a connection can be opened to it,
+// and, if Send is set, when it answers the Send payload with a response containing Expect.
+// A server is removed from the load-balancer after UnhealthyThreshold failed checks in a row,
+// and returns to it after HealthyThreshold successful checks in a row.
+type TCPHealthCheck struct {
+	Port               int            `json:"port,omitempty" toml:"port,omitempty,omitzero" yaml:"port,omitempty"`
+	Interval           types.Duration `json:"interval,omitempty" toml:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout            types.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
+	UnhealthyThreshold int            `json:"unhealthyThreshold,omitempty" toml:"unhealthyThreshold,omitempty" yaml:"unhealthyThreshold,omitempty"`
+	HealthyThreshold   int            `json:"healthyThreshold,omitempty" toml:"healthyThreshold,omitempty" yaml:"healthyThreshold,omitempty"`
+	Send               string         `json:"send,omitempty" toml:"send,omitempty" yaml:"send,omitempty"`
+	Expect             string         `json:"expect,omitempty" toml:"expect,omitempty" yaml:"expect,omitempty"`
+}
+
+// SetDefaults Default values for a TCPHealthCheck.
+func (h *TCPHealthCheck) SetDefaults() {
+	h.Interval = types.Duration(30 * time.Second)
+	h.Timeout = types.Duration(5 * time.Second)
+	h.UnhealthyThreshold = 1
+	h.HealthyThreshold = 1
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPAffinity holds the client IP affinity configuration of a TCP load-balancer.
+// The connections of a client are sent to the server chosen from a hash of the client IP,
+// or, when TTL is set, to the server chosen for the first connection of the client,
+// until the client has not opened any connection for TTL.
+// In both cases, a client is moved to another server when its server is down or removed.
+type TCPAffinity struct {
+	TTL types.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ProxyProtocol holds the PROXY protocol configuration of the connections to the servers.
+type ProxyProtocol struct {
+	// Version is the version of the PROXY protocol header: 1 (text) or 2 (binary).
+	Version int `json:"version,omitempty" toml:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// SetDefaults Default values for a ProxyProtocol.
+func (p *ProxyProtocol) SetDefaults() {
+	p.Version = 2
+}
+
+// +k8s:deepcopy-gen=true
+
 // TCPServer holds a TCP Server configuration.
 type TCPServer struct {
 	Address string `json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" label:"-"`
-	Port    string `toml:"-" json:"-" yaml:"-"`
+	// Weight is the relative share of connections sent to the server, defaulting to 1.
+	// A server with a zero weight does not receive any connection.
+	Weight *int `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty"`
+	// DNS makes the server stand for all the addresses its host name resolves to,
+	// kept up to date with the DNS records.
+	DNS  *ServerDNS `json:"dns,omitempty" toml:"dns,omitempty" yaml:"dns,omitempty" label:"-"`
+	Port string     `toml:"-" json:"-" yaml:"-"`
 }
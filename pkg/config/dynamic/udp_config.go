@@ -2,6 +2,9 @@ package dynamic
 
 import (
 	"reflect"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/types"
 )
 
 // +k8s:deepcopy-gen=true
@@ -25,6 +28,7 @@ type UDPService struct {
 // UDPWeightedRoundRobin is a weighted round robin UDP load-balancer of services.
 type UDPWeightedRoundRobin struct {
 	Services []UDPWRRService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty"`
+	Affinity *UDPAffinity    `json:"affinity,omitempty" toml:"affinity,omitempty" yaml:"affinity,omitempty" label:"allowEmpty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -53,7 +57,9 @@ type UDPRouter struct {
 
 // UDPServersLoadBalancer defines the configuration for a load-balancer of UDP servers.
 type UDPServersLoadBalancer struct {
-	Servers []UDPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
+	Servers     []UDPServer     `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server"`
+	HealthCheck *UDPHealthCheck `json:"healthCheck,omitempty" toml:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	Affinity    *UDPAffinity    `json:"affinity,omitempty" toml:"affinity,omitempty" yaml:"affinity,omitempty" label:"allowEmpty"`
 }
 
 // Mergeable reports whether the given load-balancer can be merged with the receiver.
@@ -75,8 +81,50 @@ func (l *UDPServersLoadBalancer) Mergeable(loadBalancer *UDPServersLoadBalancer)
 
 // +k8s:deepcopy-gen=true
 
+// UDPHealthCheck holds the active health check configuration of a UDP load-balancer.
+// A server is healthy when it answers the Send payload, which is mandatory,
+// with a response containing Expect (any response if Expect is empty).
+// A server is removed from the load-balancer after UnhealthyThreshold failed checks in a row,
+// and returns to it after HealthyThreshold successful checks in a row.
+type UDPHealthCheck struct {
+	Port               int            `json:"port,omitempty" toml:"port,omitempty,omitzero" yaml:"port,omitempty"`
+	Interval           types.Duration `json:"interval,omitempty" toml:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout            types.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
+	UnhealthyThreshold int            `json:"unhealthyThreshold,omitempty" toml:"unhealthyThreshold,omitempty" yaml:"unhealthyThreshold,omitempty"`
+	HealthyThreshold   int            `json:"healthyThreshold,omitempty" toml:"healthyThreshold,omitempty" yaml:"healthyThreshold,omitempty"`
+	Send               string         `json:"send,omitempty" toml:"send,omitempty" yaml:"send,omitempty"`
+	Expect             string         `json:"expect,omitempty" toml:"expect,omitempty" yaml:"expect,omitempty"`
+}
+
+// SetDefaults Default values for a UDPHealthCheck.
+func (h *UDPHealthCheck) SetDefaults() {
+	h.Interval = types.Duration(30 * time.Second)
+	h.Timeout = types.Duration(5 * time.Second)
+	h.UnhealthyThreshold = 1
+	h.HealthyThreshold = 1
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPAffinity defines the client IP affinity configuration of a UDP load-balancer.
+// The sessions of a client are sent to the server chosen from a hash of the client IP,
+// or, when TTL is set, to the server chosen for the first session of the client,
+// until the client has not opened any session for TTL.
+// In both cases, a client is moved to another server when its server is down or removed.
+type UDPAffinity struct {
+	TTL types.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // UDPServer defines a UDP server configuration.
 type UDPServer struct {
 	Address string `json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty" label:"-"`
-	Port    string `toml:"-" json:"-" yaml:"-"`
+	// Weight is the relative share of sessions sent to the server, defaulting to 1.
+	// A server with a zero weight does not receive any session.
+	Weight *int `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty"`
+	// DNS makes the server stand for all the addresses its host name resolves to,
+	// kept up to date with the DNS records.
+	DNS  *ServerDNS `json:"dns,omitempty" toml:"dns,omitempty" yaml:"dns,omitempty" label:"-"`
+	Port string     `toml:"-" json:"-" yaml:"-"`
 }
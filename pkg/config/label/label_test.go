@@ -149,6 +149,7 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval": "foobar",
 		"traefik.http.services.Service0.loadbalancer.server.scheme":                    "foobar",
 		"traefik.http.services.Service0.loadbalancer.server.port":                      "8080",
+		"traefik.http.services.Service0.loadbalancer.server.weight":                    "42",
 		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":               "foobar",
 		"traefik.http.services.Service0.loadbalancer.sticky.cookie.secure":             "true",
 		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":        "foobar",
@@ -177,16 +178,18 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.tcp.routers.Router1.tls.options":                                      "foo",
 		"traefik.tcp.routers.Router1.tls.passthrough":                                  "false",
 		"traefik.tcp.services.Service0.loadbalancer.server.Port":                       "42",
+		"traefik.tcp.services.Service0.loadbalancer.server.Weight":                     "42",
 		"traefik.tcp.services.Service0.loadbalancer.TerminationDelay":                  "42",
 		"traefik.tcp.services.Service1.loadbalancer.server.Port":                       "42",
 		"traefik.tcp.services.Service1.loadbalancer.TerminationDelay":                  "42",
 
-		"traefik.udp.routers.Router0.entrypoints":                "foobar, fiibar",
-		"traefik.udp.routers.Router0.service":                    "foobar",
-		"traefik.udp.routers.Router1.entrypoints":                "foobar, fiibar",
-		"traefik.udp.routers.Router1.service":                    "foobar",
-		"traefik.udp.services.Service0.loadbalancer.server.Port": "42",
-		"traefik.udp.services.Service1.loadbalancer.server.Port": "42",
+		"traefik.udp.routers.Router0.entrypoints":                  "foobar, fiibar",
+		"traefik.udp.routers.Router0.service":                      "foobar",
+		"traefik.udp.routers.Router1.entrypoints":                  "foobar, fiibar",
+		"traefik.udp.routers.Router1.service":                      "foobar",
+		"traefik.udp.services.Service0.loadbalancer.server.Port":   "42",
+		"traefik.udp.services.Service0.loadbalancer.server.Weight": "42",
+		"traefik.udp.services.Service1.loadbalancer.server.Port":   "42",
 	}
 
 	configuration, err := DecodeConfiguration(labels)
@@ -225,7 +228,8 @@ func TestDecodeConfiguration(t *testing.T) {
 					LoadBalancer: &dynamic.TCPServersLoadBalancer{
 						Servers: []dynamic.TCPServer{
 							{
-								Port: "42",
+								Port:   "42",
+								Weight: func(i int) *int { return &i }(42),
 							},
 						},
 						TerminationDelay: func(i int) *int { return &i }(42),
@@ -265,7 +269,8 @@ func TestDecodeConfiguration(t *testing.T) {
 					LoadBalancer: &dynamic.UDPServersLoadBalancer{
 						Servers: []dynamic.UDPServer{
 							{
-								Port: "42",
+								Port:   "42",
+								Weight: func(i int) *int { return &i }(42),
 							},
 						},
 					},
@@ -589,6 +594,7 @@ func TestDecodeConfiguration(t *testing.T) {
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: func(i int) *int { return &i }(42),
 							},
 						},
 						HealthCheck: &dynamic.HealthCheck{
@@ -678,7 +684,8 @@ func TestEncodeConfiguration(t *testing.T) {
 					LoadBalancer: &dynamic.TCPServersLoadBalancer{
 						Servers: []dynamic.TCPServer{
 							{
-								Port: "42",
+								Port:   "42",
+								Weight: func(i int) *int { return &i }(42),
 							},
 						},
 						TerminationDelay: func(i int) *int { return &i }(42),
@@ -718,7 +725,8 @@ func TestEncodeConfiguration(t *testing.T) {
 					LoadBalancer: &dynamic.UDPServersLoadBalancer{
 						Servers: []dynamic.UDPServer{
 							{
-								Port: "42",
+								Port:   "42",
+								Weight: func(i int) *int { return &i }(42),
 							},
 						},
 					},
@@ -1040,6 +1048,7 @@ func TestEncodeConfiguration(t *testing.T) {
 							{
 								Scheme: "foobar",
 								Port:   "8080",
+								Weight: func(i int) *int { return &i }(42),
 							},
 						},
 						HealthCheck: &dynamic.HealthCheck{
@@ -1117,6 +1126,8 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware6.Errors.Status":                                       "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.Address":                                 "foobar",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.AuthResponseHeaders":                     "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.ForwardBody":                             "false",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.MaxBodySize":                             "0",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.CA":                                  "foobar",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.CAOptional":                          "true",
 		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Cert":                                "foobar",
@@ -1201,6 +1212,8 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Regex":                             "foobar",
 		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Replacement":                       "foobar",
 		"traefik.HTTP.Middlewares.Middleware16.Retry.Attempts":                                     "42",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.InitialInterval":                              "0",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.PerTryTimeout":                                "0",
 		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.Prefixes":                               "foobar, fiibar",
 		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.ForceSlash":                             "true",
 		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                             "foobar, fiibar",
@@ -1227,8 +1240,10 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Timeout":              "foobar",
 		"traefik.HTTP.Services.Service0.LoadBalancer.PassHostHeader":                   "true",
 		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.SlowStart":                        "0",
 		"traefik.HTTP.Services.Service0.LoadBalancer.server.Port":                      "8080",
 		"traefik.HTTP.Services.Service0.LoadBalancer.server.Scheme":                    "foobar",
+		"traefik.HTTP.Services.Service0.LoadBalancer.server.Weight":                    "42",
 		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Name":               "foobar",
 		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.HTTPOnly":           "true",
 		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Secure":             "false",
@@ -1242,6 +1257,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Timeout":              "foobar",
 		"traefik.HTTP.Services.Service1.LoadBalancer.PassHostHeader":                   "true",
 		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval": "foobar",
+		"traefik.HTTP.Services.Service1.LoadBalancer.SlowStart":                        "0",
 		"traefik.HTTP.Services.Service1.LoadBalancer.server.Port":                      "8080",
 		"traefik.HTTP.Services.Service1.LoadBalancer.server.Scheme":                    "foobar",
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Headers.name0":        "foobar",
@@ -1257,16 +1273,18 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.TCP.Routers.Router1.TLS.Passthrough":                 "false",
 		"traefik.TCP.Routers.Router1.TLS.Options":                     "foo",
 		"traefik.TCP.Services.Service0.LoadBalancer.server.Port":      "42",
+		"traefik.TCP.Services.Service0.LoadBalancer.server.Weight":    "42",
 		"traefik.TCP.Services.Service0.LoadBalancer.TerminationDelay": "42",
 		"traefik.TCP.Services.Service1.LoadBalancer.server.Port":      "42",
 		"traefik.TCP.Services.Service1.LoadBalancer.TerminationDelay": "42",
 
-		"traefik.UDP.Routers.Router0.EntryPoints":                "foobar, fiibar",
-		"traefik.UDP.Routers.Router0.Service":                    "foobar",
-		"traefik.UDP.Routers.Router1.EntryPoints":                "foobar, fiibar",
-		"traefik.UDP.Routers.Router1.Service":                    "foobar",
-		"traefik.UDP.Services.Service0.LoadBalancer.server.Port": "42",
-		"traefik.UDP.Services.Service1.LoadBalancer.server.Port": "42",
+		"traefik.UDP.Routers.Router0.EntryPoints":                  "foobar, fiibar",
+		"traefik.UDP.Routers.Router0.Service":                      "foobar",
+		"traefik.UDP.Routers.Router1.EntryPoints":                  "foobar, fiibar",
+		"traefik.UDP.Routers.Router1.Service":                      "foobar",
+		"traefik.UDP.Services.Service0.LoadBalancer.server.Port":   "42",
+		"traefik.UDP.Services.Service0.LoadBalancer.server.Weight": "42",
+		"traefik.UDP.Services.Service1.LoadBalancer.server.Port":   "42",
 	}
 
 	for key, val := range expected {
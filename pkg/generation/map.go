@@ -0,0 +1,65 @@
+// Package generation keeps the state of the dynamic configuration elements across configuration reloads.
+package generation
+
+import "sync"
+
+// Map holds values by key across configuration reloads.
+// A value is kept as long as its key is used by each new configuration.
+type Map struct {
+	mu         sync.Mutex
+	generation int
+	entries    map[string]*entry
+}
+
+type entry struct {
+	value      interface{}
+	generation int
+}
+
+// NewMap creates a new Map.
+func NewMap() *Map {
+	return &Map{entries: make(map[string]*entry)}
+}
+
+// NewGeneration must be called before building the elements of a new configuration.
+// It forgets the values whose keys were not used by the previous configuration.
+func (m *Map) NewGeneration() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, e := range m.entries {
+		if e.generation < m.generation {
+			delete(m.entries, key)
+		}
+	}
+	m.generation++
+}
+
+// Get returns the value of the given key, and marks the key as used by the current configuration.
+// The value is created with create if the key is unknown, or if reuse, when not nil, rejects the current value.
+func (m *Map) Get(key string, reuse func(value interface{}) bool, create func() (interface{}, error)) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok || (reuse != nil && !reuse(e.value)) {
+		value, err := create()
+		if err != nil {
+			return nil, err
+		}
+
+		e = &entry{value: value}
+		m.entries[key] = e
+	}
+
+	e.generation = m.generation
+	return e.value, nil
+}
+
+// Delete forgets the value of the given key.
+func (m *Map) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+}
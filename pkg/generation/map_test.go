@@ -0,0 +1,59 @@
+package generation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap(t *testing.T) {
+	m := NewMap()
+
+	created := 0
+	get := func(key string, version int) interface{} {
+		value, err := m.Get(key, func(value interface{}) bool {
+			return value.([2]int)[0] == version
+		}, func() (interface{}, error) {
+			created++
+			return [2]int{version, created}, nil
+		})
+		require.NoError(t, err)
+		return value
+	}
+
+	m.NewGeneration()
+	foo := get("foo", 1)
+	bar := get("bar", 1)
+	assert.Equal(t, 2, created)
+
+	// The values are kept for the next configuration.
+	m.NewGeneration()
+	assert.Equal(t, foo, get("foo", 1))
+
+	// A value rejected by reuse is replaced.
+	assert.NotEqual(t, foo, get("foo", 2))
+
+	// The values of the keys unused by the previous configuration are forgotten.
+	m.NewGeneration()
+	assert.NotEqual(t, bar, get("bar", 1))
+
+	m.Delete("foo")
+	assert.NotEqual(t, foo, get("foo", 2))
+}
+
+func TestMap_createError(t *testing.T) {
+	m := NewMap()
+
+	_, err := m.Get("foo", nil, func() (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+
+	value, err := m.Get("foo", nil, func() (interface{}, error) {
+		return "foo", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", value)
+}
@@ -0,0 +1,126 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/golang/protobuf/proto"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+
+// maxGRPCResponseSize is the maximum size of a health check response,
+// which only holds a status, read from the server.
+const maxGRPCResponseSize = 4 * 1024
+
+// checkHealthGRPC calls the Check method of the gRPC health checking protocol on the server,
+// through the transport of the backend, so that the server is reached over h2c or TLS depending on the scheme.
+// It returns a nil error only if the server reports the checked service as SERVING.
+func checkHealthGRPC(serverURL *url.URL, backend *BackendConfig) error {
+	u, err := backend.targetURL(serverURL, grpcHealthCheckPath)
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC request: %w", err)
+	}
+
+	msg, err := proto.Marshal(&healthpb.HealthCheckRequest{Service: backend.ServiceName})
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC request: %w", err)
+	}
+
+	req, err := newServerRequest(http.MethodPost, u, bytes.NewReader(encodeGRPCMessage(msg)))
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Te", "trailers")
+	req = backend.addHeadersAndHost(req)
+
+	client := http.Client{
+		Timeout:   backend.Options.Timeout,
+		Transport: backend.Options.Transport,
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gRPC request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received error status code: %v", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxGRPCResponseSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read gRPC response: %w", err)
+	}
+	if len(body) > maxGRPCResponseSize {
+		return fmt.Errorf("gRPC response larger than %d bytes", maxGRPCResponseSize)
+	}
+
+	// The status is sent in the trailers, or in the headers for responses without a body.
+	status := resp.Trailer.Get("Grpc-Status")
+	message := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		message = resp.Header.Get("Grpc-Message")
+	}
+	if status == "" {
+		return errors.New("missing gRPC status in the response")
+	}
+	if status != "0" {
+		return fmt.Errorf("received gRPC error status: %s %s", status, message)
+	}
+
+	msg, err = decodeGRPCMessage(body)
+	if err != nil {
+		return fmt.Errorf("failed to read gRPC response: %w", err)
+	}
+
+	var healthResp healthpb.HealthCheckResponse
+	if err := proto.Unmarshal(msg, &healthResp); err != nil {
+		return fmt.Errorf("failed to read gRPC response: %w", err)
+	}
+
+	if healthResp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("received gRPC health status: %s", healthResp.Status)
+	}
+
+	return nil
+}
+
+// encodeGRPCMessage prefixes the given message with the gRPC framing:
+// the compression flag (uncompressed), and the message length.
+func encodeGRPCMessage(msg []byte) []byte {
+	frame := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(msg)))
+	copy(frame[5:], msg)
+	return frame
+}
+
+// decodeGRPCMessage returns the first message of the given gRPC framed data.
+func decodeGRPCMessage(data []byte) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, errors.New("message too short")
+	}
+
+	if data[0] != 0 {
+		return nil, errors.New("compressed messages are not supported")
+	}
+
+	length := binary.BigEndian.Uint32(data[1:5])
+	if uint32(len(data)-5) < length {
+		return nil, errors.New("truncated message")
+	}
+
+	return data[5 : 5+length], nil
+}
@@ -0,0 +1,111 @@
+package healthcheck
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestCheckHealthGRPC(t *testing.T) {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("serving", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("not-serving", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	server := httptest.NewServer(h2c.NewHandler(grpcServer, &http2.Server{}))
+	defer server.Close()
+
+	transport := &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}
+
+	testCases := []struct {
+		desc          string
+		serviceName   string
+		expectedError string
+	}{
+		{
+			desc: "overall server health",
+		},
+		{
+			desc:        "serving service",
+			serviceName: "serving",
+		},
+		{
+			desc:          "not serving service",
+			serviceName:   "not-serving",
+			expectedError: "received gRPC health status: NOT_SERVING",
+		},
+		{
+			desc:          "unknown service",
+			serviceName:   "unknown",
+			expectedError: "received gRPC error status: 5 unknown service",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			backend := NewBackendConfig(Options{
+				Mode:        ModeGRPC,
+				ServiceName: test.serviceName,
+				Timeout:     time.Second,
+				Transport:   transport,
+			}, "backend")
+
+			err := checkHealth(testhelpers.MustParseURL(server.URL), backend)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckHealthGRPC_notGRPCServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	backend := NewBackendConfig(Options{
+		Mode:    ModeGRPC,
+		Timeout: time.Second,
+	}, "backend")
+
+	err := checkHealth(testhelpers.MustParseURL(server.URL), backend)
+	assert.EqualError(t, err, "missing gRPC status in the response")
+}
+
+func TestCheckHealthGRPC_responseTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Grpc-Status", "0")
+		_, _ = rw.Write(make([]byte, 2*maxGRPCResponseSize))
+	}))
+	defer server.Close()
+
+	backend := NewBackendConfig(Options{
+		Mode:    ModeGRPC,
+		Timeout: time.Second,
+	}, "backend")
+
+	err := checkHealth(testhelpers.MustParseURL(server.URL), backend)
+	assert.EqualError(t, err, "gRPC response larger than 4096 bytes")
+}
@@ -3,6 +3,7 @@ package healthcheck
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
@@ -22,6 +23,16 @@ const (
 	serverDown = "DOWN"
 )
 
+const (
+	// ModeHTTP is the health check mode sending HTTP GET requests to the servers.
+	ModeHTTP = "http"
+	// ModeGRPC is the health check mode using the gRPC health checking protocol.
+	ModeGRPC = "grpc"
+)
+
+// unixSocketScheme is the scheme of the URLs of the servers listening on a unix socket, such as unix:///run/app.sock.
+const unixSocketScheme = "unix"
+
 var singleton *HealthCheck
 var once sync.Once
 
@@ -32,12 +43,30 @@ type Balancer interface {
 	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
 }
 
+// weightedBalancer is implemented by the balancers able to report the weight of their servers,
+// so that a server returning to the pool after a failed health check gets its weight back.
+type weightedBalancer interface {
+	ServerWeight(u *url.URL) (int, bool)
+}
+
+// ServerForgetter is implemented by the health checks taking servers out of a load-balancer and putting them back later,
+// so that a server removed from the configuration (e.g. no longer resolved from a host name) is not put back.
+type ServerForgetter interface {
+	ForgetServer(u *url.URL)
+}
+
 // BalancerHandler includes functionality for load-balancing management.
 type BalancerHandler interface {
 	ServeHTTP(w http.ResponseWriter, req *http.Request)
 	Balancer
 }
 
+// StatusUpdater is implemented by the handlers able to notify their parent(s)
+// when their status changes, i.e. when all of their servers are down, or when one of them is back up.
+type StatusUpdater interface {
+	RegisterStatusUpdater(fn func(up bool))
+}
+
 // metricsRegistry is a local interface in the health check package,
 // exposing only the required metrics necessary for the health check package.
 // This makes it easier for the tests.
@@ -49,8 +78,10 @@ type metricsRegistry interface {
 type Options struct {
 	Headers         map[string]string
 	Hostname        string
+	Mode            string
 	Scheme          string
 	Path            string
+	ServiceName     string
 	Port            int
 	FollowRedirects bool
 	Transport       http.RoundTripper
@@ -60,6 +91,9 @@ type Options struct {
 }
 
 func (opt Options) String() string {
+	if opt.Mode == ModeGRPC {
+		return fmt.Sprintf("[Mode: %s Hostname: %s Headers: %v ServiceName: %s Port: %d Interval: %s Timeout: %s]", opt.Mode, opt.Hostname, opt.Headers, opt.ServiceName, opt.Port, opt.Interval, opt.Timeout)
+	}
 	return fmt.Sprintf("[Hostname: %s Headers: %v Path: %s Port: %d Interval: %s Timeout: %s FollowRedirects: %v]", opt.Hostname, opt.Headers, opt.Path, opt.Port, opt.Interval, opt.Timeout, opt.FollowRedirects)
 }
 
@@ -71,12 +105,105 @@ type backendURL struct {
 // BackendConfig HealthCheck configuration for a backend.
 type BackendConfig struct {
 	Options
-	name         string
+	name string
+
+	mu           sync.Mutex
 	disabledURLs []backendURL
 }
 
+// ForgetServer makes the health check forget about the given server, if it took it out of the load-balancer.
+func (b *BackendConfig) ForgetServer(u *url.URL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.disabledURLs = withoutURL(b.disabledURLs, u)
+}
+
+// enable puts the given server back in the load-balancer, unless it has been forgotten in the meantime.
+func (b *BackendConfig) enable(ctx context.Context, disabledURL backendURL) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	disabledURLs := withoutURL(b.disabledURLs, disabledURL.url)
+	if len(disabledURLs) == len(b.disabledURLs) {
+		return
+	}
+	b.disabledURLs = disabledURLs
+
+	logger := log.FromContext(ctx)
+	logger.Warnf("Health check up: Returning to server list. Backend: %q URL: %q Weight: %d",
+		b.name, disabledURL.url.String(), disabledURL.weight)
+	if err := b.LB.UpsertServer(disabledURL.url, roundrobin.Weight(disabledURL.weight)); err != nil {
+		logger.Error(err)
+	}
+}
+
+// disable takes the given server out of the load-balancer.
+func (b *BackendConfig) disable(ctx context.Context, u *url.URL, reason error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	weight := 1
+	if wb, ok := b.LB.(weightedBalancer); ok {
+		if w, found := wb.ServerWeight(u); found {
+			weight = w
+		}
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Warnf("Health check failed, removing from server list. Backend: %q URL: %q Weight: %d Reason: %s", b.name, u.String(), weight, reason)
+	if err := b.LB.RemoveServer(u); err != nil {
+		// The server may have been removed from the configuration in the meantime.
+		logger.Error(err)
+		return
+	}
+	b.disabledURLs = append(b.disabledURLs, backendURL{u, weight})
+}
+
+// withoutURL returns a new list of the given servers, without the given one.
+func withoutURL(backendURLs []backendURL, u *url.URL) []backendURL {
+	var newBackendURLs []backendURL
+	for _, backendURL := range backendURLs {
+		if backendURL.url.String() != u.String() {
+			newBackendURLs = append(newBackendURLs, backendURL)
+		}
+	}
+	return newBackendURLs
+}
+
 func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
-	u, err := serverURL.Parse(b.Path)
+	u, err := b.targetURL(serverURL, b.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newServerRequest(http.MethodGet, u, http.NoBody)
+}
+
+// newServerRequest returns a request to the given URL on a server.
+func newServerRequest(method string, u *url.URL, body io.Reader) (*http.Request, error) {
+	if u.Scheme != unixSocketScheme {
+		return http.NewRequest(method, u.String(), body)
+	}
+
+	// The host of the URL, which is the path of the socket, cannot be formatted.
+	req, err := http.NewRequest(method, (&url.URL{Path: u.Path, RawQuery: u.RawQuery}).String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.URL = u
+	return req, nil
+}
+
+// targetURL returns the URL of the given path on the server, with the scheme and port overrides applied.
+func (b *BackendConfig) targetURL(serverURL *url.URL, path string) (*url.URL, error) {
+	if serverURL.Scheme == unixSocketScheme {
+		// The path of the server URL is the one of the socket, which is kept as the host for the round tripper of the service.
+		return (&url.URL{Scheme: serverURL.Scheme, Host: serverURL.Path}).Parse(path)
+	}
+
+	u, err := serverURL.Parse(path)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +216,7 @@ func (b *BackendConfig) newRequest(serverURL *url.URL) (*http.Request, error) {
 		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(b.Port))
 	}
 
-	return http.NewRequest(http.MethodGet, u.String(), http.NoBody)
+	return u, nil
 }
 
 // this function adds additional http headers and hostname to http.request.
@@ -151,37 +278,23 @@ func (hc *HealthCheck) checkBackend(ctx context.Context, backend *BackendConfig)
 	logger := log.FromContext(ctx)
 
 	enabledURLs := backend.LB.Servers()
-	var newDisabledURLs []backendURL
-	for _, disabledURL := range backend.disabledURLs {
-		if err := checkHealth(disabledURL.url, backend); err == nil {
-			logger.Warnf("Health check up: Returning to server list. Backend: %q URL: %q Weight: %d",
-				backend.name, disabledURL.url.String(), disabledURL.weight)
-			if err = backend.LB.UpsertServer(disabledURL.url, roundrobin.Weight(disabledURL.weight)); err != nil {
-				logger.Error(err)
-			}
-		} else {
+
+	// The servers are checked without holding the lock, as the checks can take up to the timeout.
+	backend.mu.Lock()
+	disabledURLs := backend.disabledURLs
+	backend.mu.Unlock()
+
+	for _, disabledURL := range disabledURLs {
+		if err := checkHealth(disabledURL.url, backend); err != nil {
 			logger.Warnf("Health check still failing. Backend: %q URL: %q Reason: %s", backend.name, disabledURL.url.String(), err)
-			newDisabledURLs = append(newDisabledURLs, disabledURL)
+			continue
 		}
+		backend.enable(ctx, disabledURL)
 	}
-	backend.disabledURLs = newDisabledURLs
 
 	for _, enableURL := range enabledURLs {
 		if err := checkHealth(enableURL, backend); err != nil {
-			weight := 1
-			rr, ok := backend.LB.(*roundrobin.RoundRobin)
-			if ok {
-				var gotWeight bool
-				weight, gotWeight = rr.ServerWeight(enableURL)
-				if !gotWeight {
-					weight = 1
-				}
-			}
-			logger.Warnf("Health check failed, removing from server list. Backend: %q URL: %q Weight: %d Reason: %s", backend.name, enableURL.String(), weight, err)
-			if err := backend.LB.RemoveServer(enableURL); err != nil {
-				logger.Error(err)
-			}
-			backend.disabledURLs = append(backend.disabledURLs, backendURL{enableURL, weight})
+			backend.disable(ctx, enableURL, err)
 		}
 	}
 }
@@ -211,6 +324,10 @@ func NewBackendConfig(options Options, backendName string) *BackendConfig {
 // checkHealth returns a nil error in case it was successful and otherwise
 // a non-nil error with a meaningful description why the health check failed.
 func checkHealth(serverURL *url.URL, backend *BackendConfig) error {
+	if backend.Mode == ModeGRPC {
+		return checkHealthGRPC(serverURL, backend)
+	}
+
 	req, err := backend.newRequest(serverURL)
 	if err != nil {
 		return fmt.Errorf("failed to create HTTP request: %w", err)
@@ -248,36 +365,99 @@ func NewLBStatusUpdater(bh BalancerHandler, info *runtime.ServiceInfo) *LbStatus
 	return &LbStatusUpdater{
 		BalancerHandler: bh,
 		serviceInfo:     info,
+		down:            len(bh.Servers()) == 0,
 	}
 }
 
 // LbStatusUpdater wraps a BalancerHandler and a ServiceInfo,
 // so it can keep track of the status of a server in the ServiceInfo.
+// It also notifies the registered status updaters when the BalancerHandler goes down (no server left),
+// or back up.
 type LbStatusUpdater struct {
 	BalancerHandler
 	serviceInfo *runtime.ServiceInfo // can be nil
+
+	// mu serializes the changes of the servers with the computation and the notification of the status,
+	// so that no status change is lost, reported twice or out of order when the servers are changed concurrently.
+	mu sync.Mutex
+	// down is the status of the BalancerHandler, which is down when it does not have any server.
+	down     bool
+	updaters []func(up bool)
+}
+
+// RegisterStatusUpdater adds fn to the list of functions called when the status of the BalancerHandler changes.
+// As the BalancerHandler is assumed to be up, fn is called right away if it does not have any server.
+func (lb *LbStatusUpdater) RegisterStatusUpdater(fn func(up bool)) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	lb.updaters = append(lb.updaters, fn)
+
+	if lb.down {
+		fn(false)
+	}
 }
 
 // RemoveServer removes the given server from the BalancerHandler,
 // and updates the status of the server to "DOWN".
 func (lb *LbStatusUpdater) RemoveServer(u *url.URL) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
 	err := lb.BalancerHandler.RemoveServer(u)
 	if err == nil && lb.serviceInfo != nil {
 		lb.serviceInfo.UpdateServerStatus(u.String(), serverDown)
 	}
+	if err == nil && lb.updateStatus() {
+		lb.notify(!lb.down)
+	}
 	return err
 }
 
 // UpsertServer adds the given server to the BalancerHandler,
 // and updates the status of the server to "UP".
 func (lb *LbStatusUpdater) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
 	err := lb.BalancerHandler.UpsertServer(u, options...)
 	if err == nil && lb.serviceInfo != nil {
 		lb.serviceInfo.UpdateServerStatus(u.String(), serverUp)
 	}
+	if err == nil && lb.updateStatus() {
+		lb.notify(!lb.down)
+	}
 	return err
 }
 
+// updateStatus updates the status of the BalancerHandler from its servers, and reports whether it changed.
+// It must be called with the lock held.
+func (lb *LbStatusUpdater) updateStatus() bool {
+	down := len(lb.BalancerHandler.Servers()) == 0
+	if down == lb.down {
+		return false
+	}
+
+	lb.down = down
+	return true
+}
+
+// notify must be called with the lock held.
+func (lb *LbStatusUpdater) notify(up bool) {
+	for _, fn := range lb.updaters {
+		fn(up)
+	}
+}
+
+// ServerWeight returns the weight of the given server in the BalancerHandler,
+// if the BalancerHandler keeps track of weights.
+func (lb *LbStatusUpdater) ServerWeight(u *url.URL) (int, bool) {
+	if wb, ok := lb.BalancerHandler.(weightedBalancer); ok {
+		return wb.ServerWeight(u)
+	}
+	return -1, false
+}
+
 // Balancers is a list of Balancers(s) that implements the Balancer interface.
 type Balancers []Balancer
 
@@ -291,6 +471,18 @@ func (b Balancers) Servers() []*url.URL {
 	return servers
 }
 
+// ServerWeight returns the weight of the given server in the first BalancerHandler that knows about it.
+func (b Balancers) ServerWeight(u *url.URL) (int, bool) {
+	for _, lb := range b {
+		if wb, ok := lb.(weightedBalancer); ok {
+			if weight, found := wb.ServerWeight(u); found {
+				return weight, true
+			}
+		}
+	}
+	return -1, false
+}
+
 // RemoveServer removes the given server from all the BalancerHandler,
 // and updates the status of the server to "DOWN".
 func (b Balancers) RemoveServer(u *url.URL) error {
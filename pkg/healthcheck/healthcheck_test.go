@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/wrr"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -287,6 +288,18 @@ func TestNewRequest(t *testing.T) {
 	}
 }
 
+func TestNewRequest_unixSocket(t *testing.T) {
+	backend := NewBackendConfig(Options{Path: "/health?foo=bar", Scheme: "https", Port: 8443}, "backendName")
+
+	req, err := backend.newRequest(testhelpers.MustParseURL("unix:///run/app.sock"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "unix", req.URL.Scheme)
+	assert.Equal(t, "/run/app.sock", req.URL.Host)
+	assert.Equal(t, "/health", req.URL.Path)
+	assert.Equal(t, "foo=bar", req.URL.RawQuery)
+}
+
 func TestAddHeadersAndHost(t *testing.T) {
 	testCases := []struct {
 		desc             string
@@ -470,6 +483,126 @@ func TestLBStatusUpdater(t *testing.T) {
 	}
 }
 
+func TestLBStatusUpdater_RegisterStatusUpdater(t *testing.T) {
+	lb := &testLoadBalancer{RWMutex: &sync.RWMutex{}}
+	lbsu := NewLBStatusUpdater(lb, nil)
+
+	var statuses []bool
+	lbsu.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	})
+
+	server1 := testhelpers.MustParseURL("http://foo.com")
+	server2 := testhelpers.MustParseURL("http://bar.com")
+
+	// No server yet.
+	assert.Equal(t, []bool{false}, statuses)
+
+	require.NoError(t, lbsu.UpsertServer(server1))
+	require.NoError(t, lbsu.UpsertServer(server2))
+	assert.Equal(t, []bool{false, true}, statuses)
+
+	require.NoError(t, lbsu.RemoveServer(server1))
+	assert.Equal(t, []bool{false, true}, statuses)
+
+	require.NoError(t, lbsu.RemoveServer(server2))
+	assert.Equal(t, []bool{false, true, false}, statuses)
+
+	require.NoError(t, lbsu.UpsertServer(server1))
+	assert.Equal(t, []bool{false, true, false, true}, statuses)
+}
+
+func TestLBStatusUpdater_concurrentChanges(t *testing.T) {
+	lb, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+	lbsu := NewLBStatusUpdater(lb, nil)
+
+	parent := wrr.New(nil)
+	parent.AddService("child", lbsu, nil)
+	lbsu.RegisterStatusUpdater(func(up bool) {
+		// Lets the other changes run before the propagation.
+		time.Sleep(time.Microsecond)
+		parent.SetStatus(context.Background(), "child", up)
+	})
+
+	var mu sync.Mutex
+	var parentUp bool
+	parent.RegisterStatusUpdater(func(up bool) {
+		mu.Lock()
+		parentUp = up
+		mu.Unlock()
+	})
+
+	server := testhelpers.MustParseURL("http://foo.com")
+
+	for _, last := range []bool{true, false} {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				for j := 0; j < 100; j++ {
+					// Removing a server which is not there fails, and changes nothing.
+					if (i+j)%2 == 0 {
+						_ = lbsu.UpsertServer(server)
+					} else {
+						_ = lbsu.RemoveServer(server)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		if last {
+			require.NoError(t, lbsu.UpsertServer(server))
+		} else {
+			_ = lbsu.RemoveServer(server)
+			require.Empty(t, lbsu.Servers())
+		}
+
+		// The parent ends up with the status of the child, whatever the order of the changes.
+		mu.Lock()
+		assert.Equal(t, last, parentUp)
+		mu.Unlock()
+	}
+}
+
+func TestCheckBackendRestoresWeight(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	rr, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+
+	serverURL := testhelpers.MustParseURL(server.URL)
+	lb := NewLBStatusUpdater(rr, &runtime.ServiceInfo{})
+	require.NoError(t, lb.UpsertServer(serverURL, roundrobin.Weight(3)))
+
+	backend := NewBackendConfig(Options{
+		Path:    "/path",
+		Timeout: healthCheckTimeout,
+		LB:      Balancers{lb},
+	}, "backendName")
+
+	check := HealthCheck{Backends: make(map[string]*BackendConfig)}
+
+	check.checkBackend(context.Background(), backend)
+	assert.Empty(t, rr.Servers())
+	require.Len(t, backend.disabledURLs, 1)
+	assert.Equal(t, 3, backend.disabledURLs[0].weight)
+
+	status = http.StatusOK
+	check.checkBackend(context.Background(), backend)
+
+	weight, ok := rr.ServerWeight(serverURL)
+	assert.True(t, ok)
+	assert.Equal(t, 3, weight)
+}
+
 func TestNotFollowingRedirects(t *testing.T) {
 	redirectServerCalled := false
 	redirectTestServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
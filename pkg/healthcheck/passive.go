@@ -0,0 +1,229 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/go-kit/kit/metrics"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// PassiveOptions are the passive health check options.
+type PassiveOptions struct {
+	// ConsecutiveFailures is the number of failed requests in a row after which a server is ejected (0 to disable).
+	ConsecutiveFailures int
+	// FailureRate is the percentage of failed requests over Window after which a server is ejected (0 to disable).
+	FailureRate int
+	// MinRequests is the number of requests a server must have received over Window before FailureRate applies.
+	MinRequests        int
+	Window             time.Duration
+	BaseEjectionTime   time.Duration
+	MaxEjectionTime    time.Duration
+	MaxEjectionPercent int
+}
+
+type serverStats struct {
+	consecutiveFailures int
+	windowStart         time.Time
+	requests            int
+	failures            int
+
+	ejected    bool
+	ejections  int
+	restoredAt time.Time
+}
+
+// PassiveHealthCheck is a http.Handler meant to be called by a load-balancer with the request targeting the chosen server.
+// It watches the responses returned by each server,
+// and temporarily removes the servers considered as failing from the load-balancer.
+type PassiveHealthCheck struct {
+	next    http.Handler
+	name    string
+	opts    PassiveOptions
+	gauge   metrics.Gauge
+	lb      Balancer
+	now     func() time.Time
+	afterFn func(time.Duration, func())
+
+	mu      sync.Mutex
+	servers map[string]*serverStats
+	ejected int
+}
+
+// NewPassiveHealthCheck creates a new PassiveHealthCheck.
+// The given gauge, which can be nil, reports whether each server is up.
+func NewPassiveHealthCheck(next http.Handler, serviceName string, opts PassiveOptions, gauge metrics.Gauge) *PassiveHealthCheck {
+	return &PassiveHealthCheck{
+		next:  next,
+		name:  serviceName,
+		opts:  opts,
+		gauge: gauge,
+		now:   time.Now,
+		afterFn: func(d time.Duration, f func()) {
+			time.AfterFunc(d, f)
+		},
+		servers: make(map[string]*serverStats),
+	}
+}
+
+// SetBalancer sets the load-balancer the failing servers are ejected from.
+func (p *PassiveHealthCheck) SetBalancer(lb Balancer) {
+	p.lb = lb
+}
+
+func (p *PassiveHealthCheck) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	serverURL := *req.URL
+
+	recorder := middlewares.NewStatusRecorder(rw)
+	p.next.ServeHTTP(recorder, req)
+
+	p.observe(req.Context(), &serverURL, recorder.Code() >= http.StatusInternalServerError)
+}
+
+func (p *PassiveHealthCheck) observe(ctx context.Context, u *url.URL, failed bool) {
+	if p.lb == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := u.String()
+	stats, ok := p.servers[key]
+	if !ok {
+		stats = &serverStats{windowStart: p.now()}
+		p.servers[key] = stats
+		p.setGauge(key, 1)
+	}
+
+	// In-flight requests can still end after the server has been ejected.
+	if stats.ejected {
+		return
+	}
+
+	now := p.now()
+	if p.opts.Window > 0 && now.Sub(stats.windowStart) >= p.opts.Window {
+		stats.windowStart = now
+		stats.requests = 0
+		stats.failures = 0
+	}
+
+	stats.requests++
+	if !failed {
+		stats.consecutiveFailures = 0
+		return
+	}
+
+	stats.failures++
+	stats.consecutiveFailures++
+
+	if p.isOutlier(stats) {
+		p.eject(ctx, u, stats)
+	}
+}
+
+func (p *PassiveHealthCheck) isOutlier(stats *serverStats) bool {
+	if p.opts.ConsecutiveFailures > 0 && stats.consecutiveFailures >= p.opts.ConsecutiveFailures {
+		return true
+	}
+
+	return p.opts.FailureRate > 0 && stats.requests >= p.opts.MinRequests &&
+		stats.failures*100 >= stats.requests*p.opts.FailureRate
+}
+
+// eject must be called with the lock held.
+func (p *PassiveHealthCheck) eject(ctx context.Context, u *url.URL, stats *serverStats) {
+	logger := log.FromContext(ctx)
+
+	total := len(p.lb.Servers()) + p.ejected
+	if (p.ejected+1)*100 > total*p.opts.MaxEjectionPercent {
+		logger.Debugf("Passive health check: not ejecting server %s, the maximum ejection percentage (%d%%) is reached", u, p.opts.MaxEjectionPercent)
+		return
+	}
+
+	weight := 1
+	if wb, ok := p.lb.(weightedBalancer); ok {
+		if w, found := wb.ServerWeight(u); found {
+			weight = w
+		}
+	}
+
+	if err := p.lb.RemoveServer(u); err != nil {
+		logger.Debugf("Passive health check: unable to eject server %s: %v", u, err)
+		return
+	}
+
+	if !stats.restoredAt.IsZero() && p.now().Sub(stats.restoredAt) > p.opts.MaxEjectionTime {
+		stats.ejections = 0
+	}
+	stats.ejections++
+	stats.ejected = true
+	p.ejected++
+
+	duration := p.opts.BaseEjectionTime * time.Duration(stats.ejections)
+	if p.opts.MaxEjectionTime > 0 && duration > p.opts.MaxEjectionTime {
+		duration = p.opts.MaxEjectionTime
+	}
+
+	logger.Warnf("Passive health check failed, ejecting server from the list for %s. Backend: %q URL: %q Weight: %d", duration, p.name, u.String(), weight)
+	p.setGauge(u.String(), 0)
+
+	p.afterFn(duration, func() {
+		p.restore(ctx, u, weight, stats)
+	})
+}
+
+// ForgetServer makes the passive health check forget about the given server,
+// so that it is not put back in the load-balancer if it is ejected.
+func (p *PassiveHealthCheck) ForgetServer(u *url.URL) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats, ok := p.servers[u.String()]
+	if !ok {
+		return
+	}
+
+	if stats.ejected {
+		p.ejected--
+	}
+	delete(p.servers, u.String())
+}
+
+func (p *PassiveHealthCheck) restore(ctx context.Context, u *url.URL, weight int, stats *serverStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The server has been forgotten since it was ejected.
+	if p.servers[u.String()] != stats || !stats.ejected {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Warnf("Passive health check ejection over: Returning to server list. Backend: %q URL: %q Weight: %d", p.name, u.String(), weight)
+
+	if err := p.lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
+		logger.Error(err)
+	}
+
+	stats.ejected = false
+	stats.restoredAt = p.now()
+	stats.windowStart = stats.restoredAt
+	stats.requests = 0
+	stats.failures = 0
+	stats.consecutiveFailures = 0
+	p.ejected--
+
+	p.setGauge(u.String(), 1)
+}
+
+func (p *PassiveHealthCheck) setGauge(serverURL string, value float64) {
+	if p.gauge != nil {
+		p.gauge.With("service", p.name, "url", serverURL).Set(value)
+	}
+}
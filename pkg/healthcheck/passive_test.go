@@ -0,0 +1,195 @@
+package healthcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+type testGauge struct {
+	values map[string]float64
+	url    string
+}
+
+func (g *testGauge) With(labelValues ...string) metrics.Gauge {
+	return &testGauge{values: g.values, url: labelValues[3]}
+}
+
+func (g *testGauge) Set(value float64) {
+	g.values[g.url] = value
+}
+
+func (g *testGauge) Add(delta float64) {
+	g.values[g.url] += delta
+}
+
+type timer struct {
+	delay time.Duration
+	fn    func()
+}
+
+func TestPassiveHealthCheck(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		opts            PassiveOptions
+		statuses        []int
+		expectedEjected bool
+	}{
+		{
+			desc:            "consecutive failures",
+			opts:            PassiveOptions{ConsecutiveFailures: 3, MaxEjectionPercent: 50, BaseEjectionTime: time.Second},
+			statuses:        []int{500, 502, 503},
+			expectedEjected: true,
+		},
+		{
+			desc:     "not enough consecutive failures",
+			opts:     PassiveOptions{ConsecutiveFailures: 3, MaxEjectionPercent: 50, BaseEjectionTime: time.Second},
+			statuses: []int{500, 502, 200, 503, 404},
+		},
+		{
+			desc:            "failure rate",
+			opts:            PassiveOptions{FailureRate: 50, MinRequests: 4, Window: time.Minute, MaxEjectionPercent: 50, BaseEjectionTime: time.Second},
+			statuses:        []int{200, 500, 200, 500},
+			expectedEjected: true,
+		},
+		{
+			desc:     "failure rate below min requests",
+			opts:     PassiveOptions{FailureRate: 50, MinRequests: 4, Window: time.Minute, MaxEjectionPercent: 50, BaseEjectionTime: time.Second},
+			statuses: []int{500, 200, 500},
+		},
+		{
+			desc:     "max ejection percent",
+			opts:     PassiveOptions{ConsecutiveFailures: 1, MaxEjectionPercent: 49, BaseEjectionTime: time.Second},
+			statuses: []int{500},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var statuses []int
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(statuses[0])
+				statuses = statuses[1:]
+			})
+
+			var timers []timer
+			gauge := &testGauge{values: make(map[string]float64)}
+			passive := NewPassiveHealthCheck(next, "foo", test.opts, gauge)
+			passive.afterFn = func(d time.Duration, f func()) {
+				timers = append(timers, timer{delay: d, fn: f})
+			}
+
+			rr, err := roundrobin.New(passive)
+			require.NoError(t, err)
+
+			serviceInfo := &runtime.ServiceInfo{}
+			lb := NewLBStatusUpdater(rr, serviceInfo)
+			passive.SetBalancer(lb)
+
+			failingURL := testhelpers.MustParseURL("http://failing:80")
+			healthyURL := testhelpers.MustParseURL("http://healthy:80")
+			require.NoError(t, lb.UpsertServer(failingURL, roundrobin.Weight(3)))
+			require.NoError(t, lb.UpsertServer(healthyURL))
+
+			for _, status := range test.statuses {
+				statuses = []int{status}
+				req := httptest.NewRequest(http.MethodGet, "http://foo/", nil)
+				req.URL = failingURL
+				passive.ServeHTTP(httptest.NewRecorder(), req)
+			}
+
+			if !test.expectedEjected {
+				assert.Len(t, rr.Servers(), 2)
+				assert.Empty(t, timers)
+				assert.Equal(t, float64(1), gauge.values[failingURL.String()])
+				return
+			}
+
+			assert.Equal(t, []string{healthyURL.String()}, urlStrings(rr.Servers()))
+			assert.Equal(t, serverDown, serviceInfo.GetAllStatus()[failingURL.String()])
+			assert.Equal(t, float64(0), gauge.values[failingURL.String()])
+
+			require.Len(t, timers, 1)
+			assert.Equal(t, time.Second, timers[0].delay)
+			timers[0].fn()
+
+			assert.Len(t, rr.Servers(), 2)
+			assert.Equal(t, serverUp, serviceInfo.GetAllStatus()[failingURL.String()])
+			assert.Equal(t, float64(1), gauge.values[failingURL.String()])
+
+			weight, ok := rr.ServerWeight(failingURL)
+			assert.True(t, ok)
+			assert.Equal(t, 3, weight)
+		})
+	}
+}
+
+func TestPassiveHealthCheck_growingEjectionTime(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	var timers []timer
+	passive := NewPassiveHealthCheck(next, "foo", PassiveOptions{
+		ConsecutiveFailures: 1,
+		MaxEjectionPercent:  100,
+		BaseEjectionTime:    10 * time.Second,
+		MaxEjectionTime:     25 * time.Second,
+	}, nil)
+	passive.afterFn = func(d time.Duration, f func()) {
+		timers = append(timers, timer{delay: d, fn: f})
+	}
+
+	now := time.Now()
+	passive.now = func() time.Time { return now }
+
+	rr, err := roundrobin.New(passive)
+	require.NoError(t, err)
+	passive.SetBalancer(rr)
+
+	serverURL := testhelpers.MustParseURL("http://failing:80")
+	require.NoError(t, rr.UpsertServer(serverURL))
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "http://foo/", nil)
+		req.URL = serverURL
+		passive.ServeHTTP(httptest.NewRecorder(), req)
+
+		require.Len(t, timers, i+1)
+		timers[i].fn()
+	}
+
+	assert.Equal(t, 10*time.Second, timers[0].delay)
+	assert.Equal(t, 20*time.Second, timers[1].delay)
+	assert.Equal(t, 25*time.Second, timers[2].delay)
+
+	// A server staying healthy longer than the maximum ejection time gets back to the base ejection time.
+	now = now.Add(time.Minute)
+
+	req := httptest.NewRequest(http.MethodGet, "http://foo/", nil)
+	req.URL = serverURL
+	passive.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, timers, 4)
+	assert.Equal(t, 10*time.Second, timers[3].delay)
+}
+
+func urlStrings(urls []*url.URL) []string {
+	var result []string
+	for _, u := range urls {
+		result = append(result, u.String())
+	}
+	return result
+}
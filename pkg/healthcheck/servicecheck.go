@@ -0,0 +1,313 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/tcp"
+)
+
+var (
+	tcpSingleton *ServiceHealthCheck
+	tcpOnce      sync.Once
+	udpSingleton *ServiceHealthCheck
+	udpOnce      sync.Once
+)
+
+// maxResponseSize is the maximum number of bytes read from a server response when looking for the expected payload.
+const maxResponseSize = 4096
+
+// StatusSetter is implemented by the TCP and UDP load-balancers,
+// whose servers are enabled or disabled by name.
+type StatusSetter interface {
+	SetStatus(ctx context.Context, name string, up bool)
+}
+
+// serverStatusUpdater is implemented by the TCP and UDP runtime service infos.
+type serverStatusUpdater interface {
+	UpdateServerStatus(server string, status string)
+}
+
+// ServiceOptions are the health check options of a TCP or UDP service.
+type ServiceOptions struct {
+	// Port overrides the port of the server addresses, when not zero.
+	Port     int
+	Interval time.Duration
+	Timeout  time.Duration
+	// UnhealthyThreshold is the number of failed checks in a row after which a server is considered down.
+	UnhealthyThreshold int
+	// HealthyThreshold is the number of successful checks in a row after which a server is considered back up.
+	HealthyThreshold int
+	// Send is the payload written to the server once connected (mandatory for UDP).
+	Send string
+	// Expect is the payload the server response must contain.
+	Expect string
+}
+
+func (opt ServiceOptions) String() string {
+	return fmt.Sprintf("[Port: %d Interval: %s Timeout: %s UnhealthyThreshold: %d HealthyThreshold: %d Send: %q Expect: %q]",
+		opt.Port, opt.Interval, opt.Timeout, opt.UnhealthyThreshold, opt.HealthyThreshold, opt.Send, opt.Expect)
+}
+
+type serverState struct {
+	address string
+	up      bool
+	// count is the number of checks in a row whose result differs from the current status.
+	count int
+}
+
+// ServiceChecker periodically checks the servers of a TCP or UDP service,
+// and sets their status in the load-balancers of the service.
+type ServiceChecker struct {
+	name      string
+	network   string
+	opts      ServiceOptions
+	info      serverStatusUpdater
+	balancers []StatusSetter
+
+	serversMu sync.Mutex
+	servers   []*serverState
+}
+
+// NewTCPServiceChecker creates a new ServiceChecker for a TCP service.
+// The given info, which can be nil, keeps track of the status of each server.
+func NewTCPServiceChecker(serviceName string, opts ServiceOptions, info serverStatusUpdater) *ServiceChecker {
+	return newServiceChecker(serviceName, "tcp", opts, info)
+}
+
+// NewUDPServiceChecker creates a new ServiceChecker for a UDP service.
+// The given info, which can be nil, keeps track of the status of each server.
+func NewUDPServiceChecker(serviceName string, opts ServiceOptions, info serverStatusUpdater) *ServiceChecker {
+	return newServiceChecker(serviceName, "udp", opts, info)
+}
+
+func newServiceChecker(serviceName, network string, opts ServiceOptions, info serverStatusUpdater) *ServiceChecker {
+	return &ServiceChecker{
+		name:    serviceName,
+		network: network,
+		opts:    opts,
+		info:    info,
+	}
+}
+
+// AddBalancer adds a load-balancer whose servers, named after their address, are enabled or disabled by the checks.
+func (c *ServiceChecker) AddBalancer(lb StatusSetter) {
+	c.balancers = append(c.balancers, lb)
+}
+
+// AddServer adds the given server address to the list of checked servers.
+func (c *ServiceChecker) AddServer(address string) {
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+
+	for _, server := range c.servers {
+		if server.address == address {
+			return
+		}
+	}
+
+	c.servers = append(c.servers, &serverState{address: address, up: true})
+	if c.info != nil {
+		c.info.UpdateServerStatus(address, serverUp)
+	}
+}
+
+// RemoveServer removes the given server address from the list of checked servers.
+func (c *ServiceChecker) RemoveServer(address string) {
+	c.serversMu.Lock()
+	defer c.serversMu.Unlock()
+
+	for i, server := range c.servers {
+		if server.address != address {
+			continue
+		}
+
+		c.servers = append(c.servers[:i:i], c.servers[i+1:]...)
+		if c.info != nil {
+			c.info.UpdateServerStatus(address, serverDown)
+		}
+		return
+	}
+}
+
+func (c *ServiceChecker) execute(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	logger.Debugf("Initial health check for service: %q", c.name)
+
+	c.checkServers(ctx)
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Debugf("Stopping current health check goroutines of service: %s", c.name)
+			return
+		case <-ticker.C:
+			logger.Debugf("Refreshing health check for service: %s", c.name)
+			c.checkServers(ctx)
+		}
+	}
+}
+
+func (c *ServiceChecker) checkServers(ctx context.Context) {
+	c.serversMu.Lock()
+	servers := c.servers
+	c.serversMu.Unlock()
+
+	for _, server := range servers {
+		c.checkServer(ctx, server)
+	}
+}
+
+func (c *ServiceChecker) checkServer(ctx context.Context, server *serverState) {
+	logger := log.FromContext(ctx)
+
+	err := c.check(ctx, server.address)
+	if (err == nil) == server.up {
+		server.count = 0
+		if err != nil {
+			logger.Debugf("Health check still failing. Service: %q Address: %q Reason: %s", c.name, server.address, err)
+		}
+		return
+	}
+
+	server.count++
+
+	threshold := c.opts.UnhealthyThreshold
+	if !server.up {
+		threshold = c.opts.HealthyThreshold
+	}
+	if server.count < threshold {
+		return
+	}
+
+	server.up = !server.up
+	server.count = 0
+
+	status := serverUp
+	if server.up {
+		logger.Warnf("Health check up: Returning to server list. Service: %q Address: %q", c.name, server.address)
+	} else {
+		status = serverDown
+		logger.Warnf("Health check failed, removing from server list. Service: %q Address: %q Reason: %s", c.name, server.address, err)
+	}
+
+	for _, lb := range c.balancers {
+		lb.SetStatus(ctx, server.address, server.up)
+	}
+	if c.info != nil {
+		c.info.UpdateServerStatus(server.address, status)
+	}
+}
+
+// check returns a nil error in case it was successful and otherwise
+// a non-nil error with a meaningful description why the health check failed.
+func (c *ServiceChecker) check(ctx context.Context, address string) error {
+	network := c.network
+	if network == "tcp" {
+		network, address = tcp.SplitNetworkAddress(address)
+	}
+
+	// A unix socket has no port to override.
+	if c.opts.Port != 0 && network != "unix" {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		address = net.JoinHostPort(host, strconv.Itoa(c.opts.Port))
+	}
+
+	dialer := net.Dialer{Timeout: c.opts.Timeout}
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if c.opts.Send == "" && c.opts.Expect == "" {
+		return nil
+	}
+
+	if err = conn.SetDeadline(time.Now().Add(c.opts.Timeout)); err != nil {
+		return err
+	}
+
+	if c.opts.Send != "" {
+		if _, err = conn.Write([]byte(c.opts.Send)); err != nil {
+			return fmt.Errorf("failed to send payload: %w", err)
+		}
+	}
+
+	return c.readExpected(conn)
+}
+
+// readExpected reads the server response until it contains the expected payload.
+// A UDP response is a single datagram.
+func (c *ServiceChecker) readExpected(conn net.Conn) error {
+	var response []byte
+	buf := make([]byte, maxResponseSize)
+	for {
+		n, err := conn.Read(buf)
+		response = append(response, buf[:n]...)
+
+		if n > 0 && bytes.Contains(response, []byte(c.opts.Expect)) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read expected payload: %w", err)
+		}
+
+		if c.network == "udp" || len(response) >= maxResponseSize {
+			return errors.New("unexpected response")
+		}
+	}
+}
+
+// ServiceHealthCheck runs the health checks of a set of TCP or UDP services.
+type ServiceHealthCheck struct {
+	Checkers map[string]*ServiceChecker
+	cancel   context.CancelFunc
+}
+
+// GetTCPHealthCheck returns the health check of the TCP services, which is guaranteed to be a singleton.
+func GetTCPHealthCheck() *ServiceHealthCheck {
+	tcpOnce.Do(func() {
+		tcpSingleton = &ServiceHealthCheck{}
+	})
+	return tcpSingleton
+}
+
+// GetUDPHealthCheck returns the health check of the UDP services, which is guaranteed to be a singleton.
+func GetUDPHealthCheck() *ServiceHealthCheck {
+	udpOnce.Do(func() {
+		udpSingleton = &ServiceHealthCheck{}
+	})
+	return udpSingleton
+}
+
+// SetCheckers stops the health checks of the previous configuration, and starts the given ones.
+func (hc *ServiceHealthCheck) SetCheckers(parentCtx context.Context, checkers map[string]*ServiceChecker) {
+	hc.Checkers = checkers
+	if hc.cancel != nil {
+		hc.cancel()
+	}
+	ctx, cancel := context.WithCancel(parentCtx)
+	hc.cancel = cancel
+
+	for serviceName, checker := range checkers {
+		currentChecker := checker
+		checkerCtx := log.With(ctx, log.Str(log.ServiceName, serviceName))
+		safe.Go(func() {
+			currentChecker.execute(checkerCtx)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package healthcheck
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStatusSetter struct {
+	status map[string]bool
+}
+
+func (s *testStatusSetter) SetStatus(_ context.Context, name string, up bool) {
+	s.status[name] = up
+}
+
+func TestServiceChecker_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	var healthy int32 = 1
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			buf := make([]byte, 4)
+			if _, err := conn.Read(buf); err == nil && string(buf) == "PING" {
+				if atomic.LoadInt32(&healthy) == 1 {
+					_, _ = conn.Write([]byte("+PONG\r\n"))
+				} else {
+					_, _ = conn.Write([]byte("-ERR\r\n"))
+				}
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	address := listener.Addr().String()
+	info := &runtime.TCPServiceInfo{}
+	lb := &testStatusSetter{status: make(map[string]bool)}
+
+	checker := NewTCPServiceChecker("foo", ServiceOptions{
+		Timeout:            time.Second,
+		UnhealthyThreshold: 2,
+		HealthyThreshold:   2,
+		Send:               "PING",
+		Expect:             "PONG",
+	}, info)
+	checker.AddBalancer(lb)
+	checker.AddServer(address)
+
+	assert.Equal(t, serverUp, info.GetAllStatus()[address])
+
+	checker.checkServers(context.Background())
+	assert.Empty(t, lb.status)
+
+	atomic.StoreInt32(&healthy, 0)
+
+	checker.checkServers(context.Background())
+	assert.Empty(t, lb.status)
+
+	checker.checkServers(context.Background())
+	assert.Equal(t, map[string]bool{address: false}, lb.status)
+	assert.Equal(t, serverDown, info.GetAllStatus()[address])
+
+	atomic.StoreInt32(&healthy, 1)
+
+	checker.checkServers(context.Background())
+	assert.Equal(t, map[string]bool{address: false}, lb.status)
+
+	checker.checkServers(context.Background())
+	assert.Equal(t, map[string]bool{address: true}, lb.status)
+	assert.Equal(t, serverUp, info.GetAllStatus()[address])
+}
+
+func TestServiceChecker_TCPConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	lb := &testStatusSetter{status: make(map[string]bool)}
+
+	checker := NewTCPServiceChecker("foo", ServiceOptions{
+		Timeout:            time.Second,
+		UnhealthyThreshold: 1,
+		HealthyThreshold:   1,
+	}, nil)
+	checker.AddBalancer(lb)
+	checker.AddServer(address)
+
+	checker.checkServers(context.Background())
+	assert.Equal(t, map[string]bool{address: false}, lb.status)
+}
+
+func TestServiceChecker_unixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	address := "unix://" + socket
+	lb := &testStatusSetter{status: make(map[string]bool)}
+
+	checker := NewTCPServiceChecker("foo", ServiceOptions{
+		Port:               8080,
+		Timeout:            time.Second,
+		UnhealthyThreshold: 1,
+		HealthyThreshold:   1,
+	}, nil)
+	checker.AddBalancer(lb)
+	checker.AddServer(address)
+
+	checker.checkServers(context.Background())
+	assert.Empty(t, lb.status)
+
+	require.NoError(t, listener.Close())
+
+	checker.checkServers(context.Background())
+	assert.Equal(t, map[string]bool{address: false}, lb.status)
+}
+
+func TestServiceChecker_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	var healthy int32 = 1
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			if atomic.LoadInt32(&healthy) == 1 {
+				_, _ = conn.WriteTo(buf[:n], addr)
+			}
+		}
+	}()
+
+	address := conn.LocalAddr().String()
+	info := &runtime.UDPServiceInfo{}
+	lb := &testStatusSetter{status: make(map[string]bool)}
+
+	checker := NewUDPServiceChecker("foo", ServiceOptions{
+		Timeout:            100 * time.Millisecond,
+		UnhealthyThreshold: 1,
+		HealthyThreshold:   1,
+		Send:               "ping",
+		Expect:             "ping",
+	}, info)
+	checker.AddBalancer(lb)
+	checker.AddServer(address)
+
+	checker.checkServers(context.Background())
+	assert.Empty(t, lb.status)
+
+	atomic.StoreInt32(&healthy, 0)
+
+	checker.checkServers(context.Background())
+	assert.Equal(t, map[string]bool{address: false}, lb.status)
+	assert.Equal(t, serverDown, info.GetAllStatus()[address])
+
+	atomic.StoreInt32(&healthy, 1)
+
+	checker.checkServers(context.Background())
+	assert.Equal(t, map[string]bool{address: true}, lb.status)
+	assert.Equal(t, serverUp, info.GetAllStatus()[address])
+}
@@ -32,6 +32,10 @@ const (
 	ddEntryPointOpenConnsName     = "entrypoint.connections.open"
 	ddOpenConnsName               = "service.connections.open"
 	ddServerUpName                = "service.server.up"
+	ddMirrorMismatchesName        = "service.mirror.mismatches.total"
+	ddHedgesTotalName             = "service.hedges.total"
+	ddConcurrencyLimitName        = "middleware.concurrency.limit"
+	ddCacheRequestsName           = "middleware.cache.requests.total"
 )
 
 // RegisterDatadog registers the metrics pusher if this didn't happen yet and creates a datadog Registry instance.
@@ -56,11 +60,16 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
+		registry.mdlwEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = datadogClient.NewCounter(ddMetricsServiceReqsName, 1.0)
 		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddMetricsServiceLatencyName, 1.0), time.Second)
 		registry.serviceRetriesCounter = datadogClient.NewCounter(ddRetriesTotalName, 1.0)
 		registry.serviceOpenConnsGauge = datadogClient.NewGauge(ddOpenConnsName)
 		registry.serviceServerUpGauge = datadogClient.NewGauge(ddServerUpName)
+		registry.serviceMirrorMismatchesCounter = datadogClient.NewCounter(ddMirrorMismatchesName, 1.0)
+		registry.serviceHedgesCounter = datadogClient.NewCounter(ddHedgesTotalName, 1.0)
+		registry.middlewareConcurrencyLimitGauge = datadogClient.NewGauge(ddConcurrencyLimitName)
+		registry.middlewareCacheRequestsCounter = datadogClient.NewCounter(ddCacheRequestsName, 1.0)
 	}
 
 	return registry
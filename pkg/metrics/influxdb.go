@@ -38,6 +38,10 @@ const (
 	influxDBEntryPointOpenConnsName     = "traefik.entrypoint.connections.open"
 	influxDBOpenConnsName               = "traefik.service.connections.open"
 	influxDBServerUpName                = "traefik.service.server.up"
+	influxDBMirrorMismatchesName        = "traefik.service.mirror.mismatches.total"
+	influxDBHedgesTotalName             = "traefik.service.hedges.total"
+	influxDBConcurrencyLimitName        = "traefik.middleware.concurrency.limit"
+	influxDBCacheRequestsName           = "traefik.middleware.cache.requests.total"
 )
 
 const (
@@ -70,11 +74,16 @@ func RegisterInfluxDB(ctx context.Context, config *types.InfluxDB) Registry {
 
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
+		registry.mdlwEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = influxDBClient.NewCounter(influxDBMetricsServiceReqsName)
 		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(influxDBClient.NewHistogram(influxDBMetricsServiceLatencyName), time.Second)
 		registry.serviceRetriesCounter = influxDBClient.NewCounter(influxDBRetriesTotalName)
 		registry.serviceOpenConnsGauge = influxDBClient.NewGauge(influxDBOpenConnsName)
 		registry.serviceServerUpGauge = influxDBClient.NewGauge(influxDBServerUpName)
+		registry.serviceMirrorMismatchesCounter = influxDBClient.NewCounter(influxDBMirrorMismatchesName)
+		registry.serviceHedgesCounter = influxDBClient.NewCounter(influxDBHedgesTotalName)
+		registry.middlewareConcurrencyLimitGauge = influxDBClient.NewGauge(influxDBConcurrencyLimitName)
+		registry.middlewareCacheRequestsCounter = influxDBClient.NewCounter(influxDBCacheRequestsName)
 	}
 
 	return registry
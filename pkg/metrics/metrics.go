@@ -14,6 +14,8 @@ type Registry interface {
 	IsEpEnabled() bool
 	// IsSvcEnabled shows whether metrics instrumentation is enabled on services.
 	IsSvcEnabled() bool
+	// IsMdlwEnabled shows whether metrics instrumentation is enabled on middlewares.
+	IsMdlwEnabled() bool
 
 	// server metrics
 	ConfigReloadsCounter() metrics.Counter
@@ -34,6 +36,12 @@ type Registry interface {
 	ServiceOpenConnsGauge() metrics.Gauge
 	ServiceRetriesCounter() metrics.Counter
 	ServiceServerUpGauge() metrics.Gauge
+	ServiceMirrorMismatchesCounter() metrics.Counter
+	ServiceHedgesCounter() metrics.Counter
+
+	// middleware metrics
+	MiddlewareConcurrencyLimitGauge() metrics.Gauge
+	MiddlewareCacheRequestsCounter() metrics.Counter
 }
 
 // NewVoidRegistry is a noop implementation of metrics.Registry.
@@ -60,6 +68,10 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var serviceOpenConnsGauge []metrics.Gauge
 	var serviceRetriesCounter []metrics.Counter
 	var serviceServerUpGauge []metrics.Gauge
+	var serviceMirrorMismatchesCounter []metrics.Counter
+	var serviceHedgesCounter []metrics.Counter
+	var middlewareConcurrencyLimitGauge []metrics.Gauge
+	var middlewareCacheRequestsCounter []metrics.Counter
 
 	for _, r := range registries {
 		if r.ConfigReloadsCounter() != nil {
@@ -104,45 +116,67 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.ServiceServerUpGauge() != nil {
 			serviceServerUpGauge = append(serviceServerUpGauge, r.ServiceServerUpGauge())
 		}
+		if r.ServiceMirrorMismatchesCounter() != nil {
+			serviceMirrorMismatchesCounter = append(serviceMirrorMismatchesCounter, r.ServiceMirrorMismatchesCounter())
+		}
+		if r.ServiceHedgesCounter() != nil {
+			serviceHedgesCounter = append(serviceHedgesCounter, r.ServiceHedgesCounter())
+		}
+		if r.MiddlewareConcurrencyLimitGauge() != nil {
+			middlewareConcurrencyLimitGauge = append(middlewareConcurrencyLimitGauge, r.MiddlewareConcurrencyLimitGauge())
+		}
+		if r.MiddlewareCacheRequestsCounter() != nil {
+			middlewareCacheRequestsCounter = append(middlewareCacheRequestsCounter, r.MiddlewareCacheRequestsCounter())
+		}
 	}
 
 	return &standardRegistry{
-		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		configReloadsFailureCounter:    multi.NewCounter(configReloadsFailureCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		lastConfigReloadFailureGauge:   multi.NewGauge(lastConfigReloadFailureGauge...),
-		entryPointReqsCounter:          multi.NewCounter(entryPointReqsCounter...),
-		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
-		entryPointReqDurationHistogram: NewMultiHistogram(entryPointReqDurationHistogram...),
-		entryPointOpenConnsGauge:       multi.NewGauge(entryPointOpenConnsGauge...),
-		serviceReqsCounter:             multi.NewCounter(serviceReqsCounter...),
-		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
-		serviceReqDurationHistogram:    NewMultiHistogram(serviceReqDurationHistogram...),
-		serviceOpenConnsGauge:          multi.NewGauge(serviceOpenConnsGauge...),
-		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
-		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
+		epEnabled:                       len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0 || len(entryPointOpenConnsGauge) > 0,
+		svcEnabled:                      len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceOpenConnsGauge) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0 || len(serviceMirrorMismatchesCounter) > 0 || len(serviceHedgesCounter) > 0,
+		mdlwEnabled:                     len(middlewareConcurrencyLimitGauge) > 0 || len(middlewareCacheRequestsCounter) > 0,
+		configReloadsCounter:            multi.NewCounter(configReloadsCounter...),
+		configReloadsFailureCounter:     multi.NewCounter(configReloadsFailureCounter...),
+		lastConfigReloadSuccessGauge:    multi.NewGauge(lastConfigReloadSuccessGauge...),
+		lastConfigReloadFailureGauge:    multi.NewGauge(lastConfigReloadFailureGauge...),
+		entryPointReqsCounter:           multi.NewCounter(entryPointReqsCounter...),
+		entryPointReqsTLSCounter:        multi.NewCounter(entryPointReqsTLSCounter...),
+		entryPointReqDurationHistogram:  NewMultiHistogram(entryPointReqDurationHistogram...),
+		entryPointOpenConnsGauge:        multi.NewGauge(entryPointOpenConnsGauge...),
+		serviceReqsCounter:              multi.NewCounter(serviceReqsCounter...),
+		serviceReqsTLSCounter:           multi.NewCounter(serviceReqsTLSCounter...),
+		serviceReqDurationHistogram:     NewMultiHistogram(serviceReqDurationHistogram...),
+		serviceOpenConnsGauge:           multi.NewGauge(serviceOpenConnsGauge...),
+		serviceRetriesCounter:           multi.NewCounter(serviceRetriesCounter...),
+		serviceServerUpGauge:            multi.NewGauge(serviceServerUpGauge...),
+		serviceMirrorMismatchesCounter:  multi.NewCounter(serviceMirrorMismatchesCounter...),
+		serviceHedgesCounter:            multi.NewCounter(serviceHedgesCounter...),
+		middlewareConcurrencyLimitGauge: multi.NewGauge(middlewareConcurrencyLimitGauge...),
+		middlewareCacheRequestsCounter:  multi.NewCounter(middlewareCacheRequestsCounter...),
 	}
 }
 
 type standardRegistry struct {
-	epEnabled                      bool
-	svcEnabled                     bool
-	configReloadsCounter           metrics.Counter
-	configReloadsFailureCounter    metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	lastConfigReloadFailureGauge   metrics.Gauge
-	entryPointReqsCounter          metrics.Counter
-	entryPointReqsTLSCounter       metrics.Counter
-	entryPointReqDurationHistogram ScalableHistogram
-	entryPointOpenConnsGauge       metrics.Gauge
-	serviceReqsCounter             metrics.Counter
-	serviceReqsTLSCounter          metrics.Counter
-	serviceReqDurationHistogram    ScalableHistogram
-	serviceOpenConnsGauge          metrics.Gauge
-	serviceRetriesCounter          metrics.Counter
-	serviceServerUpGauge           metrics.Gauge
+	epEnabled                       bool
+	svcEnabled                      bool
+	mdlwEnabled                     bool
+	configReloadsCounter            metrics.Counter
+	configReloadsFailureCounter     metrics.Counter
+	lastConfigReloadSuccessGauge    metrics.Gauge
+	lastConfigReloadFailureGauge    metrics.Gauge
+	entryPointReqsCounter           metrics.Counter
+	entryPointReqsTLSCounter        metrics.Counter
+	entryPointReqDurationHistogram  ScalableHistogram
+	entryPointOpenConnsGauge        metrics.Gauge
+	serviceReqsCounter              metrics.Counter
+	serviceReqsTLSCounter           metrics.Counter
+	serviceReqDurationHistogram     ScalableHistogram
+	serviceOpenConnsGauge           metrics.Gauge
+	serviceRetriesCounter           metrics.Counter
+	serviceServerUpGauge            metrics.Gauge
+	serviceMirrorMismatchesCounter  metrics.Counter
+	serviceHedgesCounter            metrics.Counter
+	middlewareConcurrencyLimitGauge metrics.Gauge
+	middlewareCacheRequestsCounter  metrics.Counter
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
@@ -153,6 +187,10 @@ func (r *standardRegistry) IsSvcEnabled() bool {
 	return r.svcEnabled
 }
 
+func (r *standardRegistry) IsMdlwEnabled() bool {
+	return r.mdlwEnabled
+}
+
 func (r *standardRegistry) ConfigReloadsCounter() metrics.Counter {
 	return r.configReloadsCounter
 }
@@ -209,6 +247,22 @@ func (r *standardRegistry) ServiceServerUpGauge() metrics.Gauge {
 	return r.serviceServerUpGauge
 }
 
+func (r *standardRegistry) ServiceMirrorMismatchesCounter() metrics.Counter {
+	return r.serviceMirrorMismatchesCounter
+}
+
+func (r *standardRegistry) ServiceHedgesCounter() metrics.Counter {
+	return r.serviceHedgesCounter
+}
+
+func (r *standardRegistry) MiddlewareConcurrencyLimitGauge() metrics.Gauge {
+	return r.middlewareConcurrencyLimitGauge
+}
+
+func (r *standardRegistry) MiddlewareCacheRequestsCounter() metrics.Counter {
+	return r.middlewareCacheRequestsCounter
+}
+
 // ScalableHistogram is a Histogram with a predefined time unit,
 // used when producing observations without explicitly setting the observed value.
 type ScalableHistogram interface {
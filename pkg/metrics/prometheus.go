@@ -45,6 +45,15 @@ const (
 	serviceOpenConnsName    = MetricServicePrefix + "open_connections"
 	serviceRetriesTotalName = MetricServicePrefix + "retries_total"
 	serviceServerUpName     = MetricServicePrefix + "server_up"
+	serviceMismatchesName   = MetricServicePrefix + "mirror_mismatches_total"
+	serviceHedgesTotalName  = MetricServicePrefix + "hedges_total"
+
+	// middleware level.
+
+	// MetricMiddlewarePrefix prefix of all middleware metric names
+	MetricMiddlewarePrefix         = MetricNamePrefix + "middleware_"
+	middlewareConcurrencyLimitName = MetricMiddlewarePrefix + "concurrency_limit"
+	middlewareCacheRequestsName    = MetricMiddlewarePrefix + "cache_requests_total"
 )
 
 // promState holds all metric state internally and acts as the only Collector we register for Prometheus.
@@ -128,6 +137,7 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 	reg := &standardRegistry{
 		epEnabled:                    config.AddEntryPointsLabels,
 		svcEnabled:                   config.AddServicesLabels,
+		mdlwEnabled:                  config.AddServicesLabels,
 		configReloadsCounter:         configReloads,
 		configReloadsFailureCounter:  configReloadsFailures,
 		lastConfigReloadSuccessGauge: lastConfigReloadSuccess,
@@ -190,6 +200,22 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			Name: serviceServerUpName,
 			Help: "service server is up, described by gauge value of 0 or 1.",
 		}, []string{"service", "url"})
+		serviceMirrorMismatches := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: serviceMismatchesName,
+			Help: "How many mirrored responses differed from the response of the main service, partitioned by mirror and reason.",
+		}, []string{"service", "mirror", "reason"})
+		serviceHedges := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: serviceHedgesTotalName,
+			Help: "How many hedged requests were sent, partitioned by service and result (won or lost).",
+		}, []string{"service", "result"})
+		middlewareConcurrencyLimit := newGaugeFrom(promState.collectors, stdprometheus.GaugeOpts{
+			Name: middlewareConcurrencyLimitName,
+			Help: "The current concurrency limit of an adaptive in-flight requests middleware, averaged over its sources.",
+		}, []string{"middleware"})
+		middlewareCacheRequests := newCounterFrom(promState.collectors, stdprometheus.CounterOpts{
+			Name: middlewareCacheRequestsName,
+			Help: "How many requests were handled by a cache middleware, partitioned by cache status (HIT, MISS, STALE, REVALIDATED or BYPASS).",
+		}, []string{"middleware", "status"})
 
 		promState.describers = append(promState.describers, []func(chan<- *stdprometheus.Desc){
 			serviceReqs.cv.Describe,
@@ -198,6 +224,10 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 			serviceOpenConns.gv.Describe,
 			serviceRetries.cv.Describe,
 			serviceServerUp.gv.Describe,
+			serviceMirrorMismatches.cv.Describe,
+			serviceHedges.cv.Describe,
+			middlewareConcurrencyLimit.gv.Describe,
+			middlewareCacheRequests.cv.Describe,
 		}...)
 
 		reg.serviceReqsCounter = serviceReqs
@@ -206,6 +236,10 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		reg.serviceOpenConnsGauge = serviceOpenConns
 		reg.serviceRetriesCounter = serviceRetries
 		reg.serviceServerUpGauge = serviceServerUp
+		reg.serviceMirrorMismatchesCounter = serviceMirrorMismatches
+		reg.serviceHedgesCounter = serviceHedges
+		reg.middlewareConcurrencyLimitGauge = middlewareConcurrencyLimit
+		reg.middlewareCacheRequestsCounter = middlewareCacheRequests
 	}
 
 	return reg
@@ -237,6 +271,10 @@ func OnConfigurationUpdate(conf dynamic.Configuration, entryPoints []string) {
 		dynamicConfig.routers[name] = true
 	}
 
+	for name := range conf.HTTP.Middlewares {
+		dynamicConfig.middlewares[name] = true
+	}
+
 	for serviceName, service := range conf.HTTP.Services {
 		dynamicConfig.services[serviceName] = make(map[string]bool)
 		if service.LoadBalancer != nil {
@@ -321,6 +359,10 @@ func (ps *prometheusState) isOutdated(collector *collector) bool {
 		return true
 	}
 
+	if middlewareName, ok := labels["middleware"]; ok && !ps.dynamicConfig.hasMiddleware(middlewareName) {
+		return true
+	}
+
 	if serviceName, ok := labels["service"]; ok {
 		if !ps.dynamicConfig.hasService(serviceName) {
 			return true
@@ -337,6 +379,7 @@ func newDynamicConfig() *dynamicConfig {
 	return &dynamicConfig{
 		entryPoints: make(map[string]bool),
 		routers:     make(map[string]bool),
+		middlewares: make(map[string]bool),
 		services:    make(map[string]map[string]bool),
 	}
 }
@@ -348,6 +391,7 @@ func newDynamicConfig() *dynamicConfig {
 type dynamicConfig struct {
 	entryPoints map[string]bool
 	routers     map[string]bool
+	middlewares map[string]bool
 	services    map[string]map[string]bool
 }
 
@@ -356,6 +400,11 @@ func (d *dynamicConfig) hasEntryPoint(entrypointName string) bool {
 	return ok
 }
 
+func (d *dynamicConfig) hasMiddleware(middlewareName string) bool {
+	_, ok := d.middlewares[middlewareName]
+	return ok
+}
+
 func (d *dynamicConfig) hasService(serviceName string) bool {
 	_, ok := d.services[serviceName]
 	return ok
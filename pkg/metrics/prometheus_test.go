@@ -149,6 +149,22 @@ func TestPrometheus(t *testing.T) {
 		ServiceServerUpGauge().
 		With("service", "service1", "url", "http://127.0.0.10:80").
 		Set(1)
+	prometheusRegistry.
+		ServiceMirrorMismatchesCounter().
+		With("service", "service1", "mirror", "mirror1", "reason", "status").
+		Add(1)
+	prometheusRegistry.
+		ServiceHedgesCounter().
+		With("service", "service1", "result", "won").
+		Add(1)
+	prometheusRegistry.
+		MiddlewareConcurrencyLimitGauge().
+		With("middleware", "middleware1").
+		Set(20)
+	prometheusRegistry.
+		MiddlewareCacheRequestsCounter().
+		With("middleware", "middleware1", "status", "HIT").
+		Add(1)
 
 	delayForTrackingCompletion()
 
@@ -248,6 +264,38 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, serviceServerUpName, 1),
 		},
+		{
+			name: serviceMismatchesName,
+			labels: map[string]string{
+				"service": "service1",
+				"mirror":  "mirror1",
+				"reason":  "status",
+			},
+			assert: buildCounterAssert(t, serviceMismatchesName, 1),
+		},
+		{
+			name: serviceHedgesTotalName,
+			labels: map[string]string{
+				"service": "service1",
+				"result":  "won",
+			},
+			assert: buildCounterAssert(t, serviceHedgesTotalName, 1),
+		},
+		{
+			name: middlewareConcurrencyLimitName,
+			labels: map[string]string{
+				"middleware": "middleware1",
+			},
+			assert: buildGaugeAssert(t, middlewareConcurrencyLimitName, 20),
+		},
+		{
+			name: middlewareCacheRequestsName,
+			labels: map[string]string{
+				"middleware": "middleware1",
+				"status":     "HIT",
+			},
+			assert: buildCounterAssert(t, middlewareCacheRequestsName, 1),
+		},
 	}
 
 	for _, test := range testCases {
@@ -315,11 +363,15 @@ func TestPrometheusMetricRemoval(t *testing.T) {
 		ServiceServerUpGauge().
 		With("service", "service1", "url", "http://localhost:9999").
 		Set(1)
+	prometheusRegistry.
+		MiddlewareConcurrencyLimitGauge().
+		With("middleware", "middleware2").
+		Set(1)
 
 	delayForTrackingCompletion()
 
-	assertMetricsExist(t, mustScrape(), entryPointReqsTotalName, serviceReqsTotalName, serviceServerUpName)
-	assertMetricsAbsent(t, mustScrape(), entryPointReqsTotalName, serviceReqsTotalName, serviceServerUpName)
+	assertMetricsExist(t, mustScrape(), entryPointReqsTotalName, serviceReqsTotalName, serviceServerUpName, middlewareConcurrencyLimitName)
+	assertMetricsAbsent(t, mustScrape(), entryPointReqsTotalName, serviceReqsTotalName, serviceServerUpName, middlewareConcurrencyLimitName)
 
 	// To verify that metrics belonging to active configurations are not removed
 	// here the counter examples.
@@ -27,6 +27,10 @@ const (
 	statsdEntryPointOpenConnsName     = "entrypoint.connections.open"
 	statsdOpenConnsName               = "service.connections.open"
 	statsdServerUpName                = "service.server.up"
+	statsdMirrorMismatchesName        = "service.mirror.mismatches.total"
+	statsdHedgesTotalName             = "service.hedges.total"
+	statsdConcurrencyLimitName        = "middleware.concurrency.limit"
+	statsdCacheRequestsName           = "middleware.cache.requests.total"
 )
 
 // RegisterStatsd registers the metrics pusher if this didn't happen yet and creates a statsd Registry instance.
@@ -61,11 +65,16 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
+		registry.mdlwEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = statsdClient.NewCounter(statsdMetricsServiceReqsName, 1.0)
 		registry.serviceReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdMetricsServiceLatencyName, 1.0), time.Millisecond)
 		registry.serviceRetriesCounter = statsdClient.NewCounter(statsdRetriesTotalName, 1.0)
 		registry.serviceOpenConnsGauge = statsdClient.NewGauge(statsdOpenConnsName)
 		registry.serviceServerUpGauge = statsdClient.NewGauge(statsdServerUpName)
+		registry.serviceMirrorMismatchesCounter = statsdClient.NewCounter(statsdMirrorMismatchesName, 1.0)
+		registry.serviceHedgesCounter = statsdClient.NewCounter(statsdHedgesTotalName, 1.0)
+		registry.middlewareConcurrencyLimitGauge = statsdClient.NewGauge(statsdConcurrencyLimitName)
+		registry.middlewareCacheRequestsCounter = statsdClient.NewCounter(statsdCacheRequestsName, 1.0)
 	}
 
 	return registry
@@ -70,6 +70,10 @@ const (
 	Overhead = "Overhead"
 	// RetryAttempts is the map key used for the amount of attempts the request was retried.
 	RetryAttempts = "RetryAttempts"
+	// HedgeAttempts is the map key used for the amount of copies of the request sent to other servers by the hedging of the service.
+	HedgeAttempts = "HedgeAttempts"
+	// CacheStatus is the map key used for the status of the response in the cache (HIT, MISS, STALE, REVALIDATED or BYPASS).
+	CacheStatus = "CacheStatus"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -111,6 +115,8 @@ func init() {
 	allCoreKeys[StartLocal] = struct{}{}
 	allCoreKeys[Overhead] = struct{}{}
 	allCoreKeys[RetryAttempts] = struct{}{}
+	allCoreKeys[HedgeAttempts] = struct{}{}
+	allCoreKeys[CacheStatus] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
@@ -0,0 +1,238 @@
+// Package adaptiveinflightreq implements a middleware limiting the number of requests in flight,
+// with a limit adjusted from the observed latencies.
+package adaptiveinflightreq
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	ptypes "github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/vulcand/oxy/utils"
+)
+
+const (
+	typeName = "AdaptiveInFlightReq"
+
+	algorithmGradient = "gradient"
+	algorithmVegas    = "vegas"
+
+	defaultInitialLimit = 20
+	defaultMaxLimit     = 1000
+	defaultQueueTimeout = time.Second
+
+	// The limiters of the sources without requests for sourceTTL are dropped, and checked every cleanupInterval.
+	sourceTTL       = 10 * time.Minute
+	cleanupInterval = time.Minute
+)
+
+type adaptiveInFlightReq struct {
+	name          string
+	next          http.Handler
+	sourceMatcher utils.SourceExtractor
+	newAlgorithm  func() algorithm
+	initialLimit  float64
+	minLimit      float64
+	maxLimit      float64
+	maxQueue      int64
+	queueTimeout  time.Duration
+	limitGauge    metrics.Gauge
+
+	mu          sync.Mutex
+	limiters    map[string]*limiter
+	lastCleanup time.Time
+
+	// totalLimit is the sum of the limits of the sources, and sources their number,
+	// whose ratio is reported by limitGauge.
+	limitMu    sync.Mutex
+	totalLimit int64
+	sources    int64
+}
+
+// New creates an adaptive max request middleware.
+// If no source criterion is provided in the config, it defaults to RequestHost.
+// limitGauge, if not nil, reports the mean of the limits of the sources,
+// which is the limit itself when all the requests come from a single source,
+// as a series per source would grow with the number of sources.
+func New(ctx context.Context, next http.Handler, config dynamic.AdaptiveInFlightReq, limitGauge metrics.Gauge, name string) (http.Handler, error) {
+	ctxLog := log.With(ctx, log.Str(log.MiddlewareName, name), log.Str(log.MiddlewareType, typeName))
+	log.FromContext(ctxLog).Debug("Creating middleware")
+
+	var newAlgorithm func() algorithm
+	switch config.Algorithm {
+	case algorithmGradient, "":
+		newAlgorithm = func() algorithm { return &gradient{} }
+	case algorithmVegas:
+		newAlgorithm = func() algorithm { return &vegas{} }
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", config.Algorithm)
+	}
+
+	if config.MinLimit < 1 {
+		config.MinLimit = 1
+	}
+
+	if config.MaxLimit == 0 {
+		config.MaxLimit = defaultMaxLimit
+	}
+
+	if config.InitialLimit == 0 {
+		config.InitialLimit = defaultInitialLimit
+	}
+
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = ptypes.Duration(defaultQueueTimeout)
+	}
+
+	if config.MaxLimit < config.MinLimit {
+		return nil, fmt.Errorf("maxLimit %d is lower than minLimit %d", config.MaxLimit, config.MinLimit)
+	}
+
+	if config.InitialLimit < config.MinLimit || config.InitialLimit > config.MaxLimit {
+		return nil, fmt.Errorf("initialLimit %d is not between minLimit %d and maxLimit %d", config.InitialLimit, config.MinLimit, config.MaxLimit)
+	}
+
+	if config.SourceCriterion == nil ||
+		config.SourceCriterion.IPStrategy == nil &&
+			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+		config.SourceCriterion = &dynamic.SourceCriterion{
+			RequestHost: true,
+		}
+	}
+
+	sourceMatcher, err := middlewares.GetSourceExtractor(ctxLog, config.SourceCriterion)
+	if err != nil {
+		return nil, fmt.Errorf("error creating requests limiter: %w", err)
+	}
+
+	if limitGauge != nil {
+		limitGauge = limitGauge.With("middleware", name)
+	}
+
+	return &adaptiveInFlightReq{
+		name:          name,
+		next:          next,
+		sourceMatcher: sourceMatcher,
+		newAlgorithm:  newAlgorithm,
+		initialLimit:  float64(config.InitialLimit),
+		minLimit:      float64(config.MinLimit),
+		maxLimit:      float64(config.MaxLimit),
+		maxQueue:      config.MaxQueue,
+		queueTimeout:  time.Duration(config.QueueTimeout),
+		limitGauge:    limitGauge,
+		limiters:      make(map[string]*limiter),
+		lastCleanup:   time.Now(),
+	}, nil
+}
+
+func (a *adaptiveInFlightReq) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *adaptiveInFlightReq) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), a.name, typeName)
+	logger := log.FromContext(ctx)
+
+	source, _, err := a.sourceMatcher.Extract(req)
+	if err != nil {
+		logger.Errorf("could not extract source of request: %v", err)
+		http.Error(rw, "could not extract source of request", http.StatusInternalServerError)
+		return
+	}
+
+	l := a.getLimiter(source)
+
+	inFlight, ok := l.acquire(req.Context(), a.maxQueue, a.queueTimeout)
+	if !ok {
+		logger.Debugf("Limiting request source %s: too many requests in flight", source)
+		http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	recorder := middlewares.NewStatusRecorder(rw)
+	start := time.Now()
+
+	defer func() {
+		// The latency of a request canceled by the client says nothing about the backends.
+		sample := req.Context().Err() == nil
+		l.release(time.Since(start), inFlight, dropped(recorder.Code()), sample)
+	}()
+
+	a.next.ServeHTTP(recorder, req)
+}
+
+// getLimiter returns the limiter of the given source, creating it if needed,
+// and drops the limiters of the sources which have been idle for too long.
+func (a *adaptiveInFlightReq) getLimiter(source string) *limiter {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(a.lastCleanup) > cleanupInterval {
+		a.lastCleanup = now
+		for s, l := range a.limiters {
+			if l.idle(now.Add(-sourceTTL)) {
+				delete(a.limiters, s)
+				a.addLimit(-l.capacityLocked(), -1)
+			}
+		}
+	}
+
+	if l, ok := a.limiters[source]; ok {
+		return l
+	}
+
+	l := &limiter{
+		algorithm: a.newAlgorithm(),
+		limit:     a.initialLimit,
+		minLimit:  a.minLimit,
+		maxLimit:  a.maxLimit,
+		waiters:   list.New(),
+		lastUsed:  now,
+	}
+
+	if a.limitGauge != nil {
+		l.onUpdate = func(previous, limit float64) {
+			a.addLimit(int64(limit)-int64(previous), 0)
+		}
+		a.addLimit(int64(a.initialLimit), 1)
+	}
+
+	a.limiters[source] = l
+	return l
+}
+
+// addLimit adds delta to the sum of the limits of the sources, and sourcesDelta to their number,
+// and reports the mean of the limits.
+func (a *adaptiveInFlightReq) addLimit(delta, sourcesDelta int64) {
+	if a.limitGauge == nil || delta == 0 && sourcesDelta == 0 {
+		return
+	}
+
+	a.limitMu.Lock()
+	defer a.limitMu.Unlock()
+
+	a.totalLimit += delta
+	a.sources += sourcesDelta
+
+	if a.sources == 0 {
+		a.limitGauge.Set(0)
+		return
+	}
+	a.limitGauge.Set(float64(a.totalLimit) / float64(a.sources))
+}
+
+// dropped reports whether the response status code means that the backend could not handle the request,
+// likely because it is overloaded.
+func dropped(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
@@ -0,0 +1,174 @@
+package adaptiveinflightreq
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	ptypes "github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testGauge struct {
+	labels []string
+	values map[string]float64
+}
+
+func (g *testGauge) With(labelValues ...string) metrics.Gauge {
+	return &testGauge{labels: labelValues, values: g.values}
+}
+
+func (g *testGauge) Set(value float64) {
+	g.values[strings.Join(g.labels, ",")] = value
+}
+
+func (g *testGauge) Add(delta float64) {
+	g.values[strings.Join(g.labels, ",")] += delta
+}
+
+// blockingHandler answers the requests once released.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.started <- struct{}{}
+	<-h.release
+}
+
+func TestAdaptiveInFlightReq(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		config       dynamic.AdaptiveInFlightReq
+		secondHost   string
+		releaseFirst bool
+		expected     int
+	}{
+		{
+			desc:       "rejected above the limit",
+			config:     dynamic.AdaptiveInFlightReq{InitialLimit: 1, MaxLimit: 1},
+			secondHost: "foo.com",
+			expected:   http.StatusServiceUnavailable,
+		},
+		{
+			desc:       "limited by source",
+			config:     dynamic.AdaptiveInFlightReq{InitialLimit: 1, MaxLimit: 1},
+			secondHost: "bar.com",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:         "queued until a slot is released",
+			config:       dynamic.AdaptiveInFlightReq{InitialLimit: 1, MaxLimit: 1, MaxQueue: 1, QueueTimeout: ptypes.Duration(time.Second)},
+			secondHost:   "foo.com",
+			releaseFirst: true,
+			expected:     http.StatusOK,
+		},
+		{
+			desc:       "rejected after the queue timeout",
+			config:     dynamic.AdaptiveInFlightReq{InitialLimit: 1, MaxLimit: 1, MaxQueue: 1, QueueTimeout: ptypes.Duration(10 * time.Millisecond)},
+			secondHost: "foo.com",
+			expected:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := newBlockingHandler()
+			handler, err := New(context.Background(), next, test.config, nil, "foo")
+			require.NoError(t, err)
+
+			firstDone := make(chan struct{})
+			go func() {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+				close(firstDone)
+			}()
+			<-next.started
+
+			if test.releaseFirst {
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					next.release <- struct{}{}
+				}()
+			}
+
+			secondDone := make(chan struct{})
+			recorder := httptest.NewRecorder()
+			go func() {
+				handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://"+test.secondHost+"/", nil))
+				close(secondDone)
+			}()
+
+			select {
+			case <-next.started:
+				close(next.release)
+			case <-secondDone:
+				close(next.release)
+			}
+
+			<-secondDone
+			<-firstDone
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+func TestAdaptiveInFlightReq_limitGauge(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(time.Millisecond)
+	})
+
+	gauge := &testGauge{values: make(map[string]float64)}
+	handler, err := New(context.Background(), next, dynamic.AdaptiveInFlightReq{InitialLimit: 10, MaxLimit: 10}, gauge, "foo")
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://bar.com/", nil))
+
+	// The mean of the limits of the sources is reported in a single series per middleware.
+	assert.Equal(t, map[string]float64{"middleware,foo": 10}, gauge.values)
+}
+
+func TestNew_invalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.AdaptiveInFlightReq
+	}{
+		{
+			desc:   "unknown algorithm",
+			config: dynamic.AdaptiveInFlightReq{Algorithm: "foo"},
+		},
+		{
+			desc:   "max limit lower than min limit",
+			config: dynamic.AdaptiveInFlightReq{MinLimit: 10, MaxLimit: 5},
+		},
+		{
+			desc:   "initial limit above max limit",
+			config: dynamic.AdaptiveInFlightReq{InitialLimit: 20, MaxLimit: 10},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, nil, "foo")
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package adaptiveinflightreq
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// The long-term latency of the gradient algorithm is an exponential moving average over gradientWindow requests,
+	// averaged over the first gradientWarmup requests.
+	gradientWindow    = 600
+	gradientWarmup    = 10
+	gradientTolerance = 1.5
+	gradientSmoothing = 0.2
+
+	// The no-load latency of the vegas algorithm is measured again every vegasProbeMultiplier*limit requests,
+	// to follow the latency changes of the backends.
+	vegasProbeMultiplier = 30
+)
+
+// algorithm adjusts the limit of a limiter from the requests served.
+type algorithm interface {
+	// update returns the new limit, from the current limit and the latency of a request,
+	// which was sent with inFlight requests in flight, and which was dropped by the backend if dropped is true.
+	update(limit float64, rtt time.Duration, inFlight int64, dropped bool) float64
+}
+
+// gradient compares the latency of each request to the long-term latency,
+// and lowers the limit when the latency grows, raising it otherwise.
+// It is the gradient2 algorithm of the Netflix concurrency-limits library.
+type gradient struct {
+	longRTT float64
+	samples int
+}
+
+func (g *gradient) update(limit float64, rtt time.Duration, inFlight int64, _ bool) float64 {
+	shortRTT := float64(rtt)
+	if shortRTT <= 0 {
+		return limit
+	}
+
+	if g.samples < gradientWarmup {
+		g.samples++
+		g.longRTT += (shortRTT - g.longRTT) / float64(g.samples)
+	} else {
+		g.longRTT += (shortRTT - g.longRTT) * 2 / (gradientWindow + 1)
+	}
+
+	// The long-term latency recovers faster after a latency spike.
+	if g.longRTT/shortRTT > 2 {
+		g.longRTT *= 0.95
+	}
+
+	// The limit is not reached, so the latency tells nothing about it.
+	if float64(inFlight) < limit/2 {
+		return limit
+	}
+
+	grad := math.Max(0.5, math.Min(1, gradientTolerance*g.longRTT/shortRTT))
+	newLimit := limit*grad + math.Sqrt(limit)
+
+	return limit*(1-gradientSmoothing) + newLimit*gradientSmoothing
+}
+
+// vegas estimates the number of requests queued by the backends from the latency of each request,
+// compared to the latency without load, and keeps that number between two thresholds.
+// It is the vegas algorithm of the Netflix concurrency-limits library.
+type vegas struct {
+	rttNoLoad time.Duration
+	samples   int
+}
+
+func (v *vegas) update(limit float64, rtt time.Duration, inFlight int64, dropped bool) float64 {
+	if rtt <= 0 {
+		return limit
+	}
+
+	v.samples++
+	if v.samples >= vegasProbeMultiplier*int(limit) {
+		v.samples = 0
+		v.rttNoLoad = rtt
+		return limit
+	}
+
+	if v.rttNoLoad == 0 || rtt < v.rttNoLoad {
+		v.rttNoLoad = rtt
+		return limit
+	}
+
+	step := math.Max(1, math.Log10(limit))
+
+	if dropped {
+		return limit - step
+	}
+
+	// The limit is not reached, so the latency tells nothing about it.
+	if float64(inFlight)*2 < limit {
+		return limit
+	}
+
+	queueSize := math.Ceil(limit * (1 - float64(v.rttNoLoad)/float64(rtt)))
+
+	switch alpha, beta := 3*step, 6*step; {
+	case queueSize <= step:
+		return limit + beta
+	case queueSize < alpha:
+		return limit + step
+	case queueSize > beta:
+		return limit - step
+	default:
+		return limit
+	}
+}
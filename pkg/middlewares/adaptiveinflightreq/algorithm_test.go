@@ -0,0 +1,42 @@
+package adaptiveinflightreq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradient(t *testing.T) {
+	g := &gradient{}
+
+	limit := 20.0
+	for i := 0; i < 100; i++ {
+		limit = g.update(limit, 10*time.Millisecond, int64(limit), false)
+	}
+	assert.Greater(t, limit, 20.0, "the limit should grow while the latency is stable")
+
+	grown := limit
+	for i := 0; i < 20; i++ {
+		limit = g.update(limit, 100*time.Millisecond, int64(limit), false)
+	}
+	assert.Less(t, limit, grown, "the limit should shrink when the latency grows")
+
+	assert.Equal(t, 20.0, g.update(20, time.Second, 2, false), "the limit should not change when it is not reached")
+}
+
+func TestVegas(t *testing.T) {
+	v := &vegas{}
+
+	limit := v.update(20, 10*time.Millisecond, 20, false)
+	assert.Equal(t, 20.0, limit, "the first request measures the latency without load")
+
+	limit = v.update(limit, 10*time.Millisecond, 20, false)
+	assert.Greater(t, limit, 20.0, "the limit should grow while nothing is queued")
+
+	grown := limit
+	limit = v.update(limit, 50*time.Millisecond, int64(limit), false)
+	assert.Less(t, limit, grown, "the limit should shrink when requests are queued")
+
+	assert.Less(t, v.update(limit, 10*time.Millisecond, 1, true), limit, "the limit should shrink when a request is dropped")
+}
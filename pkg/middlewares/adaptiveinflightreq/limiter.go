@@ -0,0 +1,117 @@
+package adaptiveinflightreq
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// limiter limits the number of requests in flight for a source.
+type limiter struct {
+	mu        sync.Mutex
+	algorithm algorithm
+	limit     float64
+	minLimit  float64
+	maxLimit  float64
+	inFlight  int64
+	waiters   *list.List // of chan struct{}, closed when a slot is handed over to the waiting request.
+	lastUsed  time.Time
+
+	// onUpdate is called with the previous and the new limit, every time it changes.
+	onUpdate func(previous, limit float64)
+}
+
+// acquire takes a slot for a request, waiting for at most timeout for one to be released if there are none left,
+// unless maxQueue requests are already waiting.
+// It returns the number of requests in flight, including this one, and whether a slot was taken.
+func (l *limiter) acquire(ctx context.Context, maxQueue int64, timeout time.Duration) (int64, bool) {
+	l.mu.Lock()
+	l.lastUsed = time.Now()
+
+	if l.inFlight < l.capacity() {
+		l.inFlight++
+		inFlight := l.inFlight
+		l.mu.Unlock()
+		return inFlight, true
+	}
+
+	if int64(l.waiters.Len()) >= maxQueue {
+		l.mu.Unlock()
+		return 0, false
+	}
+
+	ready := make(chan struct{})
+	elem := l.waiters.PushBack(ready)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ready:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-ready:
+		// The slot may have been handed over while the wait was over.
+		return l.inFlight, true
+	default:
+		l.waiters.Remove(elem)
+		return 0, false
+	}
+}
+
+// release frees the slot of a request, and updates the limit from its latency,
+// unless the latency is meaningless (e.g. the client went away).
+func (l *limiter) release(rtt time.Duration, inFlight int64, dropped, sample bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+
+	if sample {
+		limit := math.Max(l.minLimit, math.Min(l.maxLimit, l.algorithm.update(l.limit, rtt, inFlight, dropped)))
+		if limit != l.limit {
+			previous := l.limit
+			l.limit = limit
+			if l.onUpdate != nil {
+				l.onUpdate(previous, limit)
+			}
+		}
+	}
+
+	// Hands the free slots over to the waiting requests.
+	for l.waiters.Len() > 0 && l.inFlight < l.capacity() {
+		ready := l.waiters.Remove(l.waiters.Front()).(chan struct{})
+		l.inFlight++
+		close(ready)
+	}
+}
+
+// idle reports whether the limiter has not been used since the given time, and has no request in flight.
+func (l *limiter) idle(since time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inFlight == 0 && l.waiters.Len() == 0 && l.lastUsed.Before(since)
+}
+
+// capacityLocked returns the maximum number of requests in flight.
+func (l *limiter) capacityLocked() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.capacity()
+}
+
+// capacity returns the maximum number of requests in flight, which must be called with the lock held.
+func (l *limiter) capacity() int64 {
+	return int64(l.limit)
+}
@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/middlewares/ratelimiter"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	apiKeyTypeName = "APIKey"
+
+	defaultAPIKeyHeader      = "X-Api-Key"
+	defaultAPIKeyHeaderField = "X-Api-Consumer"
+
+	// apiKeysFileCheckInterval is the minimum time between two checks of the keys file for changes.
+	apiKeysFileCheckInterval = time.Second
+)
+
+// apiKeyConsumer is the consumer a key belongs to.
+type apiKeyConsumer struct {
+	name string
+	tier string
+}
+
+type apiKey struct {
+	next        http.Handler
+	name        string
+	header      string
+	query       string
+	headerField string
+	removeKey   bool
+
+	// inline holds the consumers of the keys of the configuration, by key hash.
+	inline map[string]apiKeyConsumer
+	file   *apiKeysFile
+	// tiers holds the rate limiters of the tiers, which forward the requests to next.
+	tiers map[string]http.Handler
+}
+
+// NewAPIKey creates an apiKey middleware.
+func NewAPIKey(ctx context.Context, next http.Handler, config dynamic.APIKey, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, apiKeyTypeName)).Debug("Creating middleware")
+
+	if len(config.Keys) == 0 && config.KeysFile == "" {
+		return nil, fmt.Errorf("keys or keysFile must be set")
+	}
+
+	ak := &apiKey{
+		next:        next,
+		name:        name,
+		header:      config.Header,
+		query:       config.Query,
+		headerField: config.HeaderField,
+		removeKey:   config.RemoveKey,
+		tiers:       make(map[string]http.Handler),
+	}
+
+	if ak.header == "" {
+		ak.header = defaultAPIKeyHeader
+	}
+
+	if ak.headerField == "" {
+		ak.headerField = defaultAPIKeyHeaderField
+	}
+
+	for tierName, tier := range config.Tiers {
+		if tier == nil {
+			continue
+		}
+
+		// The tiers rely on the rate limiter, with a bucket for each consumer.
+		limiter, err := ratelimiter.New(ctx, next, dynamic.RateLimit{
+			Average:         tier.Average,
+			Period:          tier.Period,
+			Burst:           tier.Burst,
+			SourceCriterion: &dynamic.SourceCriterion{RequestHeaderName: ak.headerField},
+		}, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tier %s: %w", tierName, err)
+		}
+
+		ak.tiers[tierName] = limiter
+	}
+
+	var err error
+	ak.inline, err = ak.parseKeys(config.Keys)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.KeysFile != "" {
+		ak.file = &apiKeysFile{path: config.KeysFile, parse: ak.parseKeys}
+		if err := ak.file.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ak, nil
+}
+
+func (a *apiKey) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *apiKey) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), a.name, apiKeyTypeName))
+
+	key := req.Header.Get(a.header)
+	if key == "" && a.query != "" {
+		key = req.URL.Query().Get(a.query)
+	}
+
+	consumer, ok := a.lookup(req.Context(), key)
+	if !ok {
+		logger.Debug("Authentication failed")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = consumer.name
+	}
+
+	// The header is always overwritten, as the rate limiters rely on it.
+	req.Header.Set(a.headerField, consumer.name)
+
+	if a.removeKey {
+		logger.Debug("Removing API key")
+		req.Header.Del(a.header)
+
+		if a.query != "" {
+			query := req.URL.Query()
+			if _, exists := query[a.query]; exists {
+				query.Del(a.query)
+				req.URL.RawQuery = query.Encode()
+				req.RequestURI = req.URL.RequestURI()
+			}
+		}
+	}
+
+	if limiter, ok := a.tiers[consumer.tier]; ok {
+		limiter.ServeHTTP(rw, req)
+		return
+	}
+
+	a.next.ServeHTTP(rw, req)
+}
+
+// lookup returns the consumer of the given key.
+func (a *apiKey) lookup(ctx context.Context, key string) (apiKeyConsumer, bool) {
+	if key == "" {
+		return apiKeyConsumer{}, false
+	}
+
+	hash := hashAPIKey(key)
+
+	if consumer, ok := a.inline[hash]; ok {
+		return consumer, true
+	}
+
+	if a.file == nil {
+		return apiKeyConsumer{}, false
+	}
+
+	consumer, ok := a.file.get(ctx)[hash]
+	return consumer, ok
+}
+
+// parseKeys parses consumer:hash[:tier] entries into the consumers of the keys, by key hash.
+func (a *apiKey) parseKeys(entries []string) (map[string]apiKeyConsumer, error) {
+	keys := make(map[string]apiKeyConsumer)
+	for _, entry := range entries {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, fmt.Errorf("error parsing API key: %s", entry)
+		}
+
+		hash := strings.ToLower(parts[1])
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("invalid SHA-256 hash for the API key of %s", parts[0])
+		}
+
+		consumer := apiKeyConsumer{name: parts[0]}
+		if len(parts) == 3 {
+			if _, ok := a.tiers[parts[2]]; !ok {
+				return nil, fmt.Errorf("unknown tier %s for the API key of %s", parts[2], parts[0])
+			}
+			consumer.tier = parts[2]
+		}
+
+		keys[hash] = consumer
+	}
+
+	return keys, nil
+}
+
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// apiKeysFile holds the keys of a file, which is reloaded when it changes.
+type apiKeysFile struct {
+	path  string
+	parse func(entries []string) (map[string]apiKeyConsumer, error)
+
+	mu        sync.RWMutex
+	keys      map[string]apiKeyConsumer
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+// get returns the keys of the file, after reloading it if it has changed since the last check.
+func (f *apiKeysFile) get(ctx context.Context) map[string]apiKeyConsumer {
+	f.mu.RLock()
+	keys := f.keys
+	recentCheck := time.Since(f.checkedAt) < apiKeysFileCheckInterval
+	f.mu.RUnlock()
+
+	if recentCheck {
+		return keys
+	}
+
+	if err := f.load(); err != nil {
+		// The previous keys are kept until the file is valid again.
+		log.FromContext(ctx).Errorf("Unable to reload API keys file %s: %v", f.path, err)
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.keys
+}
+
+// load reads the file again if it has changed.
+func (f *apiKeysFile) load() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.checkedAt = time.Now()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+
+	if f.keys != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return nil
+	}
+
+	lines, err := getLinesFromFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	keys, err := f.parse(lines)
+	if err != nil {
+		return err
+	}
+
+	f.keys = keys
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return nil
+}
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIKey_invalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.APIKey
+	}{
+		{
+			desc:   "no keys",
+			config: dynamic.APIKey{},
+		},
+		{
+			desc:   "missing hash",
+			config: dynamic.APIKey{Keys: []string{"foo"}},
+		},
+		{
+			desc:   "invalid hash",
+			config: dynamic.APIKey{Keys: []string{"foo:bar"}},
+		},
+		{
+			desc:   "unknown tier",
+			config: dynamic.APIKey{Keys: []string{"foo:" + hashAPIKey("secret") + ":gold"}},
+		},
+		{
+			desc:   "missing keys file",
+			config: dynamic.APIKey{KeysFile: filepath.Join(t.TempDir(), "keys")},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewAPIKey(context.Background(), http.NotFoundHandler(), test.config, "apiKey")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Consumer", req.Header.Get("X-Api-Consumer"))
+		rw.Header().Set("X-Key", req.Header.Get("X-Api-Key"))
+		rw.Header().Set("X-Query", req.URL.RawQuery)
+	})
+
+	testCases := []struct {
+		desc             string
+		config           dynamic.APIKey
+		header           string
+		url              string
+		expectedStatus   int
+		expectedConsumer string
+		expectedKey      string
+		expectedQuery    string
+	}{
+		{
+			desc:           "no key",
+			config:         dynamic.APIKey{Keys: []string{"foo:" + hashAPIKey("secret")}},
+			url:            "http://foo.com/",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "unknown key",
+			config:         dynamic.APIKey{Keys: []string{"foo:" + hashAPIKey("secret")}},
+			header:         "other",
+			url:            "http://foo.com/",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:             "key in header",
+			config:           dynamic.APIKey{Keys: []string{"foo:" + hashAPIKey("secret")}},
+			header:           "secret",
+			url:              "http://foo.com/",
+			expectedStatus:   http.StatusOK,
+			expectedConsumer: "foo",
+			expectedKey:      "secret",
+		},
+		{
+			desc:           "key in query parameter not allowed",
+			config:         dynamic.APIKey{Keys: []string{"foo:" + hashAPIKey("secret")}},
+			url:            "http://foo.com/?api_key=secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc: "key in query parameter",
+			config: dynamic.APIKey{
+				Keys:  []string{"foo:" + hashAPIKey("secret")},
+				Query: "api_key",
+			},
+			url:              "http://foo.com/?api_key=secret&bar=baz",
+			expectedStatus:   http.StatusOK,
+			expectedConsumer: "foo",
+			expectedQuery:    "api_key=secret&bar=baz",
+		},
+		{
+			desc: "key removed",
+			config: dynamic.APIKey{
+				Keys:      []string{"foo:" + hashAPIKey("secret")},
+				Query:     "api_key",
+				RemoveKey: true,
+			},
+			url:              "http://foo.com/?api_key=secret&bar=baz",
+			expectedStatus:   http.StatusOK,
+			expectedConsumer: "foo",
+			expectedQuery:    "bar=baz",
+		},
+		{
+			desc:             "upper case hash",
+			config:           dynamic.APIKey{Keys: []string{"foo:" + strings.ToUpper(hashAPIKey("secret"))}},
+			header:           "secret",
+			url:              "http://foo.com/",
+			expectedStatus:   http.StatusOK,
+			expectedConsumer: "foo",
+			expectedKey:      "secret",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := NewAPIKey(context.Background(), next, test.config, "apiKey")
+			require.NoError(t, err)
+
+			req := testhelpers.MustNewRequest(http.MethodGet, test.url, nil)
+			if test.header != "" {
+				req.Header.Set("X-Api-Key", test.header)
+			}
+			// The consumer header sent by the client is never trusted.
+			req.Header.Set("X-Api-Consumer", "bar")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedConsumer, recorder.Header().Get("X-Consumer"))
+			assert.Equal(t, test.expectedKey, recorder.Header().Get("X-Key"))
+			assert.Equal(t, test.expectedQuery, recorder.Header().Get("X-Query"))
+		})
+	}
+}
+
+func TestAPIKey_keysFile(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "keys")
+	err := ioutil.WriteFile(keysFile, []byte("# consumers\nfoo:"+hashAPIKey("foo-secret")+"\n"), 0600)
+	require.NoError(t, err)
+
+	handler, err := NewAPIKey(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.APIKey{
+		Keys:     []string{"bar:" + hashAPIKey("bar-secret")},
+		KeysFile: keysFile,
+	}, "apiKey")
+	require.NoError(t, err)
+
+	serve := func(key string) int {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://foo.com/", nil)
+		req.Header.Set("X-Api-Key", key)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("foo-secret"))
+	assert.Equal(t, http.StatusOK, serve("bar-secret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("baz-secret"))
+
+	err = ioutil.WriteFile(keysFile, []byte("baz:"+hashAPIKey("baz-secret")+"\n"), 0600)
+	require.NoError(t, err)
+	forceAPIKeysFileCheck(t, handler)
+
+	assert.Equal(t, http.StatusUnauthorized, serve("foo-secret"))
+	assert.Equal(t, http.StatusOK, serve("bar-secret"))
+	assert.Equal(t, http.StatusOK, serve("baz-secret"))
+
+	// The previous keys are kept while the file is invalid.
+	err = ioutil.WriteFile(keysFile, []byte("invalid\n"), 0600)
+	require.NoError(t, err)
+	forceAPIKeysFileCheck(t, handler)
+
+	assert.Equal(t, http.StatusOK, serve("baz-secret"))
+}
+
+func TestAPIKey_tiers(t *testing.T) {
+	handler, err := NewAPIKey(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.APIKey{
+		Keys: []string{
+			"foo:" + hashAPIKey("foo-secret") + ":bronze",
+			"foo:" + hashAPIKey("foo-other-secret") + ":bronze",
+			"bar:" + hashAPIKey("bar-secret") + ":bronze",
+			"baz:" + hashAPIKey("baz-secret"),
+		},
+		Tiers: map[string]*dynamic.APIKeyTier{
+			"bronze": {Average: 1, Period: types.Duration(time.Hour), Burst: 1},
+		},
+	}, "apiKey")
+	require.NoError(t, err)
+
+	serve := func(key string) int {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://foo.com/", nil)
+		req.Header.Set("X-Api-Key", key)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("foo-secret"))
+	// The keys of a consumer share its bucket.
+	assert.Equal(t, http.StatusTooManyRequests, serve("foo-other-secret"))
+	assert.Equal(t, http.StatusOK, serve("bar-secret"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("bar-secret"))
+
+	// The keys without a tier are not rate limited.
+	assert.Equal(t, http.StatusOK, serve("baz-secret"))
+	assert.Equal(t, http.StatusOK, serve("baz-secret"))
+}
+
+func forceAPIKeysFileCheck(t *testing.T, handler http.Handler) {
+	t.Helper()
+
+	ak, ok := handler.(*apiKey)
+	require.True(t, ok)
+
+	ak.file.mu.Lock()
+	ak.file.checkedAt = time.Time{}
+	// The modification time may not change on file systems with a coarse resolution.
+	ak.file.modTime = time.Time{}
+	ak.file.mu.Unlock()
+}
@@ -1,8 +1,13 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -22,11 +27,28 @@ const (
 	xForwardedURI     = "X-Forwarded-Uri"
 	xForwardedMethod  = "X-Forwarded-Method"
 	forwardedTypeName = "ForwardedAuthType"
+
+	defaultForwardAuthMaxBodySize     = 1024 * 1024
+	defaultForwardAuthCacheTTL        = time.Minute
+	defaultForwardAuthCacheMaxEntries = 10000
+)
+
+var (
+	defaultForwardAuthCredentialHeaders = []string{"Authorization", "Cookie"}
+
+	errBodyTooLarge = errors.New("request body too large")
 )
 
 type forwardAuth struct {
 	address             string
 	authResponseHeaders []string
+	authRequestHeaders  []string
+	method              string
+	forwardBody         bool
+	maxBodySize         int64
+	cache               *forwardAuthCache
+	credentialHeaders   []string
+	keyHeaders          []string
 	next                http.Handler
 	name                string
 	client              http.Client
@@ -40,11 +62,43 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 	fa := &forwardAuth{
 		address:             config.Address,
 		authResponseHeaders: config.AuthResponseHeaders,
+		authRequestHeaders:  config.AuthRequestHeaders,
+		method:              config.Method,
+		forwardBody:         config.ForwardBody,
+		maxBodySize:         config.MaxBodySize,
 		next:                next,
 		name:                name,
 		trustForwardHeader:  config.TrustForwardHeader,
 	}
 
+	if fa.method == "" {
+		fa.method = http.MethodGet
+	}
+
+	if fa.maxBodySize <= 0 {
+		fa.maxBodySize = defaultForwardAuthMaxBodySize
+	}
+
+	if config.Cache != nil {
+		ttl := time.Duration(config.Cache.TTL)
+		if ttl <= 0 {
+			ttl = defaultForwardAuthCacheTTL
+		}
+
+		maxEntries := config.Cache.MaxEntries
+		if maxEntries <= 0 {
+			maxEntries = defaultForwardAuthCacheMaxEntries
+		}
+
+		fa.cache = newForwardAuthCache(ttl, maxEntries)
+
+		fa.credentialHeaders = config.Cache.CredentialHeaders
+		if len(fa.credentialHeaders) == 0 {
+			fa.credentialHeaders = defaultForwardAuthCredentialHeaders
+		}
+		fa.keyHeaders = config.Cache.KeyHeaders
+	}
+
 	// Ensure our request client does not follow redirects
 	fa.client = http.Client{
 		CheckRedirect: func(r *http.Request, via []*http.Request) error {
@@ -74,10 +128,36 @@ func (fa *forwardAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), fa.name, forwardedTypeName))
 
-	forwardReq, err := http.NewRequest(http.MethodGet, fa.address, nil)
-	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
+	var body []byte
+	if fa.forwardBody {
+		var err error
+		body, err = readBody(req, fa.maxBodySize)
+		if err != nil {
+			logMessage := fmt.Sprintf("Error reading request body. Cause: %s", err)
+			logger.Debug(logMessage)
+			tracing.SetErrorWithEvent(req, logMessage)
+
+			if errors.Is(err, errBodyTooLarge) {
+				rw.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	cacheKey := fa.cacheKey(req, body)
+	if cacheKey != "" {
+		if decision, ok := fa.cache.get(cacheKey); ok {
+			logger.Debug("Using the cached decision of the authentication server")
+			fa.apply(rw, req, decision)
+			return
+		}
+	}
+
+	decision, err := fa.decide(req, body)
 	if err != nil {
-		logMessage := fmt.Sprintf("Error calling %s. Cause %s", fa.address, err)
+		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, err)
 		logger.Debug(logMessage)
 		tracing.SetErrorWithEvent(req, logMessage)
 
@@ -85,63 +165,93 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if !decision.granted() {
+		logger.Debugf("Remote error %s. StatusCode: %d", fa.address, decision.statusCode)
+	}
+
+	if cacheKey != "" && decision.cacheable() {
+		fa.cache.set(cacheKey, decision)
+	}
+
+	fa.apply(rw, req, decision)
+}
+
+// decide asks the authentication server whether the access is granted.
+func (fa *forwardAuth) decide(req *http.Request, body []byte) (*forwardAuthDecision, error) {
+	var bodyReader io.Reader
+	if len(body) > 0 {
+		bodyReader = bytes.NewReader(body)
+	}
+
+	forwardReq, err := http.NewRequest(fa.method, fa.address, bodyReader)
+	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure tracing headers are in the request before we copy the headers to the
 	// forwardReq.
 	tracing.InjectRequestHeaders(req)
 
-	writeHeader(req, forwardReq, fa.trustForwardHeader)
+	writeHeader(req, forwardReq, fa.trustForwardHeader, fa.authRequestHeaders)
 
-	forwardResponse, forwardErr := fa.client.Do(forwardReq)
-	if forwardErr != nil {
-		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, forwardErr)
-		logger.Debug(logMessage)
-		tracing.SetErrorWithEvent(req, logMessage)
+	forwardResponse, err := fa.client.Do(forwardReq)
+	if err != nil {
+		return nil, err
+	}
+	defer forwardResponse.Body.Close()
 
-		rw.WriteHeader(http.StatusInternalServerError)
-		return
+	forwardBody, err := ioutil.ReadAll(forwardResponse.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
 	}
 
-	body, readError := ioutil.ReadAll(forwardResponse.Body)
-	if readError != nil {
-		logMessage := fmt.Sprintf("Error reading body %s. Cause: %s", fa.address, readError)
-		logger.Debug(logMessage)
-		tracing.SetErrorWithEvent(req, logMessage)
+	decision := &forwardAuthDecision{
+		statusCode: forwardResponse.StatusCode,
+		header:     make(http.Header),
+	}
 
-		rw.WriteHeader(http.StatusInternalServerError)
-		return
+	if decision.granted() {
+		for _, headerName := range fa.authResponseHeaders {
+			headerKey := http.CanonicalHeaderKey(headerName)
+			if len(forwardResponse.Header[headerKey]) > 0 {
+				decision.header[headerKey] = append([]string(nil), forwardResponse.Header[headerKey]...)
+			}
+		}
+		return decision, nil
 	}
-	defer forwardResponse.Body.Close()
 
 	// Pass the forward response's body and selected headers if it
 	// didn't return a response within the range of [200, 300).
-	if forwardResponse.StatusCode < http.StatusOK || forwardResponse.StatusCode >= http.StatusMultipleChoices {
-		logger.Debugf("Remote error %s. StatusCode: %d", fa.address, forwardResponse.StatusCode)
+	utils.CopyHeaders(decision.header, forwardResponse.Header)
+	utils.RemoveHeaders(decision.header, forward.HopHeaders...)
 
-		utils.CopyHeaders(rw.Header(), forwardResponse.Header)
-		utils.RemoveHeaders(rw.Header(), forward.HopHeaders...)
+	// Grab the location header, if any.
+	redirectURL, err := forwardResponse.Location()
 
-		// Grab the location header, if any.
-		redirectURL, err := forwardResponse.Location()
+	if err != nil {
+		if err != http.ErrNoLocation {
+			return nil, fmt.Errorf("error reading response location header: %w", err)
+		}
+	} else if redirectURL.String() != "" {
+		// Set the location in our response if one was sent back.
+		decision.header.Set("Location", redirectURL.String())
+	}
 
-		if err != nil {
-			if err != http.ErrNoLocation {
-				logMessage := fmt.Sprintf("Error reading response location header %s. Cause: %s", fa.address, err)
-				logger.Debug(logMessage)
-				tracing.SetErrorWithEvent(req, logMessage)
+	decision.body = forwardBody
+	return decision, nil
+}
 
-				rw.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-		} else if redirectURL.String() != "" {
-			// Set the location in our response if one was sent back.
-			rw.Header().Set("Location", redirectURL.String())
-		}
+// apply forwards the request if the access is granted, and writes the response of the authentication server otherwise.
+func (fa *forwardAuth) apply(rw http.ResponseWriter, req *http.Request, decision *forwardAuthDecision) {
+	if !decision.granted() {
+		utils.CopyHeaders(rw.Header(), decision.header)
 
-		tracing.LogResponseCode(tracing.GetSpan(req), forwardResponse.StatusCode)
-		rw.WriteHeader(forwardResponse.StatusCode)
+		tracing.LogResponseCode(tracing.GetSpan(req), decision.statusCode)
+		rw.WriteHeader(decision.statusCode)
 
-		if _, err = rw.Write(body); err != nil {
-			logger.Error(err)
+		if _, err := rw.Write(decision.body); err != nil {
+			log.FromContext(middlewares.GetLoggerCtx(req.Context(), fa.name, forwardedTypeName)).Error(err)
 		}
 		return
 	}
@@ -149,8 +259,8 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	for _, headerName := range fa.authResponseHeaders {
 		headerKey := http.CanonicalHeaderKey(headerName)
 		req.Header.Del(headerKey)
-		if len(forwardResponse.Header[headerKey]) > 0 {
-			req.Header[headerKey] = append([]string(nil), forwardResponse.Header[headerKey]...)
+		if len(decision.header[headerKey]) > 0 {
+			req.Header[headerKey] = append([]string(nil), decision.header[headerKey]...)
 		}
 	}
 
@@ -158,16 +268,115 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	fa.next.ServeHTTP(rw, req)
 }
 
-func writeHeader(req *http.Request, forwardReq *http.Request, trustForwardHeader bool) {
+// cacheKey returns the key of the decision for the request in the cache,
+// or an empty string if the decision cannot be cached.
+func (fa *forwardAuth) cacheKey(req *http.Request, body []byte) string {
+	// The decisions for the forwarded bodies, such as signed payloads, are specific to each request.
+	if fa.cache == nil || len(body) > 0 {
+		return ""
+	}
+
+	// The decisions may depend on the client address, and on the requested method and URL,
+	// as forwarded to the authentication server.
+	hash := sha256.New()
+	for _, value := range []string{
+		req.Host,
+		forwardedFor(req, fa.trustForwardHeader),
+		forwardedProto(req, fa.trustForwardHeader),
+		fa.forwardedValue(req, forward.XForwardedPort, ""),
+		fa.forwardedValue(req, forward.XForwardedHost, req.Host),
+		fa.forwardedValue(req, xForwardedMethod, req.Method),
+		fa.forwardedValue(req, xForwardedURI, req.URL.RequestURI()),
+	} {
+		_, _ = io.WriteString(hash, value+"\x00")
+	}
+
+	for _, headerName := range fa.keyHeaders {
+		_, _ = io.WriteString(hash, strings.Join(req.Header.Values(headerName), "\x00")+"\x00")
+	}
+
+	var credentials bool
+	for _, headerName := range fa.credentialHeaders {
+		values := req.Header.Values(headerName)
+		credentials = credentials || len(values) > 0
+
+		_, _ = io.WriteString(hash, strings.Join(values, "\x00")+"\x00")
+	}
+
+	// The decisions for the anonymous requests may depend on the requested URL, as for the redirections to a login page.
+	if !credentials {
+		return ""
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// forwardedValue returns the value of the given forwarded header sent to the authentication server,
+// which is the one of the request if it is trusted, or else the given value.
+func (fa *forwardAuth) forwardedValue(req *http.Request, headerName, value string) string {
+	if forwarded := req.Header.Get(headerName); forwarded != "" && fa.trustForwardHeader {
+		return forwarded
+	}
+	return value
+}
+
+// forwardedFor returns the X-Forwarded-For header sent to the authentication server,
+// which ends with the address of the client, or an empty string if it is unknown.
+func forwardedFor(req *http.Request, trustForwardHeader bool) string {
+	clientIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+
+	if trustForwardHeader {
+		if prior, ok := req.Header[forward.XForwardedFor]; ok {
+			clientIP = strings.Join(prior, ", ") + ", " + clientIP
+		}
+	}
+	return clientIP
+}
+
+// forwardedProto returns the X-Forwarded-Proto header sent to the authentication server.
+func forwardedProto(req *http.Request, trustForwardHeader bool) string {
+	if xfp := req.Header.Get(forward.XForwardedProto); xfp != "" && trustForwardHeader {
+		return xfp
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// readBody reads the request body up to the given size, and restores it for the next handler.
+func readBody(req *http.Request, maxSize int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if req.ContentLength > maxSize {
+		return nil, errBodyTooLarge
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > maxSize {
+		return nil, errBodyTooLarge
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func writeHeader(req *http.Request, forwardReq *http.Request, trustForwardHeader bool, allowedHeaders []string) {
 	utils.CopyHeaders(forwardReq.Header, req.Header)
 	utils.RemoveHeaders(forwardReq.Header, forward.HopHeaders...)
 
-	if clientIP, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
-		if trustForwardHeader {
-			if prior, ok := req.Header[forward.XForwardedFor]; ok {
-				clientIP = strings.Join(prior, ", ") + ", " + clientIP
-			}
-		}
+	forwardReq.Header = filterHeaders(forwardReq.Header, allowedHeaders)
+
+	if clientIP := forwardedFor(req, trustForwardHeader); clientIP != "" {
 		forwardReq.Header.Set(forward.XForwardedFor, clientIP)
 	}
 
@@ -181,15 +390,7 @@ func writeHeader(req *http.Request, forwardReq *http.Request, trustForwardHeader
 		forwardReq.Header.Del(xForwardedMethod)
 	}
 
-	xfp := req.Header.Get(forward.XForwardedProto)
-	switch {
-	case xfp != "" && trustForwardHeader:
-		forwardReq.Header.Set(forward.XForwardedProto, xfp)
-	case req.TLS != nil:
-		forwardReq.Header.Set(forward.XForwardedProto, "https")
-	default:
-		forwardReq.Header.Set(forward.XForwardedProto, "http")
-	}
+	forwardReq.Header.Set(forward.XForwardedProto, forwardedProto(req, trustForwardHeader))
 
 	if xfp := req.Header.Get(forward.XForwardedPort); xfp != "" && trustForwardHeader {
 		forwardReq.Header.Set(forward.XForwardedPort, xfp)
@@ -215,3 +416,19 @@ func writeHeader(req *http.Request, forwardReq *http.Request, trustForwardHeader
 		forwardReq.Header.Del(xForwardedURI)
 	}
 }
+
+// filterHeaders returns the given headers restricted to the allowed ones, or all of them if none is allowed explicitly.
+func filterHeaders(headers http.Header, allowedHeaders []string) http.Header {
+	if len(allowedHeaders) == 0 {
+		return headers
+	}
+
+	filtered := make(http.Header)
+	for _, headerName := range allowedHeaders {
+		headerKey := http.CanonicalHeaderKey(headerName)
+		if values, ok := headers[headerKey]; ok {
+			filtered[headerKey] = values
+		}
+	}
+	return filtered
+}
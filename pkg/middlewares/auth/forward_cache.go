@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// forwardAuthDecision is a decision of the authentication server.
+type forwardAuthDecision struct {
+	// statusCode is the status code of the response of the authentication server.
+	statusCode int
+	// header holds the headers set on the request when the access is granted,
+	// or on the response otherwise.
+	header http.Header
+	// body is the body of the response when the access is denied.
+	body []byte
+}
+
+func (d *forwardAuthDecision) granted() bool {
+	return d.statusCode >= http.StatusOK && d.statusCode < http.StatusMultipleChoices
+}
+
+// cacheable returns whether the decision only depends on the credentials, and can be cached:
+// the redirections, such as the ones to a login page, may depend on the requested URL,
+// and the other errors may be temporary.
+func (d *forwardAuthDecision) cacheable() bool {
+	return d.granted() || d.statusCode == http.StatusUnauthorized || d.statusCode == http.StatusForbidden
+}
+
+// forwardAuthCache is a bounded cache of the decisions of the authentication server,
+// which evicts the least recently used decisions when it is full.
+type forwardAuthCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	list    *list.List
+	entries map[string]*list.Element
+}
+
+type forwardAuthCacheEntry struct {
+	key       string
+	decision  *forwardAuthDecision
+	expiresAt time.Time
+}
+
+func newForwardAuthCache(ttl time.Duration, maxEntries int) *forwardAuthCache {
+	return &forwardAuthCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		list:       list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the decision cached for the given key, if it has not expired.
+func (c *forwardAuthCache) get(key string) (*forwardAuthDecision, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elt, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elt.Value.(*forwardAuthCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.list.Remove(elt)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.list.MoveToFront(elt)
+	return entry.decision, true
+}
+
+// set caches the decision for the given key, evicting the least recently used decision if the cache is full.
+func (c *forwardAuthCache) set(key string, decision *forwardAuthDecision) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elt, ok := c.entries[key]; ok {
+		c.list.Remove(elt)
+		delete(c.entries, key)
+	}
+
+	c.entries[key] = c.list.PushFront(&forwardAuthCacheEntry{
+		key:       key,
+		decision:  decision,
+		expiresAt: time.Now().Add(c.ttl),
+	})
+
+	for c.list.Len() > c.maxEntries {
+		oldest := c.list.Back()
+		c.list.Remove(oldest)
+		delete(c.entries, oldest.Value.(*forwardAuthCacheEntry).key)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	tracingMiddleware "github.com/containous/traefik/v2/pkg/middlewares/tracing"
@@ -383,7 +386,7 @@ func Test_writeHeader(t *testing.T) {
 
 			forwardReq := testhelpers.MustNewRequest(http.MethodGet, "http://foo.bar/path?q=1", nil)
 
-			writeHeader(req, forwardReq, test.trustForwardHeader)
+			writeHeader(req, forwardReq, test.trustForwardHeader, nil)
 
 			actualHeaders := forwardReq.Header
 			expectedHeaders := test.expectedHeaders
@@ -440,3 +443,323 @@ type mockBackend struct {
 func (b *mockBackend) Setup(componentName string) (opentracing.Tracer, io.Closer, error) {
 	return b.Tracer, ioutil.NopCloser(nil), nil
 }
+
+func TestForwardAuthCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+
+		switch r.Header.Get("Authorization") {
+		case "Bearer valid":
+			w.Header().Set("X-Auth-User", "user@example.com")
+			fmt.Fprintln(w, "Success")
+		case "Bearer flaky":
+			http.Error(w, "Unavailable", http.StatusServiceUnavailable)
+		case "":
+			http.Redirect(w, r, "http://example.com/login?rd="+r.Header.Get(xForwardedURI), http.StatusFound)
+		default:
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("X-Auth-User"))
+	})
+
+	middleware, err := NewForward(context.Background(), next, dynamic.ForwardAuth{
+		Address:             server.URL,
+		AuthResponseHeaders: []string{"X-Auth-User"},
+		Cache:               &dynamic.ForwardAuthCache{KeyHeaders: []string{"X-Tenant"}},
+	}, "authTest")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		authorization  string
+		method         string
+		host           string
+		path           string
+		remoteAddr     string
+		tenant         string
+		expectedStatus int
+		expectedBody   string
+		expectedCalls  int32
+	}{
+		{
+			desc:           "granted",
+			authorization:  "Bearer valid",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "granted from the cache",
+			authorization:  "Bearer valid",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  0,
+		},
+		{
+			desc:           "granted on another path",
+			authorization:  "Bearer valid",
+			path:           "/other",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "granted for another method",
+			authorization:  "Bearer valid",
+			method:         http.MethodDelete,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "granted on another host",
+			authorization:  "Bearer valid",
+			host:           "other.localhost",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "granted for another client",
+			authorization:  "Bearer valid",
+			remoteAddr:     "10.0.0.2:1234",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "granted for another key header",
+			authorization:  "Bearer valid",
+			tenant:         "foo",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "granted for another key header from the cache",
+			authorization:  "Bearer valid",
+			tenant:         "foo",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "user@example.com",
+			expectedCalls:  0,
+		},
+		{
+			desc:           "denied",
+			authorization:  "Bearer invalid",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Forbidden\n",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "denied from the cache",
+			authorization:  "Bearer invalid",
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "Forbidden\n",
+			expectedCalls:  0,
+		},
+		{
+			desc:           "server errors are not cached",
+			authorization:  "Bearer flaky",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Unavailable\n",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "server errors are not cached again",
+			authorization:  "Bearer flaky",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "Unavailable\n",
+			expectedCalls:  1,
+		},
+		{
+			desc:           "anonymous requests are not cached",
+			path:           "/foo",
+			expectedStatus: http.StatusFound,
+			expectedCalls:  1,
+		},
+		{
+			desc:           "anonymous requests are not cached again",
+			path:           "/bar",
+			expectedStatus: http.StatusFound,
+			expectedCalls:  1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			atomic.StoreInt32(&calls, 0)
+
+			method := http.MethodGet
+			if test.method != "" {
+				method = test.method
+			}
+
+			req := httptest.NewRequest(method, "http://localhost"+test.path, nil)
+			if test.host != "" {
+				req.Host = test.host
+			}
+			if test.remoteAddr != "" {
+				req.RemoteAddr = test.remoteAddr
+			}
+			if test.tenant != "" {
+				req.Header.Set("X-Tenant", test.tenant)
+			}
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedCalls, atomic.LoadInt32(&calls))
+
+			if test.expectedStatus == http.StatusFound {
+				assert.Equal(t, "http://example.com/login?rd="+test.path, recorder.Header().Get("Location"))
+				return
+			}
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestForwardAuthCache_eviction(t *testing.T) {
+	cache := newForwardAuthCache(time.Minute, 2)
+
+	cache.set("foo", &forwardAuthDecision{statusCode: http.StatusOK})
+	cache.set("bar", &forwardAuthDecision{statusCode: http.StatusOK})
+
+	_, ok := cache.get("foo")
+	require.True(t, ok)
+
+	cache.set("baz", &forwardAuthDecision{statusCode: http.StatusOK})
+
+	_, ok = cache.get("bar")
+	assert.False(t, ok)
+	_, ok = cache.get("foo")
+	assert.True(t, ok)
+	_, ok = cache.get("baz")
+	assert.True(t, ok)
+
+	cache.ttl = -time.Second
+	cache.set("foo", &forwardAuthDecision{statusCode: http.StatusOK})
+
+	_, ok = cache.get("foo")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.list.Len())
+}
+
+func TestForwardAuthRequest(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          dynamic.ForwardAuth
+		body            string
+		expectedStatus  int
+		expectedMethod  string
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:           "default",
+			body:           "payload",
+			expectedStatus: http.StatusOK,
+			expectedMethod: http.MethodGet,
+			expectedHeaders: map[string]string{
+				"X-Signature":      "sha256=foo",
+				"X-Other":          "bar",
+				xForwardedURI:      "/webhook",
+				"X-Forwarded-Host": "localhost",
+			},
+		},
+		{
+			desc: "method and allowed headers",
+			config: dynamic.ForwardAuth{
+				Method:             http.MethodPost,
+				AuthRequestHeaders: []string{"x-signature"},
+			},
+			body:           "payload",
+			expectedStatus: http.StatusOK,
+			expectedMethod: http.MethodPost,
+			expectedHeaders: map[string]string{
+				"X-Signature":      "sha256=foo",
+				"X-Other":          "",
+				xForwardedURI:      "/webhook",
+				"X-Forwarded-Host": "localhost",
+			},
+		},
+		{
+			desc: "forwarded body",
+			config: dynamic.ForwardAuth{
+				Method:      http.MethodPost,
+				ForwardBody: true,
+			},
+			body:           "payload",
+			expectedStatus: http.StatusOK,
+			expectedMethod: http.MethodPost,
+			expectedBody:   "payload",
+		},
+		{
+			desc: "body too large",
+			config: dynamic.ForwardAuth{
+				ForwardBody: true,
+				MaxBodySize: 3,
+			},
+			body:           "payload",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var authReq *http.Request
+			var authBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authReq = r
+				authBody, _ = ioutil.ReadAll(r.Body)
+			}))
+			defer server.Close()
+
+			var nextBody []byte
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextBody, _ = ioutil.ReadAll(r.Body)
+			})
+
+			config := test.config
+			config.Address = server.URL
+
+			middleware, err := NewForward(context.Background(), next, config, "authTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost/webhook", strings.NewReader(test.body))
+			req.Header.Set("X-Signature", "sha256=foo")
+			req.Header.Set("X-Other", "bar")
+
+			recorder := httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, req)
+
+			require.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedStatus != http.StatusOK {
+				assert.Nil(t, authReq)
+				return
+			}
+
+			require.NotNil(t, authReq)
+			assert.Equal(t, test.expectedMethod, authReq.Method)
+			assert.Equal(t, test.expectedBody, string(authBody))
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, authReq.Header.Get(name), name)
+			}
+
+			// The service always gets the body.
+			assert.Equal(t, test.body, string(nextBody))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"gopkg.in/square/go-jose.v2"
+)
+
+// minJWKSRefreshInterval is the minimum time between two fetches of a JSON Web Key Set
+// triggered by tokens signed with unknown keys.
+const minJWKSRefreshInterval = 10 * time.Second
+
+// maxJWKSSize is the maximum size of a JSON Web Key Set fetched from a URL.
+const maxJWKSSize = 1 << 20
+
+// jwtKeys provides the keys the tokens can be signed with:
+// the static ones, and the ones of a JSON Web Key Set fetched from a URL, which is refreshed periodically.
+type jwtKeys struct {
+	static []jose.JSONWebKey
+
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	// fetchMu serializes the fetches of the remote keys.
+	fetchMu sync.Mutex
+
+	mu          sync.RWMutex
+	remote      []jose.JSONWebKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	refreshing  bool
+}
+
+// get returns the keys with the given key ID, or all the keys if the key ID is empty.
+func (k *jwtKeys) get(ctx context.Context, keyID string) []jose.JSONWebKey {
+	keys := k.lookup(keyID)
+	if k.url == "" {
+		return keys
+	}
+
+	k.mu.RLock()
+	fetched := !k.fetchedAt.IsZero()
+	stale := time.Since(k.fetchedAt) > k.refreshInterval
+	recentAttempt := time.Since(k.attemptedAt) < minJWKSRefreshInterval
+	k.mu.RUnlock()
+
+	// The keys are fetched right away when they are unknown, as they may have just been rotated.
+	if (len(keys) == 0 || !fetched) && !recentAttempt {
+		k.fetch(ctx)
+		return k.lookup(keyID)
+	}
+
+	if stale && !recentAttempt {
+		k.refreshInBackground(ctx)
+	}
+
+	return keys
+}
+
+func (k *jwtKeys) lookup(keyID string) []jose.JSONWebKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	var keys []jose.JSONWebKey
+	for _, set := range [][]jose.JSONWebKey{k.static, k.remote} {
+		for _, key := range set {
+			if keyID == "" || key.KeyID == keyID {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+func (k *jwtKeys) refreshInBackground(ctx context.Context) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.refreshing {
+		return
+	}
+	k.refreshing = true
+
+	// The context is only used for logging, so it can outlive the request.
+	safe.Go(func() {
+		k.fetch(ctx)
+
+		k.mu.Lock()
+		k.refreshing = false
+		k.mu.Unlock()
+	})
+}
+
+// fetch fetches the remote keys, unless they have just been fetched by a concurrent call.
+func (k *jwtKeys) fetch(ctx context.Context) {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	k.mu.RLock()
+	recentAttempt := time.Since(k.attemptedAt) < minJWKSRefreshInterval
+	k.mu.RUnlock()
+
+	if recentAttempt {
+		return
+	}
+
+	keys, err := fetchJWKS(k.client, k.url)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.attemptedAt = time.Now()
+	if err != nil {
+		// The previous keys are kept until the next successful fetch.
+		log.FromContext(ctx).Errorf("Unable to fetch JSON Web Key Set %s: %v", k.url, err)
+		return
+	}
+
+	k.remote = keys
+	k.fetchedAt = k.attemptedAt
+}
+
+func fetchJWKS(client *http.Client, url string) ([]jose.JSONWebKey, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxJWKSSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxJWKSSize {
+		return nil, fmt.Errorf("JSON Web Key Set larger than %d bytes", maxJWKSSize)
+	}
+
+	return parseJWKS(body)
+}
+
+// parseJWKS returns the public keys of a JSON Web Key Set used to verify signatures.
+func parseJWKS(data []byte) ([]jose.JSONWebKey, error) {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid JSON Web Key Set: %w", err)
+	}
+
+	var keys []jose.JSONWebKey
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if !key.IsPublic() {
+			key = key.Public()
+		}
+		if key.Valid() {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// parsePEMKeys returns the public keys of the given PEM content or file,
+// holding public keys or certificates.
+func parsePEMKeys(keyOrFile string) ([]jose.JSONWebKey, error) {
+	data := []byte(keyOrFile)
+	if _, err := os.Stat(keyOrFile); err == nil {
+		data, err = ioutil.ReadFile(keyOrFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key: %w", err)
+		}
+	}
+
+	var keys []jose.JSONWebKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key interface{}
+		var err error
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "RSA PUBLIC KEY":
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			cert, err = x509.ParseCertificate(block.Bytes)
+			if err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key: %w", err)
+		}
+
+		keys = append(keys, jose.JSONWebKey{Key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded key")
+	}
+	return keys, nil
+}
@@ -0,0 +1,355 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	jwtTypeName = "JWTAuth"
+
+	defaultJWKSRefreshInterval = time.Hour
+)
+
+// Bearer token errors (RFC 6750 section 3.1).
+const (
+	errorInvalidToken      = "invalid_token"
+	errorInsufficientScope = "insufficient_scope"
+)
+
+var errMissingExpiration = errors.New("missing expiration claim (exp)")
+
+type jwtAuth struct {
+	next           http.Handler
+	name           string
+	keys           *jwtKeys
+	issuer         string
+	audience       string
+	clockSkew      time.Duration
+	allowNoExp     bool
+	requiredScopes []string
+	requiredClaims map[string]string
+	claimsHeaders  map[string]string
+	realm          string
+}
+
+// NewJWT creates a JWT auth middleware.
+func NewJWT(ctx context.Context, next http.Handler, config dynamic.JWT, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, jwtTypeName)).Debug("Creating middleware")
+
+	if len(config.Keys) == 0 && config.JWKSFile == "" && config.JWKSURL == "" {
+		return nil, errors.New("no keys, jwksFile or jwksURL to verify the tokens with")
+	}
+
+	refreshInterval := time.Duration(config.JWKSRefreshInterval)
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+
+	keys := &jwtKeys{
+		url:             config.JWKSURL,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		refreshInterval: refreshInterval,
+	}
+
+	for _, key := range config.Keys {
+		pemKeys, err := parsePEMKeys(key)
+		if err != nil {
+			return nil, err
+		}
+		keys.static = append(keys.static, pemKeys...)
+	}
+
+	if config.JWKSFile != "" {
+		data, err := ioutil.ReadFile(config.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JSON Web Key Set: %w", err)
+		}
+
+		jwksKeys, err := parseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+		keys.static = append(keys.static, jwksKeys...)
+	}
+
+	realm := defaultRealm
+	if len(config.Realm) > 0 {
+		realm = config.Realm
+	}
+
+	return &jwtAuth{
+		next:           next,
+		name:           name,
+		keys:           keys,
+		issuer:         config.Issuer,
+		audience:       config.Audience,
+		clockSkew:      time.Duration(config.ClockSkew),
+		allowNoExp:     config.AllowMissingExpiration,
+		requiredScopes: config.RequiredScopes,
+		requiredClaims: config.RequiredClaims,
+		claimsHeaders:  config.ClaimsHeaders,
+		realm:          realm,
+	}, nil
+}
+
+func (j *jwtAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return j.name, tracing.SpanKindNoneEnum
+}
+
+func (j *jwtAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), j.name, jwtTypeName)
+	logger := log.FromContext(ctx)
+
+	// The headers set from the claims cannot come from the client.
+	for header := range j.claimsHeaders {
+		req.Header.Del(header)
+	}
+
+	token := bearerToken(req)
+	if token == "" {
+		logger.Debug("Authentication failed: no bearer token")
+		tracing.SetErrorWithEvent(req, "Authentication failed: no bearer token")
+		j.reject(rw, http.StatusUnauthorized, "", "")
+		return
+	}
+
+	claims, err := j.verify(ctx, token)
+	if err != nil {
+		logMessage := fmt.Sprintf("Authentication failed: %v", err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		j.reject(rw, http.StatusUnauthorized, errorInvalidToken, err.Error())
+		return
+	}
+
+	if err := j.authorize(claims); err != nil {
+		logMessage := fmt.Sprintf("Authorization failed: %v", err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		j.reject(rw, http.StatusForbidden, errorInsufficientScope, err.Error())
+		return
+	}
+
+	logger.Debug("Authentication succeeded")
+
+	if subject, ok := claims["sub"].(string); ok {
+		if logData := accesslog.GetLogData(req); logData != nil {
+			logData.Core[accesslog.ClientUsername] = subject
+		}
+	}
+
+	setClaimsHeaders(req, claims, j.claimsHeaders)
+
+	j.next.ServeHTTP(rw, req)
+}
+
+// verify checks the signature and the registered claims of the given token, and returns all its claims.
+func (j *jwtAuth) verify(ctx context.Context, raw string) (map[string]interface{}, error) {
+	registered, claims, err := verifyJWT(ctx, j.keys, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := registered.ValidateWithLeeway(jwt.Expected{Issuer: j.issuer, Time: time.Now()}, j.clockSkew); err != nil {
+		return nil, err
+	}
+
+	// A token without expiration would be valid forever.
+	if registered.Expiry == nil && !j.allowNoExp {
+		return nil, errMissingExpiration
+	}
+
+	if j.audience != "" && !registered.Audience.Contains(j.audience) {
+		return nil, jwt.ErrInvalidAudience
+	}
+
+	return claims, nil
+}
+
+// authorize checks the required scopes and claims.
+func (j *jwtAuth) authorize(claims map[string]interface{}) error {
+	if len(j.requiredScopes) > 0 {
+		scopes := make(map[string]struct{})
+		for _, name := range []string{"scope", "scp"} {
+			value, ok := claims[name]
+			if !ok {
+				continue
+			}
+
+			switch v := value.(type) {
+			case string:
+				for _, scope := range strings.Fields(v) {
+					scopes[scope] = struct{}{}
+				}
+			case []interface{}:
+				for _, scope := range v {
+					scopes[claimString(scope)] = struct{}{}
+				}
+			}
+		}
+
+		for _, scope := range j.requiredScopes {
+			if _, ok := scopes[scope]; !ok {
+				return fmt.Errorf("missing scope %q", scope)
+			}
+		}
+	}
+
+	return checkRequiredClaims(claims, j.requiredClaims)
+}
+
+// reject writes an error response with a WWW-Authenticate header for the bearer scheme (RFC 6750 section 3).
+func (j *jwtAuth) reject(rw http.ResponseWriter, status int, errorCode, description string) {
+	challenge := fmt.Sprintf("Bearer realm=%q", j.realm)
+	if errorCode != "" {
+		challenge += fmt.Sprintf(", error=%q, error_description=%q", errorCode, strings.ReplaceAll(description, `"`, "'"))
+	}
+	if errorCode == errorInsufficientScope && len(j.requiredScopes) > 0 {
+		challenge += fmt.Sprintf(", scope=%q", strings.Join(j.requiredScopes, " "))
+	}
+
+	rw.Header().Set("WWW-Authenticate", challenge)
+	rw.WriteHeader(status)
+	_, _ = rw.Write([]byte(http.StatusText(status)))
+}
+
+// verifyJWT checks the signature of the given token with the given keys,
+// and returns its registered claims, which are left to the caller to validate, and all its claims.
+func verifyJWT(ctx context.Context, keys *jwtKeys, raw string) (jwt.Claims, map[string]interface{}, error) {
+	var registered jwt.Claims
+
+	token, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return registered, nil, errors.New("malformed token")
+	}
+
+	if len(token.Headers) != 1 {
+		return registered, nil, errors.New("unexpected number of signatures")
+	}
+	header := token.Headers[0]
+
+	candidates := keys.get(ctx, header.KeyID)
+	if len(candidates) == 0 {
+		return registered, nil, fmt.Errorf("unknown key %q", header.KeyID)
+	}
+
+	var claims map[string]interface{}
+	for _, key := range candidates {
+		if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+			continue
+		}
+
+		if token.Claims(key.Key, &registered, &claims) == nil {
+			return registered, claims, nil
+		}
+	}
+
+	return registered, nil, errors.New("invalid signature")
+}
+
+// checkRequiredClaims returns an error if one of the claims is not equal to, or does not contain, its required value.
+func checkRequiredClaims(claims map[string]interface{}, required map[string]string) error {
+	for claim, expected := range required {
+		value, ok := claimValue(claims, claim)
+		if !ok || !claimContains(value, expected) {
+			return fmt.Errorf("claim %q does not match %q", claim, expected)
+		}
+	}
+
+	return nil
+}
+
+// setClaimsHeaders sets the request headers to the values of their claims.
+func setClaimsHeaders(req *http.Request, claims map[string]interface{}, claimsHeaders map[string]string) {
+	for header, claim := range claimsHeaders {
+		if value, ok := claimValue(claims, claim); ok {
+			req.Header.Set(header, claimString(value))
+		}
+	}
+}
+
+func bearerToken(req *http.Request) string {
+	const prefix = "bearer "
+
+	authorization := req.Header.Get(authorizationHeader)
+	if len(authorization) < len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(authorization[len(prefix):])
+}
+
+// claimValue returns the value of the given claim, where the names of nested claims are separated by dots.
+func claimValue(claims map[string]interface{}, name string) (interface{}, bool) {
+	// Claim names can contain dots, such as the URIs of the namespaced claims.
+	if value, ok := claims[name]; ok {
+		return value, true
+	}
+
+	var value interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		value, ok = object[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// claimString returns the given claim value as a header value, where the elements of arrays are separated by commas.
+func claimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, elt := range v {
+			values = append(values, claimString(elt))
+		}
+		return strings.Join(values, ",")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+}
+
+// claimContains returns whether the given claim value is, or contains when it is an array, the expected value.
+func claimContains(value interface{}, expected string) bool {
+	if values, ok := value.([]interface{}); ok {
+		for _, elt := range values {
+			if claimString(elt) == expected {
+				return true
+			}
+		}
+		return false
+	}
+
+	return claimString(value) == expected
+}
@@ -0,0 +1,375 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func pemPublicKey(t *testing.T, key *rsa.PrivateKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func jwksOf(t *testing.T, key *rsa.PrivateKey, keyID string) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: keyID, Use: "sig", Algorithm: string(jose.RS256)}}})
+	require.NoError(t, err)
+	return data
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, keyID string, claims ...interface{}) string {
+	t.Helper()
+
+	opts := &jose.SignerOptions{}
+	if keyID != "" {
+		opts.WithHeader("kid", keyID)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, opts)
+	require.NoError(t, err)
+
+	builder := jwt.Signed(signer)
+	for _, c := range claims {
+		builder = builder.Claims(c)
+	}
+
+	token, err := builder.CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTAuth(t *testing.T) {
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+
+	now := time.Now()
+	validClaims := jwt.Claims{
+		Subject:  "foo",
+		Issuer:   "https://issuer.example.com",
+		Audience: jwt.Audience{"api"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+
+	testCases := []struct {
+		desc                  string
+		config                dynamic.JWT
+		authorization         string
+		header                map[string]string
+		expectedStatus        int
+		expectedAuthenticate  string
+		expectedHeaders       map[string]string
+		expectedAbsentHeaders []string
+	}{
+		{
+			desc:                 "no token",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik"`,
+		},
+		{
+			desc:                 "basic credentials",
+			authorization:        "Basic Zm9vOmJhcg==",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik"`,
+		},
+		{
+			desc:           "valid token",
+			authorization:  "Bearer " + signToken(t, key, "", validClaims),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "valid token with lowercase scheme",
+			authorization:  "bearer " + signToken(t, key, "", validClaims),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:                 "malformed token",
+			authorization:        "Bearer foo",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik", error="invalid_token", error_description="malformed token"`,
+		},
+		{
+			desc:                 "token signed with another key",
+			authorization:        "Bearer " + signToken(t, otherKey, "", validClaims),
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik", error="invalid_token", error_description="invalid signature"`,
+		},
+		{
+			desc: "expired token",
+			authorization: "Bearer " + signToken(t, key, "", jwt.Claims{
+				Subject: "foo",
+				Expiry:  jwt.NewNumericDate(now.Add(-time.Hour)),
+			}),
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik", error="invalid_token", error_description="square/go-jose/jwt: validation failed, token is expired (exp)"`,
+		},
+		{
+			desc: "token expired within the clock skew",
+			authorization: "Bearer " + signToken(t, key, "", jwt.Claims{
+				Subject: "foo",
+				Expiry:  jwt.NewNumericDate(now.Add(-10 * time.Second)),
+			}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:                 "token without expiration",
+			authorization:        "Bearer " + signToken(t, key, "", jwt.Claims{Subject: "foo"}),
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik", error="invalid_token", error_description="missing expiration claim (exp)"`,
+		},
+		{
+			desc:           "token without expiration allowed",
+			config:         dynamic.JWT{AllowMissingExpiration: true},
+			authorization:  "Bearer " + signToken(t, key, "", jwt.Claims{Subject: "foo"}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "token not valid yet",
+			authorization: "Bearer " + signToken(t, key, "", jwt.Claims{
+				Subject:   "foo",
+				NotBefore: jwt.NewNumericDate(now.Add(time.Hour)),
+			}),
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik", error="invalid_token", error_description="square/go-jose/jwt: validation failed, token not valid yet (nbf)"`,
+		},
+		{
+			desc:           "expected issuer and audience",
+			config:         dynamic.JWT{Issuer: "https://issuer.example.com", Audience: "api"},
+			authorization:  "Bearer " + signToken(t, key, "", validClaims),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:                 "unexpected issuer",
+			config:               dynamic.JWT{Issuer: "https://other.example.com"},
+			authorization:        "Bearer " + signToken(t, key, "", validClaims),
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik", error="invalid_token", error_description="square/go-jose/jwt: validation failed, invalid issuer claim (iss)"`,
+		},
+		{
+			desc:                 "unexpected audience",
+			config:               dynamic.JWT{Audience: "other"},
+			authorization:        "Bearer " + signToken(t, key, "", validClaims),
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="traefik", error="invalid_token", error_description="square/go-jose/jwt: validation failed, invalid audience claim (aud)"`,
+		},
+		{
+			desc:           "required scopes",
+			config:         dynamic.JWT{RequiredScopes: []string{"read", "write"}},
+			authorization:  "Bearer " + signToken(t, key, "", validClaims, map[string]interface{}{"scope": "read write admin"}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "required scopes in scp",
+			config:         dynamic.JWT{RequiredScopes: []string{"read", "write"}},
+			authorization:  "Bearer " + signToken(t, key, "", validClaims, map[string]interface{}{"scp": []string{"read", "write"}}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:                 "missing scope",
+			config:               dynamic.JWT{RequiredScopes: []string{"read", "write"}},
+			authorization:        "Bearer " + signToken(t, key, "", validClaims, map[string]interface{}{"scope": "read"}),
+			expectedStatus:       http.StatusForbidden,
+			expectedAuthenticate: `Bearer realm="traefik", error="insufficient_scope", error_description="missing scope 'write'", scope="read write"`,
+		},
+		{
+			desc:           "required claims",
+			config:         dynamic.JWT{RequiredClaims: map[string]string{"tenant": "acme", "realm_access.roles": "admin"}},
+			authorization:  "Bearer " + signToken(t, key, "", validClaims, map[string]interface{}{"tenant": "acme", "realm_access": map[string]interface{}{"roles": []string{"user", "admin"}}}),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:                 "mismatching claim",
+			config:               dynamic.JWT{RequiredClaims: map[string]string{"realm_access.roles": "admin"}},
+			authorization:        "Bearer " + signToken(t, key, "", validClaims, map[string]interface{}{"realm_access": map[string]interface{}{"roles": []string{"user"}}}),
+			expectedStatus:       http.StatusForbidden,
+			expectedAuthenticate: `Bearer realm="traefik", error="insufficient_scope", error_description="claim 'realm_access.roles' does not match 'admin'"`,
+		},
+		{
+			desc:   "claims headers",
+			config: dynamic.JWT{ClaimsHeaders: map[string]string{"X-User": "sub", "X-Roles": "roles", "X-Level": "level", "X-Tenant": "tenant"}},
+			authorization: "Bearer " + signToken(t, key, "", validClaims, map[string]interface{}{
+				"roles": []string{"user", "admin"},
+				"level": 42,
+			}),
+			header:                map[string]string{"X-Tenant": "spoofed"},
+			expectedStatus:        http.StatusOK,
+			expectedHeaders:       map[string]string{"X-User": "foo", "X-Roles": "user,admin", "X-Level": "42"},
+			expectedAbsentHeaders: []string{"X-Tenant"},
+		},
+		{
+			desc:                 "custom realm",
+			config:               dynamic.JWT{Realm: "api"},
+			expectedStatus:       http.StatusUnauthorized,
+			expectedAuthenticate: `Bearer realm="api"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := test.config
+			config.SetDefaults()
+			config.JWKSRefreshInterval = test.config.JWKSRefreshInterval
+			config.Keys = []string{pemPublicKey(t, key)}
+
+			var forwarded *http.Request
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req
+			})
+
+			handler, err := NewJWT(context.Background(), next, config, "jwt")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			for name, value := range test.header {
+				req.Header.Set(name, value)
+			}
+
+			logData := &accesslog.LogData{Core: make(accesslog.CoreLogData)}
+			req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedAuthenticate, recorder.Header().Get("WWW-Authenticate"))
+
+			if test.expectedStatus != http.StatusOK {
+				assert.Nil(t, forwarded)
+				return
+			}
+
+			require.NotNil(t, forwarded)
+			assert.Equal(t, "foo", logData.Core[accesslog.ClientUsername])
+
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, forwarded.Header.Get(name))
+			}
+			for _, name := range test.expectedAbsentHeaders {
+				assert.Empty(t, forwarded.Header.Values(name))
+			}
+		})
+	}
+}
+
+func TestJWTAuth_jwksFile(t *testing.T) {
+	key := newTestKey(t)
+	claims := jwt.Claims{Subject: "foo", Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+	file, err := ioutil.TempFile("", "jwks")
+	require.NoError(t, err)
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = file.Write(jwksOf(t, key, "key1"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	handler, err := NewJWT(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.JWT{JWKSFile: file.Name()}, "jwt")
+	require.NoError(t, err)
+
+	for keyID, expectedStatus := range map[string]int{"key1": http.StatusOK, "": http.StatusOK, "key2": http.StatusUnauthorized} {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+		req.Header.Set("Authorization", "Bearer "+signToken(t, key, keyID, claims))
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, expectedStatus, recorder.Code, "key %q", keyID)
+	}
+}
+
+func TestJWTAuth_jwksURL(t *testing.T) {
+	oldKey := newTestKey(t)
+	newKey := newTestKey(t)
+	claims := jwt.Claims{Subject: "foo", Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+	var jwks atomic.Value
+	jwks.Store(jwksOf(t, oldKey, "old"))
+
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		_, _ = rw.Write(jwks.Load().([]byte))
+	}))
+	defer server.Close()
+
+	handler, err := NewJWT(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.JWT{JWKSURL: server.URL}, "jwt")
+	require.NoError(t, err)
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(signToken(t, oldKey, "old", claims)))
+	assert.Equal(t, http.StatusOK, serve(signToken(t, oldKey, "old", claims)))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// The keys are not fetched again right away for unknown keys.
+	jwks.Store(jwksOf(t, newKey, "new"))
+	assert.Equal(t, http.StatusUnauthorized, serve(signToken(t, newKey, "new", claims)))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	// Once the minimum interval elapsed, an unknown key triggers a fetch.
+	jwtKeys := handler.(*jwtAuth).keys
+	jwtKeys.mu.Lock()
+	jwtKeys.attemptedAt = time.Now().Add(-minJWKSRefreshInterval)
+	jwtKeys.mu.Unlock()
+
+	assert.Equal(t, http.StatusOK, serve(signToken(t, newKey, "new", claims)))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+	assert.Equal(t, http.StatusUnauthorized, serve(signToken(t, oldKey, "old", claims)))
+}
+
+func TestFetchJWKS_tooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(make([]byte, maxJWKSSize+1))
+	}))
+	defer server.Close()
+
+	_, err := fetchJWKS(server.Client(), server.URL)
+	assert.EqualError(t, err, "JSON Web Key Set larger than 1048576 bytes")
+}
+
+func TestNewJWT_noKeys(t *testing.T) {
+	_, err := NewJWT(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.JWT{}, "jwt")
+	assert.Error(t, err)
+}
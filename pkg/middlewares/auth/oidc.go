@@ -0,0 +1,602 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/opentracing/opentracing-go/ext"
+	"golang.org/x/oauth2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	oidcTypeName = "OIDCAuth"
+
+	defaultOIDCCallbackPath    = "/oauth2/callback"
+	defaultOIDCCookieName      = "_traefik_oidc"
+	defaultOIDCSessionLifetime = 24 * time.Hour
+	defaultOIDCGroupsClaim     = "groups"
+
+	// oidcScope is the scope requesting an ID token, which is always added to the configured scopes.
+	oidcScope = "openid"
+
+	// oidcLoginLifetime is how long the users have to authenticate with the provider.
+	oidcLoginLifetime = 10 * time.Minute
+	// minOIDCDiscoveryInterval is the minimum time between two attempts to discover the provider.
+	minOIDCDiscoveryInterval = 10 * time.Second
+)
+
+// oidcMetadata is the part of the OpenID provider metadata used by the middleware.
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// oidcProvider is a discovered OpenID provider.
+type oidcProvider struct {
+	metadata oidcMetadata
+	oauth2   oauth2.Config
+	keys     *jwtKeys
+}
+
+type oidcAuth struct {
+	next                http.Handler
+	name                string
+	issuer              string
+	clientID            string
+	clientSecret        string
+	scopes              []string
+	callbackPath        string
+	logoutPath          string
+	cookies             *oidcCookies
+	sessionLifetime     time.Duration
+	allowedEmailDomains []string
+	allowedGroups       []string
+	groupsClaim         string
+	requiredClaims      map[string]string
+	claimsHeaders       map[string]string
+	forwardAccessToken  bool
+	client              *http.Client
+
+	mu                   sync.Mutex
+	provider             *oidcProvider
+	discoveryErr         error
+	discoveryAttemptedAt time.Time
+}
+
+// NewOIDC creates an OpenID Connect auth middleware.
+func NewOIDC(ctx context.Context, next http.Handler, config dynamic.OIDC, name string) (http.Handler, error) {
+	log.FromContext(middlewares.GetLoggerCtx(ctx, name, oidcTypeName)).Debug("Creating middleware")
+
+	if config.Issuer == "" || config.ClientID == "" {
+		return nil, errors.New("issuer and clientID are required")
+	}
+
+	if config.Secret == "" {
+		return nil, errors.New("a secret is required to encrypt the session cookies")
+	}
+
+	cookieName := config.CookieName
+	if cookieName == "" {
+		cookieName = defaultOIDCCookieName
+	}
+
+	cookiePath := config.CookiePath
+	if cookiePath == "" {
+		cookiePath = "/"
+	}
+
+	cookies, err := newOIDCCookies(cookieName, config.CookieDomain, cookiePath, config.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []string{oidcScope}
+	for _, scope := range config.Scopes {
+		if scope != oidcScope {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	callbackPath := config.CallbackPath
+	if callbackPath == "" {
+		callbackPath = defaultOIDCCallbackPath
+	}
+
+	sessionLifetime := time.Duration(config.SessionLifetime)
+	if sessionLifetime <= 0 {
+		sessionLifetime = defaultOIDCSessionLifetime
+	}
+
+	groupsClaim := config.GroupsClaim
+	if groupsClaim == "" {
+		groupsClaim = defaultOIDCGroupsClaim
+	}
+
+	return &oidcAuth{
+		next:                next,
+		name:                name,
+		issuer:              config.Issuer,
+		clientID:            config.ClientID,
+		clientSecret:        config.ClientSecret,
+		scopes:              scopes,
+		callbackPath:        callbackPath,
+		logoutPath:          config.LogoutPath,
+		cookies:             cookies,
+		sessionLifetime:     sessionLifetime,
+		allowedEmailDomains: config.AllowedEmailDomains,
+		allowedGroups:       config.AllowedGroups,
+		groupsClaim:         groupsClaim,
+		requiredClaims:      config.RequiredClaims,
+		claimsHeaders:       config.ClaimsHeaders,
+		forwardAccessToken:  config.ForwardAccessToken,
+		client:              &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (o *oidcAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, tracing.SpanKindNoneEnum
+}
+
+func (o *oidcAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := middlewares.GetLoggerCtx(req.Context(), o.name, oidcTypeName)
+	logger := log.FromContext(ctx)
+
+	provider, err := o.getProvider(ctx)
+	if err != nil {
+		logMessage := fmt.Sprintf("Unable to discover the OpenID provider %s: %v", o.issuer, err)
+		logger.Error(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch {
+	case req.URL.Path == o.callbackPath:
+		o.callback(ctx, rw, req, provider)
+		return
+	case o.logoutPath != "" && req.URL.Path == o.logoutPath:
+		o.logout(rw, req, provider)
+		return
+	}
+
+	var session oidcSession
+	if err := o.cookies.read(req, o.cookies.name, &session); err != nil {
+		logger.Debugf("No valid session: %v", err)
+		o.login(ctx, rw, req, provider)
+		return
+	}
+
+	if time.Since(session.CreatedAt) > o.sessionLifetime {
+		logger.Debug("Session expired")
+		o.login(ctx, rw, req, provider)
+		return
+	}
+
+	if !session.Expiry.IsZero() && time.Now().After(session.Expiry) {
+		refreshed, err := o.refresh(ctx, req, provider, &session)
+		if err != nil {
+			logger.Debugf("Unable to refresh the session: %v", err)
+			o.login(ctx, rw, req, provider)
+			return
+		}
+
+		if err := o.writeSession(rw, req, refreshed); err != nil {
+			logger.Errorf("Unable to write the session: %v", err)
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		session = *refreshed
+	}
+
+	if err := o.authorize(session.Claims); err != nil {
+		logMessage := fmt.Sprintf("Authorization failed: %v", err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		writeStatus(rw, http.StatusForbidden)
+		return
+	}
+
+	o.forward(rw, req, &session)
+}
+
+func (o *oidcAuth) forward(rw http.ResponseWriter, req *http.Request, session *oidcSession) {
+	if logData := accesslog.GetLogData(req); logData != nil {
+		if username := oidcUsername(session.Claims); username != "" {
+			logData.Core[accesslog.ClientUsername] = username
+		}
+	}
+
+	// The cookies of the middleware are not forwarded to the service.
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if !o.cookies.isOwn(cookie.Name) {
+			req.AddCookie(cookie)
+		}
+	}
+
+	// The headers set from the claims cannot come from the client.
+	for header := range o.claimsHeaders {
+		req.Header.Del(header)
+	}
+	setClaimsHeaders(req, session.Claims, o.claimsHeaders)
+
+	if o.forwardAccessToken && session.AccessToken != "" {
+		req.Header.Set(authorizationHeader, "Bearer "+session.AccessToken)
+	}
+
+	o.next.ServeHTTP(rw, req)
+}
+
+// login redirects the user to the authorization endpoint of the provider,
+// with the authorization code flow and PKCE (RFC 7636).
+func (o *oidcAuth) login(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	// Only the navigations can be redirected to the provider, without losing the request body.
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		tracing.SetErrorWithEvent(req, "Authentication failed: no session")
+		writeStatus(rw, http.StatusUnauthorized)
+		return
+	}
+
+	login := oidcLogin{RedirectURI: localRedirectURI(req.URL)}
+	for _, value := range []*string{&login.State, &login.Nonce, &login.Verifier} {
+		random, err := randomString()
+		if err != nil {
+			log.FromContext(ctx).Errorf("Unable to start the authentication: %v", err)
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		*value = random
+	}
+
+	if err := o.cookies.write(rw, req, o.cookies.loginName(), login, oidcLoginLifetime); err != nil {
+		log.FromContext(ctx).Errorf("Unable to start the authentication: %v", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	challenge := sha256.Sum256([]byte(login.Verifier))
+
+	config := o.oauth2Config(req, provider)
+	authURL := config.AuthCodeURL(login.State,
+		oauth2.SetAuthURLParam("nonce", login.Nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	http.Redirect(rw, req, authURL, http.StatusFound)
+}
+
+// callback exchanges the authorization code for the tokens, and starts the session.
+func (o *oidcAuth) callback(ctx context.Context, rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	logger := log.FromContext(ctx)
+
+	var login oidcLogin
+	err := o.cookies.read(req, o.cookies.loginName(), &login)
+	o.cookies.clear(rw, req, o.cookies.loginName())
+	if err != nil {
+		o.callbackFailed(rw, req, http.StatusUnauthorized, fmt.Errorf("no valid login state: %w", err))
+		return
+	}
+
+	query := req.URL.Query()
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(login.State)) != 1 {
+		o.callbackFailed(rw, req, http.StatusUnauthorized, errors.New("state mismatch"))
+		return
+	}
+
+	if errorCode := query.Get("error"); errorCode != "" {
+		o.callbackFailed(rw, req, http.StatusUnauthorized, fmt.Errorf("provider error %s: %s", errorCode, query.Get("error_description")))
+		return
+	}
+
+	config := o.oauth2Config(req, provider)
+	token, err := config.Exchange(o.oauth2Context(req), query.Get("code"), oauth2.SetAuthURLParam("code_verifier", login.Verifier))
+	if err != nil {
+		o.callbackFailed(rw, req, http.StatusUnauthorized, fmt.Errorf("unable to exchange the authorization code: %w", err))
+		return
+	}
+
+	session, err := o.newSession(ctx, provider, token, login.Nonce, nil)
+	if err != nil {
+		o.callbackFailed(rw, req, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err := o.authorize(session.Claims); err != nil {
+		o.callbackFailed(rw, req, http.StatusForbidden, err)
+		return
+	}
+
+	if err := o.writeSession(rw, req, session); err != nil {
+		logger.Errorf("Unable to write the session: %v", err)
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	logger.Debug("Authentication succeeded")
+	redirectURI := login.RedirectURI
+	if !isLocalPath(redirectURI) {
+		redirectURI = "/"
+	}
+	http.Redirect(rw, req, redirectURI, http.StatusFound)
+}
+
+func (o *oidcAuth) callbackFailed(rw http.ResponseWriter, req *http.Request, status int, err error) {
+	logMessage := fmt.Sprintf("Authentication failed: %v", err)
+	log.FromContext(middlewares.GetLoggerCtx(req.Context(), o.name, oidcTypeName)).Debug(logMessage)
+	tracing.SetErrorWithEvent(req, logMessage)
+	writeStatus(rw, status)
+}
+
+// logout ends the session, and redirects the user to the end session endpoint of the provider, if any.
+func (o *oidcAuth) logout(rw http.ResponseWriter, req *http.Request, provider *oidcProvider) {
+	o.cookies.clear(rw, req, o.cookies.name)
+
+	if provider.metadata.EndSessionEndpoint == "" {
+		writeStatus(rw, http.StatusOK)
+		return
+	}
+
+	endSessionURL, err := url.Parse(provider.metadata.EndSessionEndpoint)
+	if err != nil {
+		writeStatus(rw, http.StatusOK)
+		return
+	}
+
+	query := endSessionURL.Query()
+	query.Set("client_id", o.clientID)
+	endSessionURL.RawQuery = query.Encode()
+
+	http.Redirect(rw, req, endSessionURL.String(), http.StatusFound)
+}
+
+// refresh refreshes the tokens of an expired session.
+func (o *oidcAuth) refresh(ctx context.Context, req *http.Request, provider *oidcProvider, session *oidcSession) (*oidcSession, error) {
+	if session.RefreshToken == "" {
+		return nil, errors.New("session expired without refresh token")
+	}
+
+	token, err := provider.oauth2.TokenSource(o.oauth2Context(req), &oauth2.Token{RefreshToken: session.RefreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+
+	return o.newSession(ctx, provider, token, "", session)
+}
+
+// newSession creates a session from the tokens returned by the provider.
+// When refreshing a session, the provider may not return a new ID token, in which case the previous claims are kept.
+func (o *oidcAuth) newSession(ctx context.Context, provider *oidcProvider, token *oauth2.Token, nonce string, previous *oidcSession) (*oidcSession, error) {
+	session := &oidcSession{
+		RefreshToken: token.RefreshToken,
+		Expiry:       token.Expiry,
+		CreatedAt:    time.Now(),
+	}
+
+	if o.forwardAccessToken {
+		session.AccessToken = token.AccessToken
+	}
+
+	if previous != nil {
+		session.Claims = previous.Claims
+		session.CreatedAt = previous.CreatedAt
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		if previous == nil {
+			return nil, errors.New("no ID token")
+		}
+		return session, nil
+	}
+
+	registered, claims, err := verifyJWT(ctx, provider.keys, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	expected := jwt.Expected{Issuer: provider.metadata.Issuer, Audience: jwt.Audience{o.clientID}, Time: time.Now()}
+	if err := registered.ValidateWithLeeway(expected, jwt.DefaultLeeway); err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if nonce != "" {
+		if tokenNonce, _ := claims["nonce"].(string); subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+			return nil, errors.New("invalid ID token: nonce mismatch")
+		}
+	}
+
+	session.Claims = claims
+	if session.Expiry.IsZero() && registered.Expiry != nil {
+		session.Expiry = registered.Expiry.Time()
+	}
+
+	return session, nil
+}
+
+func (o *oidcAuth) writeSession(rw http.ResponseWriter, req *http.Request, session *oidcSession) error {
+	return o.cookies.write(rw, req, o.cookies.name, session, time.Until(session.CreatedAt.Add(o.sessionLifetime)))
+}
+
+// authorize checks the email domain, the groups and the required claims of the user.
+func (o *oidcAuth) authorize(claims map[string]interface{}) error {
+	if len(o.allowedEmailDomains) > 0 {
+		if verified, ok := claims["email_verified"].(bool); ok && !verified {
+			return errors.New("email not verified")
+		}
+
+		email, _ := claims["email"].(string)
+		at := strings.LastIndex(email, "@")
+		if at < 0 || !containsFold(o.allowedEmailDomains, email[at+1:]) {
+			return fmt.Errorf("email %q not allowed", email)
+		}
+	}
+
+	if len(o.allowedGroups) > 0 {
+		groups, _ := claimValue(claims, o.groupsClaim)
+
+		allowed := false
+		for _, group := range o.allowedGroups {
+			if groups != nil && claimContains(groups, group) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errors.New("no allowed group")
+		}
+	}
+
+	return checkRequiredClaims(claims, o.requiredClaims)
+}
+
+// getProvider returns the provider, discovered from its metadata on the first call.
+func (o *oidcAuth) getProvider(ctx context.Context) (*oidcProvider, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.provider != nil {
+		return o.provider, nil
+	}
+
+	if time.Since(o.discoveryAttemptedAt) < minOIDCDiscoveryInterval {
+		return nil, o.discoveryErr
+	}
+	o.discoveryAttemptedAt = time.Now()
+
+	metadata, err := o.discover()
+	if err != nil {
+		o.discoveryErr = err
+		return nil, err
+	}
+
+	log.FromContext(ctx).Debugf("Discovered the OpenID provider %s", metadata.Issuer)
+
+	o.provider = &oidcProvider{
+		metadata: *metadata,
+		oauth2: oauth2.Config{
+			ClientID:     o.clientID,
+			ClientSecret: o.clientSecret,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  metadata.AuthorizationEndpoint,
+				TokenURL: metadata.TokenEndpoint,
+			},
+			Scopes: o.scopes,
+		},
+		keys: &jwtKeys{
+			url:             metadata.JWKSURI,
+			client:          o.client,
+			refreshInterval: defaultJWKSRefreshInterval,
+		},
+	}
+	return o.provider, nil
+}
+
+func (o *oidcAuth) discover() (*oidcMetadata, error) {
+	resp, err := o.client.Get(strings.TrimSuffix(o.issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var metadata oidcMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("invalid provider metadata: %w", err)
+	}
+
+	if metadata.Issuer != o.issuer {
+		return nil, fmt.Errorf("issuer %q does not match the configured one", metadata.Issuer)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("incomplete provider metadata")
+	}
+
+	return &metadata, nil
+}
+
+// oauth2Config returns the OAuth2 configuration, with the callback URL of the request host.
+func (o *oidcAuth) oauth2Config(req *http.Request, provider *oidcProvider) oauth2.Config {
+	config := provider.oauth2
+	config.RedirectURL = requestScheme(req) + "://" + req.Host + o.callbackPath
+	return config
+}
+
+func (o *oidcAuth) oauth2Context(req *http.Request) context.Context {
+	return context.WithValue(req.Context(), oauth2.HTTPClient, o.client)
+}
+
+func oidcUsername(claims map[string]interface{}) string {
+	if email, ok := claims["email"].(string); ok && email != "" {
+		return email
+	}
+	subject, _ := claims["sub"].(string)
+	return subject
+}
+
+// localRedirectURI returns the path and query of the given URL, to redirect the user back to it after the login.
+// A path the browsers could take for another host is replaced by the root path.
+func localRedirectURI(u *url.URL) string {
+	uri := u.EscapedPath()
+	if !isLocalPath(uri) {
+		uri = "/"
+	}
+
+	if u.RawQuery != "" {
+		uri += "?" + u.RawQuery
+	}
+	return uri
+}
+
+// isLocalPath reports whether the given path starts with exactly one slash,
+// as the browsers follow a redirection to //foo.com or /\foo.com to the host foo.com.
+func isLocalPath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.HasPrefix(path, "/\\")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func randomString() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func writeStatus(rw http.ResponseWriter, status int) {
+	rw.WriteHeader(status)
+	_, _ = rw.Write([]byte(http.StatusText(status)))
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCookieValueSize is the size of the chunks the cookie values are split into,
+// to stay below the 4096 bytes browsers allow for a cookie with its attributes.
+const maxCookieValueSize = 3800
+
+// oidcSession is the session of an authenticated user, kept in a cookie.
+type oidcSession struct {
+	Claims       map[string]interface{} `json:"claims"`
+	AccessToken  string                 `json:"accessToken,omitempty"`
+	RefreshToken string                 `json:"refreshToken,omitempty"`
+	// Expiry is when the tokens have to be refreshed, if not zero.
+	Expiry    time.Time `json:"expiry"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// oidcLogin is the state of an authorization request, kept in a cookie until the callback.
+type oidcLogin struct {
+	State       string `json:"state"`
+	Nonce       string `json:"nonce"`
+	Verifier    string `json:"verifier"`
+	RedirectURI string `json:"redirectURI"`
+}
+
+// oidcCookies reads and writes the encrypted cookies of the OIDC middleware.
+type oidcCookies struct {
+	name   string
+	domain string
+	path   string
+	aead   cipher.AEAD
+}
+
+func newOIDCCookies(name, domain, path, secret string) (*oidcCookies, error) {
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidcCookies{name: name, domain: domain, path: path, aead: aead}, nil
+}
+
+func (c *oidcCookies) loginName() string {
+	return c.name + "_login"
+}
+
+// isOwn returns whether the given cookie is one of the cookies of the middleware,
+// or one of their chunks.
+func (c *oidcCookies) isOwn(name string) bool {
+	return isChunkOf(name, c.name) || isChunkOf(name, c.loginName())
+}
+
+// isChunkOf returns whether the given cookie name is the one of the given cookie, or of one of its chunks.
+func isChunkOf(name, cookieName string) bool {
+	if name == cookieName {
+		return true
+	}
+
+	if !strings.HasPrefix(name, cookieName+"_") {
+		return false
+	}
+	_, err := strconv.Atoi(name[len(cookieName)+1:])
+	return err == nil
+}
+
+// read decrypts the value of the given cookie, which may be split into chunks, into v.
+func (c *oidcCookies) read(req *http.Request, name string, v interface{}) error {
+	cookie, err := req.Cookie(name)
+	if err != nil {
+		return err
+	}
+
+	value := cookie.Value
+	for i := 1; ; i++ {
+		chunk, err := req.Cookie(chunkName(name, i))
+		if err != nil {
+			break
+		}
+		value += chunk.Value
+	}
+
+	return c.open(name, value, v)
+}
+
+// write encrypts v into the given cookie, split into chunks if needed.
+func (c *oidcCookies) write(rw http.ResponseWriter, req *http.Request, name string, v interface{}, maxAge time.Duration) error {
+	value, err := c.seal(name, v)
+	if err != nil {
+		return err
+	}
+
+	chunks := 0
+	for ; len(value) > 0; chunks++ {
+		size := maxCookieValueSize
+		if len(value) < size {
+			size = len(value)
+		}
+
+		http.SetCookie(rw, c.cookie(req, chunkName(name, chunks), value[:size], int(maxAge.Seconds())))
+		value = value[size:]
+	}
+
+	// The chunks left from a previous and larger value are removed.
+	c.clearChunks(rw, req, name, chunks)
+	return nil
+}
+
+// clear removes the given cookie, and its chunks.
+func (c *oidcCookies) clear(rw http.ResponseWriter, req *http.Request, name string) {
+	c.clearChunks(rw, req, name, 0)
+}
+
+func (c *oidcCookies) clearChunks(rw http.ResponseWriter, req *http.Request, name string, from int) {
+	for i := from; ; i++ {
+		if _, err := req.Cookie(chunkName(name, i)); err != nil {
+			return
+		}
+		http.SetCookie(rw, c.cookie(req, chunkName(name, i), "", -1))
+	}
+}
+
+func (c *oidcCookies) cookie(req *http.Request, name, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     c.path,
+		Domain:   c.domain,
+		MaxAge:   maxAge,
+		Secure:   requestScheme(req) == "https",
+		HttpOnly: true,
+		// The cookies have to be sent along with the redirection from the provider.
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func (c *oidcCookies) seal(name string, v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	// The cookie name is authenticated, so that a cookie cannot be used in place of another.
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, data, []byte(name))), nil
+}
+
+func (c *oidcCookies) open(name, value string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return err
+	}
+
+	if len(data) < c.aead.NonceSize() {
+		return errors.New("invalid cookie")
+	}
+
+	data, err = c.aead.Open(nil, data[:c.aead.NonceSize()], data[c.aead.NonceSize():], []byte(name))
+	if err != nil {
+		return errors.New("invalid cookie")
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func chunkName(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s_%d", name, i)
+}
+
+func requestScheme(req *http.Request) string {
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return proto
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
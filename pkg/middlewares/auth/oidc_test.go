@@ -0,0 +1,541 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// oidcTestProvider is a stand-in OpenID provider, issuing the tokens of a single user.
+type oidcTestProvider struct {
+	*httptest.Server
+
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+
+	mu             sync.Mutex
+	authorizations map[string]url.Values
+	refreshes      int
+}
+
+func newOIDCTestProvider(t *testing.T, claims map[string]interface{}) *oidcTestProvider {
+	t.Helper()
+
+	provider := &oidcTestProvider{
+		key:            newTestKey(t),
+		claims:         claims,
+		authorizations: make(map[string]url.Values),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(oidcMetadata{
+			Issuer:                provider.URL,
+			AuthorizationEndpoint: provider.URL + "/authorize",
+			TokenEndpoint:         provider.URL + "/token",
+			JWKSURI:               provider.URL + "/jwks",
+			EndSessionEndpoint:    provider.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write(jwksOf(t, provider.key, "key1"))
+	})
+	mux.HandleFunc("/token", provider.token(t))
+
+	provider.Server = httptest.NewServer(mux)
+	t.Cleanup(provider.Close)
+
+	return provider
+}
+
+// authorize authenticates the user for the given authorization request, and returns the authorization code.
+func (p *oidcTestProvider) authorize(t *testing.T, authURL string) string {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, p.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+
+	query := u.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "client", query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Contains(t, strings.Fields(query.Get("scope")), "openid")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	code := query.Get("state") + "-code"
+	p.authorizations[code] = query
+	return code
+}
+
+func (p *oidcTestProvider) token(t *testing.T) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+
+		if clientID, clientSecret, _ := req.BasicAuth(); clientID != "client" || clientSecret != "secret" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		var nonce string
+		switch req.Form.Get("grant_type") {
+		case "authorization_code":
+			authorization, ok := p.authorizations[req.Form.Get("code")]
+			delete(p.authorizations, req.Form.Get("code"))
+
+			challenge := sha256.Sum256([]byte(req.Form.Get("code_verifier")))
+			if !ok || authorization.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) ||
+				authorization.Get("redirect_uri") != req.Form.Get("redirect_uri") {
+				rw.WriteHeader(http.StatusBadRequest)
+				_, _ = rw.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			nonce = authorization.Get("nonce")
+		case "refresh_token":
+			if req.Form.Get("refresh_token") != "refresh-token" {
+				rw.WriteHeader(http.StatusBadRequest)
+				_, _ = rw.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			p.refreshes++
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		claims := map[string]interface{}{"nonce": nonce}
+		for name, value := range p.claims {
+			claims[name] = value
+		}
+
+		idToken := signToken(t, p.key, "key1", jwt.Claims{
+			Issuer:   p.URL,
+			Subject:  "user",
+			Audience: jwt.Audience{"client"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}, claims)
+
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"access_token":  "access-token",
+			"token_type":    "Bearer",
+			"refresh_token": "refresh-token",
+			"expires_in":    3600,
+			"id_token":      idToken,
+		})
+	}
+}
+
+func newTestOIDC(t *testing.T, provider *oidcTestProvider, config dynamic.OIDC, next http.Handler) *oidcAuth {
+	t.Helper()
+
+	config.Issuer = provider.URL
+	config.ClientID = "client"
+	config.ClientSecret = "secret"
+	config.Secret = "cookie-secret"
+
+	handler, err := NewOIDC(context.Background(), next, config, "oidc")
+	require.NoError(t, err)
+
+	return handler.(*oidcAuth)
+}
+
+func serveOIDC(handler http.Handler, method, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// loginWithOIDC goes through the authorization code flow, and returns the response to the callback.
+func loginWithOIDC(t *testing.T, handler http.Handler, provider *oidcTestProvider, target string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := serveOIDC(handler, http.MethodGet, target, nil)
+	require.Equal(t, http.StatusFound, recorder.Code)
+
+	authURL := recorder.Header().Get("Location")
+	code := provider.authorize(t, authURL)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "http://foo.com/oauth2/callback", u.Query().Get("redirect_uri"))
+
+	callback := "http://foo.com/oauth2/callback?" + url.Values{"code": {code}, "state": {u.Query().Get("state")}}.Encode()
+	return serveOIDC(handler, http.MethodGet, callback, recorder.Result().Cookies())
+}
+
+func TestOIDCAuth(t *testing.T) {
+	provider := newOIDCTestProvider(t, map[string]interface{}{"email": "user@example.com", "groups": []string{"dev"}})
+
+	var forwarded *http.Request
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req
+	})
+
+	handler := newTestOIDC(t, provider, dynamic.OIDC{
+		ClaimsHeaders:      map[string]string{"X-Email": "email", "X-Groups": "groups"},
+		ForwardAccessToken: true,
+	}, next)
+
+	recorder := loginWithOIDC(t, handler, provider, "http://foo.com/bar?baz=qux")
+	require.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, "/bar?baz=qux", recorder.Header().Get("Location"))
+	assert.Nil(t, forwarded)
+
+	cookies := []*http.Cookie{{Name: "other", Value: "foo"}}
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.MaxAge > 0 {
+			cookies = append(cookies, cookie)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://foo.com/bar", nil)
+	req.Header.Set("X-Email", "admin@example.com")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "user@example.com", forwarded.Header.Get("X-Email"))
+	assert.Equal(t, "dev", forwarded.Header.Get("X-Groups"))
+	assert.Equal(t, "Bearer access-token", forwarded.Header.Get("Authorization"))
+	assert.Equal(t, "other=foo", forwarded.Header.Get("Cookie"))
+}
+
+func TestOIDCAuth_redirect(t *testing.T) {
+	provider := newOIDCTestProvider(t, nil)
+	handler := newTestOIDC(t, provider, dynamic.OIDC{}, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	testCases := []struct {
+		desc     string
+		target   string
+		expected string
+	}{
+		{
+			desc:     "local path",
+			target:   "http://foo.com/bar/baz?qux=1",
+			expected: "/bar/baz?qux=1",
+		},
+		{
+			desc:     "escaped path",
+			target:   "http://foo.com/bar%2Fbaz%20qux",
+			expected: "/bar%2Fbaz%20qux",
+		},
+		{
+			desc:     "double slash",
+			target:   "http://foo.com//evil.com/bar?qux=1",
+			expected: "/?qux=1",
+		},
+		{
+			desc:     "backslash",
+			target:   "http://foo.com/\\evil.com/bar",
+			expected: "/%5Cevil.com/bar",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			recorder := loginWithOIDC(t, handler, provider, test.target)
+			require.Equal(t, http.StatusFound, recorder.Code)
+			assert.Equal(t, test.expected, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestIsLocalPath(t *testing.T) {
+	assert.True(t, isLocalPath("/"))
+	assert.True(t, isLocalPath("/foo//bar"))
+	assert.False(t, isLocalPath(""))
+	assert.False(t, isLocalPath("foo"))
+	assert.False(t, isLocalPath("//evil.com"))
+	assert.False(t, isLocalPath("/\\evil.com"))
+	assert.False(t, isLocalPath("https://evil.com"))
+}
+
+func TestOIDCAuth_unauthenticated(t *testing.T) {
+	provider := newOIDCTestProvider(t, nil)
+	handler := newTestOIDC(t, provider, dynamic.OIDC{}, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	recorder := serveOIDC(handler, http.MethodPost, "http://foo.com/bar", nil)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = serveOIDC(handler, http.MethodGet, "http://foo.com/bar", []*http.Cookie{{Name: "_traefik_oidc", Value: "foo"}})
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.True(t, strings.HasPrefix(recorder.Header().Get("Location"), provider.URL+"/authorize?"))
+}
+
+func TestOIDCAuth_callback(t *testing.T) {
+	provider := newOIDCTestProvider(t, nil)
+	handler := newTestOIDC(t, provider, dynamic.OIDC{}, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	recorder := serveOIDC(handler, http.MethodGet, "http://foo.com/", nil)
+	require.Equal(t, http.StatusFound, recorder.Code)
+	loginCookies := recorder.Result().Cookies()
+
+	u, err := url.Parse(recorder.Header().Get("Location"))
+	require.NoError(t, err)
+	state := u.Query().Get("state")
+
+	testCases := []struct {
+		desc    string
+		query   url.Values
+		cookies []*http.Cookie
+	}{
+		{
+			desc:  "no login cookie",
+			query: url.Values{"code": {state + "-code"}, "state": {state}},
+		},
+		{
+			desc:    "state mismatch",
+			query:   url.Values{"code": {state + "-code"}, "state": {"foo"}},
+			cookies: loginCookies,
+		},
+		{
+			desc:    "provider error",
+			query:   url.Values{"error": {"access_denied"}, "state": {state}},
+			cookies: loginCookies,
+		},
+		{
+			desc:    "unknown code",
+			query:   url.Values{"code": {"foo"}, "state": {state}},
+			cookies: loginCookies,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			recorder := serveOIDC(handler, http.MethodGet, "http://foo.com/oauth2/callback?"+test.query.Encode(), test.cookies)
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		})
+	}
+}
+
+func TestOIDCAuth_authorization(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		claims         map[string]interface{}
+		config         dynamic.OIDC
+		expectedStatus int
+	}{
+		{
+			desc:           "allowed email domain",
+			claims:         map[string]interface{}{"email": "user@Example.com", "email_verified": true},
+			config:         dynamic.OIDC{AllowedEmailDomains: []string{"example.org", "example.com"}},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "email domain not allowed",
+			claims:         map[string]interface{}{"email": "user@example.net"},
+			config:         dynamic.OIDC{AllowedEmailDomains: []string{"example.com"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "email not verified",
+			claims:         map[string]interface{}{"email": "user@example.com", "email_verified": false},
+			config:         dynamic.OIDC{AllowedEmailDomains: []string{"example.com"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "allowed group",
+			claims:         map[string]interface{}{"groups": []string{"dev", "ops"}},
+			config:         dynamic.OIDC{AllowedGroups: []string{"admin", "ops"}},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "group not allowed",
+			claims:         map[string]interface{}{"groups": []string{"dev"}},
+			config:         dynamic.OIDC{AllowedGroups: []string{"admin", "ops"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "custom groups claim",
+			claims:         map[string]interface{}{"realm_access": map[string]interface{}{"roles": []string{"admin"}}},
+			config:         dynamic.OIDC{AllowedGroups: []string{"admin"}, GroupsClaim: "realm_access.roles"},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "required claim",
+			claims:         map[string]interface{}{"tenant": "acme"},
+			config:         dynamic.OIDC{RequiredClaims: map[string]string{"tenant": "acme"}},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "mismatching claim",
+			claims:         map[string]interface{}{"tenant": "other"},
+			config:         dynamic.OIDC{RequiredClaims: map[string]string{"tenant": "acme"}},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			provider := newOIDCTestProvider(t, test.claims)
+			handler := newTestOIDC(t, provider, test.config, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			recorder := loginWithOIDC(t, handler, provider, "http://foo.com/")
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestOIDCAuth_session(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		session           oidcSession
+		expectedStatus    int
+		expectedRefreshes int
+	}{
+		{
+			desc:           "valid session",
+			session:        oidcSession{Expiry: time.Now().Add(time.Hour), CreatedAt: time.Now()},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:              "expired tokens",
+			session:           oidcSession{RefreshToken: "refresh-token", Expiry: time.Now().Add(-time.Minute), CreatedAt: time.Now()},
+			expectedStatus:    http.StatusOK,
+			expectedRefreshes: 1,
+		},
+		{
+			desc:              "expired tokens with revoked refresh token",
+			session:           oidcSession{RefreshToken: "revoked", Expiry: time.Now().Add(-time.Minute), CreatedAt: time.Now()},
+			expectedStatus:    http.StatusFound,
+			expectedRefreshes: 0,
+		},
+		{
+			desc:           "expired tokens without refresh token",
+			session:        oidcSession{Expiry: time.Now().Add(-time.Minute), CreatedAt: time.Now()},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			desc:           "expired session",
+			session:        oidcSession{RefreshToken: "refresh-token", Expiry: time.Now().Add(time.Hour), CreatedAt: time.Now().Add(-25 * time.Hour)},
+			expectedStatus: http.StatusFound,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			provider := newOIDCTestProvider(t, map[string]interface{}{"email": "user@example.com"})
+			handler := newTestOIDC(t, provider, dynamic.OIDC{}, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+			session := test.session
+			session.Claims = map[string]interface{}{"sub": "user"}
+
+			recorder := httptest.NewRecorder()
+			require.NoError(t, handler.cookies.write(recorder, httptest.NewRequest(http.MethodGet, "/", nil), handler.cookies.name, session, time.Hour))
+
+			recorder = serveOIDC(handler, http.MethodGet, "http://foo.com/", recorder.Result().Cookies())
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedRefreshes, provider.refreshes)
+
+			if test.expectedRefreshes > 0 {
+				var refreshed oidcSession
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				for _, cookie := range recorder.Result().Cookies() {
+					req.AddCookie(cookie)
+				}
+				require.NoError(t, handler.cookies.read(req, handler.cookies.name, &refreshed))
+
+				assert.Equal(t, "user@example.com", refreshed.Claims["email"])
+				assert.True(t, refreshed.Expiry.After(time.Now()))
+				assert.WithinDuration(t, session.CreatedAt, refreshed.CreatedAt, time.Second)
+			}
+		})
+	}
+}
+
+func TestOIDCAuth_logout(t *testing.T) {
+	provider := newOIDCTestProvider(t, nil)
+	handler := newTestOIDC(t, provider, dynamic.OIDC{LogoutPath: "/logout"}, http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	recorder := serveOIDC(handler, http.MethodGet, "http://foo.com/logout", []*http.Cookie{{Name: "_traefik_oidc", Value: "foo"}})
+	assert.Equal(t, http.StatusFound, recorder.Code)
+	assert.Equal(t, provider.URL+"/logout?client_id=client", recorder.Header().Get("Location"))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "_traefik_oidc", cookies[0].Name)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+}
+
+func TestOIDCCookies(t *testing.T) {
+	cookies, err := newOIDCCookies("session", "", "/", "secret")
+	require.NoError(t, err)
+
+	value := map[string]string{"foo": strings.Repeat("a", 3*maxCookieValueSize)}
+
+	recorder := httptest.NewRecorder()
+	require.NoError(t, cookies.write(recorder, httptest.NewRequest(http.MethodGet, "/", nil), "session", value, time.Hour))
+
+	written := recorder.Result().Cookies()
+	require.Greater(t, len(written), 3)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range written {
+		assert.True(t, cookies.isOwn(cookie.Name))
+		req.AddCookie(cookie)
+	}
+
+	var read map[string]string
+	require.NoError(t, cookies.read(req, "session", &read))
+	assert.Equal(t, value, read)
+
+	// A cookie cannot be read as another one.
+	assert.Error(t, cookies.read(req, "other", &read))
+	req.AddCookie(&http.Cookie{Name: "other", Value: written[0].Value})
+	assert.Error(t, cookies.read(req, "other", &read))
+
+	// The chunks of the previous value are removed.
+	recorder = httptest.NewRecorder()
+	require.NoError(t, cookies.write(recorder, req, "session", map[string]string{"foo": "bar"}, time.Hour))
+
+	rewritten := recorder.Result().Cookies()
+	require.Equal(t, len(written), len(rewritten))
+	assert.Equal(t, "session", rewritten[0].Name)
+	for _, cookie := range rewritten[1:] {
+		assert.Equal(t, -1, cookie.MaxAge)
+	}
+
+	assert.True(t, cookies.isOwn("session_login"))
+	assert.True(t, cookies.isOwn("session_login_1"))
+	assert.False(t, cookies.isOwn("session_foo"))
+	assert.False(t, cookies.isOwn("session_login_foo"))
+	assert.False(t, cookies.isOwn("sessions"))
+}
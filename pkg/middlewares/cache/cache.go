@@ -0,0 +1,395 @@
+// Package cache implements a middleware storing the responses of the services,
+// and serving them back as long as the HTTP caching rules (RFC 7234) allow it, as a shared cache.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const (
+	typeName = "Cache"
+
+	methodPurge = "PURGE"
+)
+
+// Cache statuses, reported in the access logs and the metrics.
+const (
+	// StatusHit is the status of the requests served from a fresh stored response.
+	StatusHit = "HIT"
+	// StatusMiss is the status of the requests forwarded to the service, as no stored response could be used.
+	StatusMiss = "MISS"
+	// StatusStale is the status of the requests served from a stale stored response, which is revalidated in the background.
+	StatusStale = "STALE"
+	// StatusRevalidated is the status of the requests served from a stored response the service confirmed as still valid.
+	StatusRevalidated = "REVALIDATED"
+	// StatusBypass is the status of the requests the cache does not apply to.
+	StatusBypass = "BYPASS"
+)
+
+type cache struct {
+	name            string
+	next            http.Handler
+	store           *Store
+	maxEntrySize    int64
+	purgeChecker    *ip.Checker
+	purgeStrategy   ip.Strategy
+	requestsCounter metrics.Counter
+}
+
+func defaultConfig() dynamic.Cache {
+	config := dynamic.Cache{}
+	config.SetDefaults()
+	return config
+}
+
+// New creates a cache middleware.
+// The responses are kept in the store of the middleware among the given stores, or in a store of its own if stores is nil.
+// requestsCounter, if not nil, counts the requests by cache status.
+func New(ctx context.Context, next http.Handler, config dynamic.Cache, stores *Stores, requestsCounter metrics.Counter, name string) (http.Handler, error) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(ctx, name, typeName))
+	logger.Debug("Creating middleware")
+
+	if config.MaxEntrySize <= 0 {
+		config.MaxEntrySize = defaultConfig().MaxEntrySize
+	}
+
+	var store *Store
+	var err error
+	if stores != nil {
+		store, err = stores.get(name, config)
+	} else {
+		store, err = NewStore(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &cache{
+		name:            name,
+		next:            next,
+		store:           store,
+		maxEntrySize:    config.MaxEntrySize,
+		requestsCounter: requestsCounter,
+	}
+
+	if config.Purge != nil {
+		if len(config.Purge.SourceRange) == 0 {
+			return nil, fmt.Errorf("purge sourceRange is empty")
+		}
+
+		c.purgeChecker, err = ip.NewChecker(config.Purge.SourceRange)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDR purge sourceRange %s: %w", config.Purge.SourceRange, err)
+		}
+
+		c.purgeStrategy, err = config.Purge.IPStrategy.Get()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *cache) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *cache) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+	case methodPurge:
+		if c.purgeChecker != nil {
+			c.purge(rw, req)
+			return
+		}
+		c.serveUnsafe(rw, req)
+		return
+	case http.MethodOptions, http.MethodTrace, http.MethodConnect:
+		c.report(req, StatusBypass)
+		c.next.ServeHTTP(rw, req)
+		return
+	default:
+		c.serveUnsafe(rw, req)
+		return
+	}
+
+	// The ranges and the protocol upgrades are left to the service.
+	if req.Header.Get("Range") != "" || req.Header.Get("Upgrade") != "" {
+		c.report(req, StatusBypass)
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	reqCC := requestCacheControl(req)
+
+	stored := c.store.get(req)
+	if stored == nil {
+		if reqCC.has("only-if-cached") {
+			c.report(req, StatusMiss)
+			rw.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+
+		c.report(req, StatusMiss)
+		if req.Method == http.MethodHead {
+			c.next.ServeHTTP(rw, req)
+			return
+		}
+		c.fetch(rw, req, nil)
+		return
+	}
+
+	now := time.Now()
+	age := stored.age(now)
+	lifetime := stored.freshnessLifetime()
+	respCC := parseCacheControl(stored.Header)
+
+	noCache := reqCC.has("no-cache") || respCC.has("no-cache")
+
+	freshness := lifetime
+	maxAge, hasMaxAge := reqCC.duration("max-age")
+	if hasMaxAge && maxAge < freshness {
+		freshness = maxAge
+	}
+	minFresh, hasMinFresh := reqCC.duration("min-fresh")
+
+	if !noCache && age+minFresh < freshness {
+		c.report(req, StatusHit)
+		c.serve(rw, req, stored, now)
+		return
+	}
+
+	// Shared caches cannot serve stale responses with an s-maxage directive.
+	mayServeStale := !noCache && !respCC.has("must-revalidate") && !respCC.has("proxy-revalidate") && !respCC.has("s-maxage")
+	if mayServeStale {
+		if maxStale, ok := reqCC["max-stale"]; ok {
+			staleness := age + minFresh - freshness
+			if d, valid := reqCC.duration("max-stale"); maxStale == "" || valid && staleness <= d {
+				c.report(req, StatusHit)
+				c.serve(rw, req, stored, now)
+				return
+			}
+		}
+
+		// The stale responses are only served to the requests not asking for a fresher one.
+		if swr, ok := respCC.duration("stale-while-revalidate"); ok && !hasMaxAge && !hasMinFresh && age-lifetime <= swr {
+			c.report(req, StatusStale)
+			c.revalidateInBackground(req, stored)
+			c.serve(rw, req, stored, now)
+			return
+		}
+	}
+
+	if reqCC.has("only-if-cached") {
+		c.report(req, StatusMiss)
+		rw.WriteHeader(http.StatusGatewayTimeout)
+		return
+	}
+
+	if !stored.hasValidators() {
+		c.report(req, StatusMiss)
+		c.fetch(rw, req, nil)
+		return
+	}
+
+	if refreshed := c.fetch(rw, req, stored); refreshed != nil {
+		c.report(req, StatusRevalidated)
+		c.serve(rw, req, refreshed, time.Now())
+		return
+	}
+	c.report(req, StatusMiss)
+}
+
+// fetch forwards the request to the next handler, with the validators of the given stored response if any, and stores the response when allowed.
+// If the next handler confirms the stored response is still valid, the refreshed stored response is returned instead of being written,
+// otherwise the response is written to rw, unless it is nil.
+func (c *cache) fetch(rw http.ResponseWriter, req *http.Request, stored *entry) *entry {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName))
+
+	outReq := req.Clone(req.Context())
+	outReq.Method = http.MethodGet
+	outReq.Header.Del("If-None-Match")
+	outReq.Header.Del("If-Modified-Since")
+	outReq.Header.Del("If-Match")
+	outReq.Header.Del("If-Unmodified-Since")
+	if stored != nil {
+		if etag := stored.Header.Get("ETag"); etag != "" {
+			outReq.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := stored.Header.Get("Last-Modified"); lastModified != "" {
+			outReq.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	recorder := newResponseRecorder(rw, req, c.maxEntrySize, stored != nil)
+
+	requestTime := time.Now()
+	c.next.ServeHTTP(recorder, outReq)
+	responseTime := time.Now()
+
+	if !recorder.wroteHeader {
+		recorder.WriteHeader(http.StatusOK)
+	}
+
+	if stored != nil && recorder.status == http.StatusNotModified {
+		refreshed := stored.refreshed(recorder.header, requestTime, responseTime)
+		if err := c.store.set(refreshed); err != nil {
+			logger.Errorf("Unable to store response: %v", err)
+		}
+		return refreshed
+	}
+
+	if !c.storable(req, recorder) {
+		return nil
+	}
+
+	vary, _ := varyNames(recorder.header)
+	primaryKey := primaryKey(req)
+
+	e := &entry{
+		Key:          variantKey(primaryKey, vary, req),
+		PrimaryKey:   primaryKey,
+		Vary:         vary,
+		Status:       recorder.status,
+		Header:       recorder.header.Clone(),
+		Body:         recorder.body.Bytes(),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+
+	if err := c.store.set(e); err != nil {
+		logger.Errorf("Unable to store response: %v", err)
+	}
+
+	return nil
+}
+
+// storable returns whether the response to the given request can be stored (RFC 7234 section 3).
+func (c *cache) storable(req *http.Request, recorder *responseRecorder) bool {
+	if recorder.tooLarge || !storableStatus(recorder.status) {
+		return false
+	}
+
+	if requestCacheControl(req).has("no-store") {
+		return false
+	}
+
+	respCC := parseCacheControl(recorder.header)
+	if respCC.has("no-store") || respCC.has("private") {
+		return false
+	}
+
+	// The responses to authenticated requests are only shared when explicitly allowed.
+	if req.Header.Get("Authorization") != "" && !respCC.has("public") && !respCC.has("s-maxage") && !respCC.has("must-revalidate") {
+		return false
+	}
+
+	// The cookies are not shared between clients.
+	if recorder.header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	if _, ok := varyNames(recorder.header); !ok {
+		return false
+	}
+
+	e := &entry{Status: recorder.status, Header: recorder.header}
+	return e.freshnessLifetime() > 0 || e.hasValidators()
+}
+
+// serve writes the given stored response.
+func (c *cache) serve(rw http.ResponseWriter, req *http.Request, e *entry, now time.Time) {
+	header := rw.Header()
+	for name, values := range e.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+
+	if e.Status == http.StatusOK && notModified(req, e.Header) {
+		header.Del("Content-Length")
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rw.WriteHeader(e.Status)
+	if req.Method != http.MethodHead {
+		_, _ = rw.Write(e.Body)
+	}
+}
+
+// revalidateInBackground revalidates the given stored response, unless it is already being revalidated.
+func (c *cache) revalidateInBackground(req *http.Request, stored *entry) {
+	if !c.store.startRevalidation(stored.Key) {
+		return
+	}
+
+	outReq := req.Clone(context.Background())
+	outReq.Body = http.NoBody
+	outReq.ContentLength = 0
+
+	safe.Go(func() {
+		defer c.store.endRevalidation(stored.Key)
+
+		c.fetch(nil, outReq, stored)
+	})
+}
+
+// serveUnsafe forwards a request with an unsafe method, and invalidates the stored responses to its URI if it succeeds
+// (RFC 7234 section 4.4).
+func (c *cache) serveUnsafe(rw http.ResponseWriter, req *http.Request) {
+	c.report(req, StatusBypass)
+
+	recorder := &statusRecorder{ResponseWriter: rw, status: http.StatusOK}
+	c.next.ServeHTTP(recorder, req)
+
+	if recorder.status < http.StatusBadRequest {
+		c.store.Purge(primaryKey(req))
+	}
+}
+
+// purge removes the stored responses to the URI of the request, if it comes from an allowed IP.
+func (c *cache) purge(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), c.name, typeName))
+
+	if err := c.purgeChecker.IsAuthorized(c.purgeStrategy.GetIP(req)); err != nil {
+		logMessage := fmt.Sprintf("rejecting purge request %+v: %v", req, err)
+		logger.Debug(logMessage)
+		tracing.SetErrorWithEvent(req, logMessage)
+		rw.WriteHeader(http.StatusForbidden)
+		_, _ = rw.Write([]byte(http.StatusText(http.StatusForbidden)))
+		return
+	}
+
+	if !c.store.Purge(primaryKey(req)) {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logger.Debugf("Purged %s", primaryKey(req))
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (c *cache) report(req *http.Request, status string) {
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.Core[accesslog.CacheStatus] = status
+	}
+
+	if c.requestsCounter != nil {
+		c.requestsCounter.With("middleware", c.name, "status", status).Add(1)
+	}
+}
@@ -0,0 +1,371 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	method         string
+	header         map[string]string
+	remoteAddr     string
+	expectedStatus int
+	expectedCache  string
+	expectedBody   string
+	expectedCalls  int32
+}
+
+func TestCache_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   dynamic.Cache
+		header   map[string]string
+		status   int
+		requests []request
+	}{
+		{
+			desc:   "fresh response",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 1},
+				{method: http.MethodHead, expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedCalls: 1},
+			},
+		},
+		{
+			desc:   "no-store response",
+			header: map[string]string{"Cache-Control": "no-store, max-age=60"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "private response",
+			header: map[string]string{"Cache-Control": "private, max-age=60"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "response with cookie",
+			header: map[string]string{"Cache-Control": "max-age=60", "Set-Cookie": "foo=bar"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "response to an authenticated request",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{header: map[string]string{"Authorization": "Bearer foo"}, expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "public response to an authenticated request",
+			header: map[string]string{"Cache-Control": "public, max-age=60"},
+			requests: []request{
+				{header: map[string]string{"Authorization": "Bearer foo"}, expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 1},
+			},
+		},
+		{
+			desc:   "response too large",
+			config: dynamic.Cache{MaxEntrySize: 1},
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{header: map[string]string{"Cache-Control": "no-store"}, expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "333", expectedCalls: 3},
+			},
+		},
+		{
+			desc:   "request with no-cache",
+			header: map[string]string{"Cache-Control": "max-age=60", "ETag": `"foo"`},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{header: map[string]string{"Cache-Control": "no-cache"}, expectedStatus: http.StatusOK, expectedCache: StatusRevalidated, expectedBody: "1", expectedCalls: 2},
+				{header: map[string]string{"Pragma": "no-cache"}, expectedStatus: http.StatusOK, expectedCache: StatusRevalidated, expectedBody: "1", expectedCalls: 3},
+			},
+		},
+		{
+			desc:   "request with max-age",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{header: map[string]string{"Cache-Control": "max-age=0"}, expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "request with no-store",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{header: map[string]string{"Cache-Control": "no-store"}, expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "request with only-if-cached",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{header: map[string]string{"Cache-Control": "only-if-cached"}, expectedStatus: http.StatusGatewayTimeout, expectedCache: StatusMiss, expectedCalls: 0},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{header: map[string]string{"Cache-Control": "only-if-cached"}, expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 1},
+			},
+		},
+		{
+			desc:   "stale response without validators",
+			header: map[string]string{"Cache-Control": "max-age=0"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "stale response with validators",
+			header: map[string]string{"Cache-Control": "max-age=0", "ETag": `"foo"`},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusRevalidated, expectedBody: "1", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "stale response with max-stale request",
+			header: map[string]string{"Cache-Control": "max-age=0", "ETag": `"foo"`},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{header: map[string]string{"Cache-Control": "max-stale"}, expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 1},
+			},
+		},
+		{
+			desc:   "stale response with must-revalidate and max-stale request",
+			header: map[string]string{"Cache-Control": "max-age=0, must-revalidate", "ETag": `"foo"`},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{header: map[string]string{"Cache-Control": "max-stale"}, expectedStatus: http.StatusOK, expectedCache: StatusRevalidated, expectedBody: "1", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "conditional request on a stored response",
+			header: map[string]string{"Cache-Control": "max-age=60", "ETag": `"foo"`},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{header: map[string]string{"If-None-Match": `"foo"`}, expectedStatus: http.StatusNotModified, expectedCache: StatusHit, expectedCalls: 1},
+				{header: map[string]string{"If-None-Match": `"bar"`}, expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 1},
+			},
+		},
+		{
+			desc:   "conditional request on a forwarded response",
+			header: map[string]string{"Cache-Control": "max-age=60", "ETag": `"foo"`},
+			requests: []request{
+				{header: map[string]string{"If-None-Match": `W/"foo"`}, expectedStatus: http.StatusNotModified, expectedCache: StatusMiss, expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 1},
+			},
+		},
+		{
+			desc:   "response varying on a request header",
+			header: map[string]string{"Cache-Control": "max-age=60", "Vary": "Accept-Language"},
+			requests: []request{
+				{header: map[string]string{"Accept-Language": "en"}, expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{header: map[string]string{"Accept-Language": "fr"}, expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+				{header: map[string]string{"Accept-Language": "en"}, expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 2},
+				{header: map[string]string{"Accept-Language": "fr"}, expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "response varying on everything",
+			header: map[string]string{"Cache-Control": "max-age=60", "Vary": "*"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "server error",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			status: http.StatusInternalServerError,
+			requests: []request{
+				{expectedStatus: http.StatusInternalServerError, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusInternalServerError, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "invalidation by an unsafe request",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{method: http.MethodPost, expectedStatus: http.StatusOK, expectedCache: StatusBypass, expectedBody: "22", expectedCalls: 2},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "333", expectedCalls: 3},
+			},
+		},
+		{
+			desc:   "range request",
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{header: map[string]string{"Range": "bytes=0-1"}, expectedStatus: http.StatusOK, expectedCache: StatusBypass, expectedBody: "1", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+		{
+			desc:   "purge request",
+			config: dynamic.Cache{Purge: &dynamic.CachePurge{SourceRange: []string{"10.0.0.1"}}},
+			header: map[string]string{"Cache-Control": "max-age=60"},
+			requests: []request{
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "1", expectedCalls: 1},
+				{method: methodPurge, remoteAddr: "10.0.0.2:1234", expectedStatus: http.StatusForbidden, expectedBody: "Forbidden", expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusHit, expectedBody: "1", expectedCalls: 1},
+				{method: methodPurge, remoteAddr: "10.0.0.1:1234", expectedStatus: http.StatusOK, expectedCalls: 1},
+				{method: methodPurge, remoteAddr: "10.0.0.1:1234", expectedStatus: http.StatusNotFound, expectedCalls: 1},
+				{expectedStatus: http.StatusOK, expectedCache: StatusMiss, expectedBody: "22", expectedCalls: 2},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				call := atomic.AddInt32(&calls, 1)
+
+				for name, value := range test.header {
+					rw.Header().Set(name, value)
+				}
+
+				if etag := test.header["ETag"]; etag != "" && req.Header.Get("If-None-Match") == etag {
+					rw.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				status := test.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				rw.WriteHeader(status)
+
+				// The body changes at each call, and its size is the number of the call.
+				for i := int32(0); i < call; i++ {
+					_, _ = rw.Write([]byte(strconv.Itoa(int(call))))
+				}
+			})
+
+			handler, err := New(context.Background(), next, test.config, nil, nil, "cache")
+			require.NoError(t, err)
+
+			for i, r := range test.requests {
+				method := r.method
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				req := httptest.NewRequest(method, "http://foo.com/bar?baz=qux", nil)
+				if r.remoteAddr != "" {
+					req.RemoteAddr = r.remoteAddr
+				}
+				for name, value := range r.header {
+					req.Header.Set(name, value)
+				}
+
+				logData := &accesslog.LogData{Core: make(accesslog.CoreLogData)}
+				req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+
+				assert.Equal(t, r.expectedStatus, recorder.Code, "request %d", i)
+				assert.Equal(t, r.expectedBody, recorder.Body.String(), "request %d", i)
+				assert.Equal(t, r.expectedCalls, atomic.LoadInt32(&calls), "request %d", i)
+
+				if r.expectedCache != "" {
+					assert.Equal(t, r.expectedCache, logData.Core[accesslog.CacheStatus], "request %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestCache_staleWhileRevalidate(t *testing.T) {
+	revalidated := make(chan struct{})
+
+	var calls int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		call := atomic.AddInt32(&calls, 1)
+
+		rw.Header().Set("Cache-Control", "max-age=0, stale-while-revalidate=60")
+		rw.Header().Set("ETag", `"`+strconv.Itoa(int(call))+`"`)
+		_, _ = rw.Write([]byte(strconv.Itoa(int(call))))
+
+		if call == 2 {
+			close(revalidated)
+		}
+	})
+
+	handler, err := New(context.Background(), next, dynamic.Cache{}, nil, nil, "cache")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+	assert.Equal(t, "1", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+	assert.Equal(t, "1", recorder.Body.String())
+
+	select {
+	case <-revalidated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The response has not been revalidated")
+	}
+
+	// The background revalidation stores the new response.
+	assert.Eventually(t, func() bool {
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+		return recorder.Body.String() != "1"
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestStores(t *testing.T) {
+	stores := NewStores()
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	stores.NewGeneration()
+	handler, err := New(context.Background(), next, dynamic.Cache{}, stores, nil, "cache")
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.com/", nil))
+
+	// The responses are kept across configuration reloads.
+	stores.NewGeneration()
+	handler, err = New(context.Background(), next, dynamic.Cache{}, stores, nil, "cache")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+	assert.NotNil(t, handler.(*cache).store.get(req))
+
+	// The responses are dropped when the storage settings change.
+	stores.NewGeneration()
+	handler, err = New(context.Background(), next, dynamic.Cache{MaxSize: 1024}, stores, nil, "cache")
+	require.NoError(t, err)
+
+	assert.Nil(t, handler.(*cache).store.get(req))
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDeltaSeconds is the greatest delta-seconds value taken into account (RFC 7234 section 1.2.1).
+const maxDeltaSeconds = 2147483648
+
+// maxHeuristicFreshness caps the freshness lifetime computed from the Last-Modified header of a response.
+const maxHeuristicFreshness = 24 * time.Hour
+
+// cacheControl holds the directives of the Cache-Control headers of a request or a response, by lowercase name.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	cc := make(cacheControl)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+
+			name, arg := directive, ""
+			if i := strings.IndexByte(directive, '='); i >= 0 {
+				name, arg = directive[:i], strings.Trim(strings.TrimSpace(directive[i+1:]), `"`)
+			}
+			cc[strings.ToLower(strings.TrimSpace(name))] = arg
+		}
+	}
+	return cc
+}
+
+func (c cacheControl) has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+// duration returns the value of the given delta-seconds directive, if it is valid.
+func (c cacheControl) duration(name string) (time.Duration, bool) {
+	value, ok := c[name]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	if seconds > maxDeltaSeconds {
+		seconds = maxDeltaSeconds
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// requestCacheControl returns the Cache-Control directives of the given request,
+// where a Pragma: no-cache header stands for the no-cache directive when there is no Cache-Control header.
+func requestCacheControl(req *http.Request) cacheControl {
+	cc := parseCacheControl(req.Header)
+	if len(req.Header.Values("Cache-Control")) == 0 && strings.EqualFold(strings.TrimSpace(req.Header.Get("Pragma")), "no-cache") {
+		cc["no-cache"] = ""
+	}
+	return cc
+}
+
+// heuristicallyCacheable returns whether a response with the given status code can be given a heuristic freshness lifetime
+// (RFC 7231 section 6.1), except for 206 as the ranges are not cached.
+func heuristicallyCacheable(status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMultipleChoices, http.StatusMovedPermanently, http.StatusPermanentRedirect,
+		http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusGone, http.StatusRequestURITooLong,
+		http.StatusNotImplemented:
+		return true
+	default:
+		return false
+	}
+}
+
+// storableStatus returns whether a response with the given status code can be stored,
+// which requires an explicit freshness lifetime for the ones that cannot be given a heuristic one.
+func storableStatus(status int) bool {
+	return heuristicallyCacheable(status) || status == http.StatusFound || status == http.StatusTemporaryRedirect
+}
+
+// varyNames returns the canonical names of the request headers listed by the Vary headers of the given response,
+// and false if the response varies on something else than request headers.
+func varyNames(header http.Header) ([]string, bool) {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, false
+			}
+
+			name = http.CanonicalHeaderKey(name)
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	return names, true
+}
+
+// notModified returns whether the conditional headers of the given request match the validators of a response with the given headers,
+// in which case the response can be replaced by a 304 (Not Modified) one (RFC 7232 section 6).
+func notModified(req *http.Request, header http.Header) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		etag := header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETag(candidate) == weakETag(etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(ims)
+}
+
+// weakETag returns the opaque tag of the given entity-tag, for a weak comparison.
+func weakETag(etag string) string {
+	return strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	entryFileSuffix = ".entry"
+	// tmpFileSuffix ends the names of the files being written, which are renamed once complete.
+	tmpFileSuffix = entryFileSuffix + ".tmp"
+)
+
+// entryFileName matches the names of the files of the responses, and of the files being written.
+// The other files of the directory are left untouched.
+var entryFileName = regexp.MustCompile(`^(?:[0-9a-f]{64}\.entry|[0-9]+\.entry\.tmp)$`)
+
+// diskStore keeps responses in the files of a directory, named after the hash of their key.
+// Its index is guarded by the mutex of the store it belongs to.
+type diskStore struct {
+	path  string
+	index *lru
+}
+
+// newDiskStore creates a disk store in the given directory,
+// and returns the responses already there, without their body, from the least to the most recently stored.
+// The files left over by an interrupted write, or which cannot be read, are removed,
+// but only the files named like the ones of the store are considered.
+func newDiskStore(path string, maxSize int64) (*diskStore, []*entry, error) {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, nil, fmt.Errorf("unable to create cache directory %s: %w", path, err)
+	}
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read cache directory %s: %w", path, err)
+	}
+
+	d := &diskStore{path: path, index: newLRU(maxSize)}
+
+	type storedEntry struct {
+		entry   *entry
+		size    int64
+		modTime time.Time
+	}
+
+	var stored []storedEntry
+	for _, file := range files {
+		if !file.Mode().IsRegular() || !entryFileName.MatchString(file.Name()) {
+			continue
+		}
+
+		name := filepath.Join(path, file.Name())
+		if strings.HasSuffix(file.Name(), tmpFileSuffix) {
+			_ = os.Remove(name)
+			continue
+		}
+
+		e, err := readEntry(name)
+		if err != nil || d.file(e.Key) != name {
+			_ = os.Remove(name)
+			continue
+		}
+
+		e.Body = nil
+		stored = append(stored, storedEntry{entry: e, size: file.Size(), modTime: file.ModTime()})
+	}
+
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].modTime.Before(stored[j].modTime)
+	})
+
+	var entries []*entry
+	for _, s := range stored {
+		for _, evicted := range d.index.add(&lruItem{key: s.entry.Key, primaryKey: s.entry.PrimaryKey, size: s.size}) {
+			d.remove(evicted.key)
+		}
+		entries = append(entries, s.entry)
+	}
+
+	// The directory may have been filled with a greater maximum size.
+	var kept []*entry
+	for _, e := range entries {
+		if _, ok := d.index.items[e.Key]; ok {
+			kept = append(kept, e)
+		}
+	}
+
+	return d, kept, nil
+}
+
+func (d *diskStore) file(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(d.path, hex.EncodeToString(hash[:])+entryFileSuffix)
+}
+
+// write writes the given response in its file, and returns the size of the file.
+func (d *diskStore) write(e *entry) (int64, error) {
+	file, err := ioutil.TempFile(d.path, "*"+tmpFileSuffix)
+	if err != nil {
+		return 0, err
+	}
+
+	err = gob.NewEncoder(file).Encode(e)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return 0, err
+	}
+
+	info, err := os.Stat(file.Name())
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return 0, err
+	}
+
+	// The rename replaces the previous file at once, for the concurrent reads.
+	if err := os.Rename(file.Name(), d.file(e.Key)); err != nil {
+		_ = os.Remove(file.Name())
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+func (d *diskStore) read(key string) (*entry, error) {
+	e, err := readEntry(d.file(key))
+	if err != nil {
+		return nil, err
+	}
+	if e.Key != key {
+		return nil, fmt.Errorf("unexpected response in cache file %s", d.file(key))
+	}
+	return e, nil
+}
+
+func (d *diskStore) remove(key string) {
+	_ = os.Remove(d.file(key))
+}
+
+func readEntry(name string) (*entry, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var e entry
+	if err := gob.NewDecoder(file).Decode(&e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry is a response kept in a store.
+// Its fields are exported to be encoded on disk.
+type entry struct {
+	// Key identifies the response among the variants of the responses to a URI.
+	Key string
+	// PrimaryKey is the URI of the request the response answers.
+	PrimaryKey string
+	// Vary is the list of the request headers selecting the response among its variants.
+	Vary []string
+
+	Status int
+	Header http.Header
+	Body   []byte
+
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+func (e *entry) size() int64 {
+	size := len(e.Key) + len(e.PrimaryKey) + len(e.Body)
+	for name, values := range e.Header {
+		size += len(name)
+		for _, value := range values {
+			size += len(value)
+		}
+	}
+	return int64(size)
+}
+
+// date returns the time the response was generated at.
+func (e *entry) date() time.Time {
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return date
+	}
+	return e.ResponseTime
+}
+
+// age returns the age of the response at the given time (RFC 7234 section 4.2.3).
+func (e *entry) age(now time.Time) time.Duration {
+	var ageValue time.Duration
+	if seconds, err := strconv.ParseInt(strings.TrimSpace(e.Header.Get("Age")), 10, 64); err == nil && seconds > 0 {
+		if seconds > maxDeltaSeconds {
+			seconds = maxDeltaSeconds
+		}
+		ageValue = time.Duration(seconds) * time.Second
+	}
+
+	apparentAge := e.ResponseTime.Sub(e.date())
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+
+	correctedAgeValue := ageValue + e.ResponseTime.Sub(e.RequestTime)
+
+	correctedInitialAge := apparentAge
+	if correctedAgeValue > correctedInitialAge {
+		correctedInitialAge = correctedAgeValue
+	}
+
+	return correctedInitialAge + now.Sub(e.ResponseTime)
+}
+
+// freshnessLifetime returns the duration during which the response can be served without being validated
+// (RFC 7234 section 4.2.1), for a shared cache.
+func (e *entry) freshnessLifetime() time.Duration {
+	cc := parseCacheControl(e.Header)
+	if lifetime, ok := cc.duration("s-maxage"); ok {
+		return lifetime
+	}
+	if lifetime, ok := cc.duration("max-age"); ok {
+		return lifetime
+	}
+
+	if expires := e.Header.Get("Expires"); expires != "" {
+		// An invalid date stands for a time in the past.
+		expiresTime, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+
+		lifetime := expiresTime.Sub(e.date())
+		if lifetime < 0 {
+			return 0
+		}
+		return lifetime
+	}
+
+	if !heuristicallyCacheable(e.Status) {
+		return 0
+	}
+
+	lastModified, err := http.ParseTime(e.Header.Get("Last-Modified"))
+	if err != nil {
+		return 0
+	}
+
+	lifetime := e.date().Sub(lastModified) / 10
+	if lifetime < 0 {
+		return 0
+	}
+	if lifetime > maxHeuristicFreshness {
+		return maxHeuristicFreshness
+	}
+	return lifetime
+}
+
+// hasValidators returns whether the response can be validated with a conditional request.
+func (e *entry) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// refreshed returns a copy of the response, updated with the headers of the 304 (Not Modified) response validating it
+// (RFC 7234 section 4.3.4).
+func (e *entry) refreshed(header http.Header, requestTime, responseTime time.Time) *entry {
+	refreshed := *e
+	refreshed.Header = e.Header.Clone()
+	for name, values := range header {
+		if name == "Content-Length" {
+			continue
+		}
+		refreshed.Header[name] = values
+	}
+	refreshed.RequestTime = requestTime
+	refreshed.ResponseTime = responseTime
+	return &refreshed
+}
+
+// primaryKey returns the key of the responses to the given request, which is its effective URI.
+func primaryKey(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + req.Host + req.URL.RequestURI()
+}
+
+// variantKey returns the key of the response to the given request, among the responses varying on the given request headers.
+func variantKey(primaryKey string, vary []string, req *http.Request) string {
+	if len(vary) == 0 {
+		return primaryKey
+	}
+
+	var key strings.Builder
+	key.WriteString(primaryKey)
+	for _, name := range vary {
+		key.WriteString("\n")
+		key.WriteString(name)
+		key.WriteString(": ")
+		key.WriteString(strings.Join(req.Header.Values(name), ", "))
+	}
+	return key.String()
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntry_freshnessLifetime(t *testing.T) {
+	date := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		status   int
+		header   http.Header
+		expected time.Duration
+	}{
+		{
+			desc:     "no freshness information",
+			header:   http.Header{},
+			expected: 0,
+		},
+		{
+			desc:     "max-age",
+			header:   http.Header{"Cache-Control": []string{"public, max-age=60"}},
+			expected: time.Minute,
+		},
+		{
+			desc:     "s-maxage over max-age",
+			header:   http.Header{"Cache-Control": []string{"max-age=60, s-maxage=120"}},
+			expected: 2 * time.Minute,
+		},
+		{
+			desc:     "invalid max-age",
+			header:   http.Header{"Cache-Control": []string{"max-age=foo"}},
+			expected: 0,
+		},
+		{
+			desc: "expires",
+			header: http.Header{
+				"Date":    []string{date.Format(http.TimeFormat)},
+				"Expires": []string{date.Add(time.Hour).Format(http.TimeFormat)},
+			},
+			expected: time.Hour,
+		},
+		{
+			desc: "max-age over expires",
+			header: http.Header{
+				"Cache-Control": []string{"max-age=60"},
+				"Date":          []string{date.Format(http.TimeFormat)},
+				"Expires":       []string{date.Add(time.Hour).Format(http.TimeFormat)},
+			},
+			expected: time.Minute,
+		},
+		{
+			desc: "invalid expires",
+			header: http.Header{
+				"Expires":       []string{"0"},
+				"Last-Modified": []string{date.Add(-10 * time.Hour).Format(http.TimeFormat)},
+			},
+			expected: 0,
+		},
+		{
+			desc: "heuristic",
+			header: http.Header{
+				"Date":          []string{date.Format(http.TimeFormat)},
+				"Last-Modified": []string{date.Add(-10 * time.Hour).Format(http.TimeFormat)},
+			},
+			expected: time.Hour,
+		},
+		{
+			desc:   "heuristic for a status not cacheable by default",
+			status: http.StatusFound,
+			header: http.Header{
+				"Date":          []string{date.Format(http.TimeFormat)},
+				"Last-Modified": []string{date.Add(-10 * time.Hour).Format(http.TimeFormat)},
+			},
+			expected: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			status := test.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			e := &entry{Status: status, Header: test.header, ResponseTime: date}
+			assert.Equal(t, test.expected, e.freshnessLifetime())
+		})
+	}
+}
+
+func TestEntry_age(t *testing.T) {
+	date := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	e := &entry{
+		Header: http.Header{
+			"Age":  []string{"30"},
+			"Date": []string{date.Format(http.TimeFormat)},
+		},
+		RequestTime:  date.Add(time.Second),
+		ResponseTime: date.Add(2 * time.Second),
+	}
+
+	// The Age header and the response delay add to the time spent in the store.
+	assert.Equal(t, 31*time.Second+time.Minute, e.age(date.Add(2*time.Second+time.Minute)))
+}
@@ -0,0 +1,72 @@
+package cache
+
+import "container/list"
+
+// lru tracks the keys of the responses of a store tier, to evict the least recently used ones when the tier is full.
+type lru struct {
+	maxSize int64
+	size    int64
+	list    *list.List
+	items   map[string]*list.Element
+}
+
+type lruItem struct {
+	key        string
+	primaryKey string
+	size       int64
+	// entry is the response itself, for the memory tier.
+	entry *entry
+}
+
+func newLRU(maxSize int64) *lru {
+	return &lru{
+		maxSize: maxSize,
+		list:    list.New(),
+		items:   make(map[string]*list.Element),
+	}
+}
+
+// get returns the item of the given key, and marks it as the most recently used.
+func (l *lru) get(key string) (*lruItem, bool) {
+	elt, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	l.list.MoveToFront(elt)
+	return elt.Value.(*lruItem), true
+}
+
+// add adds, or replaces, the item of the given key, and returns the items evicted to make room for it.
+// An item bigger than the tier is not added.
+func (l *lru) add(item *lruItem) []*lruItem {
+	l.remove(item.key)
+
+	if item.size > l.maxSize {
+		return nil
+	}
+
+	l.items[item.key] = l.list.PushFront(item)
+	l.size += item.size
+
+	var evicted []*lruItem
+	for l.size > l.maxSize {
+		oldest := l.list.Back().Value.(*lruItem)
+		l.remove(oldest.key)
+		evicted = append(evicted, oldest)
+	}
+	return evicted
+}
+
+// remove removes the item of the given key, and returns whether it was there.
+func (l *lru) remove(key string) bool {
+	elt, ok := l.items[key]
+	if !ok {
+		return false
+	}
+
+	l.list.Remove(elt)
+	delete(l.items, key)
+	l.size -= elt.Value.(*lruItem).size
+	return true
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseRecorder records the response of the next handler to store it, while forwarding it to the client,
+// unless it is the confirmation that the stored response being revalidated is still valid.
+type responseRecorder struct {
+	// rw is nil for the background revalidations.
+	rw  http.ResponseWriter
+	req *http.Request
+
+	header      http.Header
+	status      int
+	wroteHeader bool
+
+	// revalidation tells whether a 304 (Not Modified) response is for the cache, rather than for the client.
+	revalidation bool
+	forward      bool
+	// notModified tells whether a 304 (Not Modified) response was forwarded instead, as the conditions of the client match.
+	notModified bool
+
+	body        bytes.Buffer
+	maxBodySize int64
+	tooLarge    bool
+}
+
+func newResponseRecorder(rw http.ResponseWriter, req *http.Request, maxBodySize int64, revalidation bool) *responseRecorder {
+	return &responseRecorder{
+		rw:           rw,
+		req:          req,
+		header:       make(http.Header),
+		maxBodySize:  maxBodySize,
+		revalidation: revalidation,
+	}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+
+	// The informational responses are not forwarded.
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		return
+	}
+
+	r.wroteHeader = true
+	r.status = status
+	r.forward = r.rw != nil && !(r.revalidation && status == http.StatusNotModified)
+
+	if !r.forward {
+		return
+	}
+
+	header := r.rw.Header()
+	for name, values := range r.header {
+		header[name] = values
+	}
+
+	if status == http.StatusOK && notModified(r.req, r.header) {
+		r.notModified = true
+		header.Del("Content-Length")
+		r.rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	r.rw.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.tooLarge {
+		if int64(r.body.Len()+len(b)) > r.maxBodySize {
+			r.tooLarge = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(b)
+		}
+	}
+
+	if !r.forward || r.notModified {
+		return len(b), nil
+	}
+	return r.rw.Write(b)
+}
+
+// Flush sends any buffered data to the client.
+func (r *responseRecorder) Flush() {
+	if !r.forward || r.notModified {
+		return
+	}
+
+	if f, ok := r.rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// statusRecorder records the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = status >= 200
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Flush sends any buffered data to the client.
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
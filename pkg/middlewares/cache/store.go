@@ -0,0 +1,255 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+// Store holds the responses of a cache, in memory and optionally on disk.
+// The responses are written through to the disk, which keeps them once evicted from memory.
+type Store struct {
+	mu     sync.Mutex
+	memory *lru
+	disk   *diskStore
+	// variants indexes the stored responses by primary key.
+	variants map[string]*variants
+	// revalidations holds the keys of the responses being revalidated in the background.
+	revalidations map[string]struct{}
+}
+
+// variants holds the keys of the stored responses to a URI.
+type variants struct {
+	// vary is the list of the request headers selecting a variant, according to the latest stored response.
+	vary []string
+	keys map[string]struct{}
+}
+
+// NewStore creates a store with the storage settings of the given configuration.
+func NewStore(config dynamic.Cache) (*Store, error) {
+	maxSize := config.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultConfig().MaxSize
+	}
+
+	s := &Store{
+		memory:        newLRU(maxSize),
+		variants:      make(map[string]*variants),
+		revalidations: make(map[string]struct{}),
+	}
+
+	if config.Disk == nil {
+		return s, nil
+	}
+
+	if config.Disk.Path == "" {
+		return nil, fmt.Errorf("no path for the disk store")
+	}
+
+	diskMaxSize := config.Disk.MaxSize
+	if diskMaxSize <= 0 {
+		defaultDisk := dynamic.CacheDisk{}
+		defaultDisk.SetDefaults()
+		diskMaxSize = defaultDisk.MaxSize
+	}
+
+	disk, entries, err := newDiskStore(config.Disk.Path, diskMaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	s.disk = disk
+	for _, e := range entries {
+		s.index(e)
+	}
+
+	return s, nil
+}
+
+// Purge removes all the variants of the responses to the given URI, such as https://example.com/foo?bar=baz,
+// and returns whether there was any.
+func (s *Store) Purge(uri string) bool {
+	s.mu.Lock()
+
+	v, ok := s.variants[uri]
+	if !ok {
+		s.mu.Unlock()
+		return false
+	}
+	delete(s.variants, uri)
+
+	var onDisk []string
+	for key := range v.keys {
+		s.memory.remove(key)
+		if s.disk != nil && s.disk.index.remove(key) {
+			onDisk = append(onDisk, key)
+		}
+	}
+
+	s.mu.Unlock()
+
+	for _, key := range onDisk {
+		s.disk.remove(key)
+	}
+
+	return true
+}
+
+// get returns the stored response to the given request, if any.
+func (s *Store) get(req *http.Request) *entry {
+	primaryKey := primaryKey(req)
+
+	s.mu.Lock()
+
+	v, ok := s.variants[primaryKey]
+	if !ok {
+		s.mu.Unlock()
+		return nil
+	}
+
+	key := variantKey(primaryKey, v.vary, req)
+	if item, ok := s.memory.get(key); ok {
+		s.mu.Unlock()
+		return item.entry
+	}
+
+	if s.disk == nil {
+		s.mu.Unlock()
+		return nil
+	}
+
+	_, ok = s.disk.index.get(key)
+	s.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	e, err := s.disk.read(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.disk.index.items[key]; !ok {
+		// The response was purged while being read.
+		return nil
+	}
+
+	if err != nil {
+		s.disk.index.remove(key)
+		s.forget(key, primaryKey)
+		s.disk.remove(key)
+		return nil
+	}
+
+	s.forgetAll(s.memory.add(&lruItem{key: key, primaryKey: primaryKey, size: e.size(), entry: e}))
+
+	return e
+}
+
+// set stores the given response.
+func (s *Store) set(e *entry) error {
+	var diskSize int64
+	var diskErr error
+	if s.disk != nil {
+		diskSize, diskErr = s.disk.write(e)
+	}
+
+	s.mu.Lock()
+
+	s.index(e)
+	s.forgetAll(s.memory.add(&lruItem{key: e.Key, primaryKey: e.PrimaryKey, size: e.size(), entry: e}))
+
+	var removed []string
+	if s.disk != nil {
+		if diskErr != nil {
+			// The file holds an outdated response, if any.
+			s.disk.index.remove(e.Key)
+			removed = append(removed, e.Key)
+		} else {
+			evicted := s.disk.index.add(&lruItem{key: e.Key, primaryKey: e.PrimaryKey, size: diskSize})
+			s.forgetAll(evicted)
+			for _, item := range evicted {
+				removed = append(removed, item.key)
+			}
+			if _, ok := s.disk.index.items[e.Key]; !ok {
+				removed = append(removed, e.Key)
+			}
+		}
+	}
+
+	s.forget(e.Key, e.PrimaryKey)
+
+	s.mu.Unlock()
+
+	for _, key := range removed {
+		s.disk.remove(key)
+	}
+
+	if diskErr != nil {
+		return fmt.Errorf("unable to write response on disk: %w", diskErr)
+	}
+	return nil
+}
+
+// index adds the given response to the variants of its URI.
+func (s *Store) index(e *entry) {
+	v, ok := s.variants[e.PrimaryKey]
+	if !ok {
+		v = &variants{keys: make(map[string]struct{})}
+		s.variants[e.PrimaryKey] = v
+	}
+
+	v.vary = e.Vary
+	v.keys[e.Key] = struct{}{}
+}
+
+// forget removes the given key from the variants of its URI, unless the response is still stored in a tier.
+func (s *Store) forget(key, primaryKey string) {
+	if _, ok := s.memory.items[key]; ok {
+		return
+	}
+	if s.disk != nil {
+		if _, ok := s.disk.index.items[key]; ok {
+			return
+		}
+	}
+
+	v, ok := s.variants[primaryKey]
+	if !ok {
+		return
+	}
+
+	delete(v.keys, key)
+	if len(v.keys) == 0 {
+		delete(s.variants, primaryKey)
+	}
+}
+
+func (s *Store) forgetAll(items []*lruItem) {
+	for _, item := range items {
+		s.forget(item.key, item.primaryKey)
+	}
+}
+
+// startRevalidation returns whether the revalidation of the response of the given key can start,
+// which is not the case when it is already being revalidated.
+func (s *Store) startRevalidation(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.revalidations[key]; ok {
+		return false
+	}
+	s.revalidations[key] = struct{}{}
+	return true
+}
+
+func (s *Store) endRevalidation(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.revalidations, key)
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEntry(req *http.Request, body string) *entry {
+	return &entry{
+		Key:          primaryKey(req),
+		PrimaryKey:   primaryKey(req),
+		Status:       http.StatusOK,
+		Header:       http.Header{"Cache-Control": []string{"max-age=60"}},
+		Body:         []byte(body),
+		RequestTime:  time.Now(),
+		ResponseTime: time.Now(),
+	}
+}
+
+func TestStore_eviction(t *testing.T) {
+	foo := httptest.NewRequest(http.MethodGet, "http://foo.com/foo", nil)
+	bar := httptest.NewRequest(http.MethodGet, "http://foo.com/bar", nil)
+
+	fooEntry := newTestEntry(foo, strings.Repeat("a", 100))
+	barEntry := newTestEntry(bar, strings.Repeat("b", 100))
+
+	store, err := NewStore(dynamic.Cache{MaxSize: fooEntry.size() + barEntry.size() - 1})
+	require.NoError(t, err)
+
+	require.NoError(t, store.set(fooEntry))
+	require.NotNil(t, store.get(foo))
+
+	// The least recently used response is evicted.
+	require.NoError(t, store.set(barEntry))
+	assert.Nil(t, store.get(foo))
+	assert.NotNil(t, store.get(bar))
+	assert.Empty(t, store.variants[primaryKey(foo)])
+}
+
+func TestStore_disk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	foo := httptest.NewRequest(http.MethodGet, "http://foo.com/foo", nil)
+	bar := httptest.NewRequest(http.MethodGet, "http://foo.com/bar", nil)
+
+	fooEntry := newTestEntry(foo, "foo")
+	barEntry := newTestEntry(bar, "bar")
+
+	config := dynamic.Cache{
+		MaxSize: fooEntry.size(),
+		Disk:    &dynamic.CacheDisk{Path: dir, MaxSize: 1024 * 1024},
+	}
+
+	store, err := NewStore(config)
+	require.NoError(t, err)
+
+	require.NoError(t, store.set(fooEntry))
+	require.NoError(t, store.set(barEntry))
+
+	// The response evicted from memory is read from the disk.
+	e := store.get(foo)
+	require.NotNil(t, e)
+	assert.Equal(t, "foo", string(e.Body))
+
+	// The responses are kept across restarts.
+	store, err = NewStore(config)
+	require.NoError(t, err)
+
+	e = store.get(bar)
+	require.NotNil(t, e)
+	assert.Equal(t, "bar", string(e.Body))
+
+	assert.True(t, store.Purge(primaryKey(bar)))
+	assert.Nil(t, store.get(bar))
+	assert.False(t, store.Purge(primaryKey(bar)))
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestStore_diskForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	foreign := []string{"README", "tmp-foo", strings.Repeat("a", 64), strings.Repeat("g", 64) + ".entry"}
+	for _, name := range foreign {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("foo"), 0600))
+	}
+
+	// A file named like a response, but which cannot be read, is removed.
+	corrupted := filepath.Join(dir, strings.Repeat("a", 64)+".entry")
+	require.NoError(t, ioutil.WriteFile(corrupted, []byte("foo"), 0600))
+
+	_, err := NewStore(dynamic.Cache{Disk: &dynamic.CacheDisk{Path: dir, MaxSize: 1024 * 1024}})
+	require.NoError(t, err)
+
+	for _, name := range foreign {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+	assert.NoFileExists(t, corrupted)
+}
+
+func TestStore_vary(t *testing.T) {
+	store, err := NewStore(dynamic.Cache{})
+	require.NoError(t, err)
+
+	en := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+	en.Header.Set("Accept-Language", "en")
+	fr := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+	fr.Header.Set("Accept-Language", "fr")
+
+	for _, req := range []*http.Request{en, fr} {
+		e := newTestEntry(req, req.Header.Get("Accept-Language"))
+		e.Vary = []string{"Accept-Language"}
+		e.Key = variantKey(e.PrimaryKey, e.Vary, req)
+		require.NoError(t, store.set(e))
+	}
+
+	assert.Equal(t, "en", string(store.get(en).Body))
+	assert.Equal(t, "fr", string(store.get(fr).Body))
+
+	// A purge removes all the variants.
+	assert.True(t, store.Purge("http://foo.com/"))
+	assert.Nil(t, store.get(en))
+	assert.Nil(t, store.get(fr))
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"reflect"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/generation"
+)
+
+// Stores holds the stores of the cache middlewares across configuration reloads,
+// so that the stored responses survive the rebuilding of the middlewares.
+type Stores struct {
+	stores *generation.Map
+}
+
+type configuredStore struct {
+	store   *Store
+	storage storageConfig
+}
+
+// storageConfig is the part of the configuration of a cache middleware the store is created from.
+type storageConfig struct {
+	maxSize int64
+	disk    *dynamic.CacheDisk
+}
+
+// NewStores creates a new Stores.
+func NewStores() *Stores {
+	return &Stores{stores: generation.NewMap()}
+}
+
+// NewGeneration must be called before building the middlewares of a new configuration.
+// It forgets the stores of the middlewares that were not part of the previous configuration.
+func (s *Stores) NewGeneration() {
+	s.stores.NewGeneration()
+}
+
+// get returns the store of the given middleware, which is a new one if its storage settings have changed.
+func (s *Stores) get(middlewareName string, config dynamic.Cache) (*Store, error) {
+	storage := storageConfig{maxSize: config.MaxSize, disk: config.Disk.DeepCopy()}
+
+	st, err := s.stores.Get(middlewareName, func(value interface{}) bool {
+		return reflect.DeepEqual(value.(*configuredStore).storage, storage)
+	}, func() (interface{}, error) {
+		store, err := NewStore(config)
+		if err != nil {
+			return nil, err
+		}
+		return &configuredStore{store: store, storage: storage}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st.(*configuredStore).store, nil
+}
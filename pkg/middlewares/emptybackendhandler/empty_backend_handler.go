@@ -31,3 +31,10 @@ func (e *emptyBackend) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		e.next.ServeHTTP(rw, req)
 	}
 }
+
+// RegisterStatusUpdater registers fn on the wrapped balancer, if it is able to report its status changes.
+func (e *emptyBackend) RegisterStatusUpdater(fn func(up bool)) {
+	if updater, ok := e.next.(healthcheck.StatusUpdater); ok {
+		updater.RegisterStatusUpdater(fn)
+	}
+}
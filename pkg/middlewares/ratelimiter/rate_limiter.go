@@ -3,6 +3,7 @@ package ratelimiter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -35,6 +36,9 @@ type rateLimiter struct {
 	next          http.Handler
 
 	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+	// redisBuckets are the buckets shared between the Traefik instances, if any.
+	// The local buckets are used when they cannot be reached.
+	redisBuckets *redisBuckets
 }
 
 // New returns a rate limiter middleware.
@@ -86,6 +90,14 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		}
 	}
 
+	var rb *redisBuckets
+	if config.Redis != nil && rtl > 0 {
+		rb, err = newRedisBuckets(config.Redis, name, time.Duration(float64(time.Second)/rtl), burst)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &rateLimiter{
 		name:          name,
 		rate:          rate.Limit(rtl),
@@ -94,6 +106,7 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		next:          next,
 		sourceMatcher: sourceMatcher,
 		buckets:       buckets,
+		redisBuckets:  rb,
 	}, nil
 }
 
@@ -116,6 +129,29 @@ func (rl *rateLimiter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		logger.Infof("ignoring token bucket amount > 1: %d", amount)
 	}
 
+	if rl.redisBuckets != nil {
+		delay, err := rl.redisBuckets.reserve(ctx, source, rl.maxDelay)
+		if err == nil {
+			if delay > rl.maxDelay {
+				rl.serveDelayError(ctx, w, r, delay)
+				return
+			}
+
+			time.Sleep(delay)
+			rl.next.ServeHTTP(w, r)
+			return
+		}
+
+		// The local bucket would let the source exceed the shared rate when its requests compete for the bucket.
+		if errors.Is(err, errStoreContention) {
+			logger.Debugf("Unable to reserve the bucket: %v", err)
+			http.Error(w, "No bursty traffic allowed", http.StatusTooManyRequests)
+			return
+		}
+
+		logger.Debugf("Using local bucket: %v", err)
+	}
+
 	var bucket *rate.Limiter
 	if rlSource, exists := rl.buckets.Get(source); exists {
 		bucket = rlSource.(*rate.Limiter)
@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/abronan/valkeyrie/store"
+	"github.com/alicebob/miniredis"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
 	"github.com/containous/traefik/v2/pkg/types"
@@ -314,3 +318,165 @@ func TestRateLimit(t *testing.T) {
 		})
 	}
 }
+
+func TestRateLimit_redis(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	config := dynamic.RateLimit{
+		Average: 1,
+		Period:  types.Duration(time.Hour),
+		Burst:   2,
+		Redis: &dynamic.RateLimitRedis{
+			Endpoints: []string{server.Addr()},
+		},
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	// Two instances of the same middleware, as on two Traefik instances.
+	instance1, err := New(context.Background(), next, config, "rate-limiter")
+	require.NoError(t, err)
+	instance2, err := New(context.Background(), next, config, "rate-limiter")
+	require.NoError(t, err)
+	other, err := New(context.Background(), next, config, "other-rate-limiter")
+	require.NoError(t, err)
+
+	serve := func(h http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve(instance1, "127.0.0.1:1234").Code)
+	assert.Equal(t, http.StatusOK, serve(instance2, "127.0.0.1:1234").Code)
+
+	rw := serve(instance1, "127.0.0.1:1234")
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+	assert.Equal(t, "3600", rw.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusTooManyRequests, serve(instance2, "127.0.0.1:1234").Code)
+
+	// The buckets are kept for each source, and each middleware.
+	assert.Equal(t, http.StatusOK, serve(instance2, "127.0.0.2:1234").Code)
+	assert.Equal(t, http.StatusOK, serve(other, "127.0.0.1:1234").Code)
+
+	key := "traefik/ratelimit/rate-limiter/127.0.0.1"
+	assert.True(t, server.Exists(key))
+	// The bucket is forgotten once it is full again.
+	assert.InDelta(t, 2*time.Hour, server.TTL(key), float64(2*time.Second))
+}
+
+func TestRateLimit_redisConcurrent(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	defer server.Close()
+
+	config := dynamic.RateLimit{
+		Average: 1,
+		Period:  types.Duration(time.Hour),
+		Burst:   20,
+		Redis: &dynamic.RateLimitRedis{
+			Endpoints: []string{server.Addr()},
+		},
+	}
+
+	h, err := New(context.Background(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), config, "rate-limiter")
+	require.NoError(t, err)
+
+	var served int32
+	var wg sync.WaitGroup
+	for i := 0; i < 60; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = "127.0.0.1:1234"
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code == http.StatusOK {
+				atomic.AddInt32(&served, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The concurrent requests of an instance never fall back to the local bucket.
+	assert.Equal(t, int32(20), served)
+}
+
+func TestRateLimit_redisUnavailable(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+
+	config := dynamic.RateLimit{
+		Average: 1,
+		Period:  types.Duration(time.Hour),
+		Burst:   1,
+		Redis: &dynamic.RateLimitRedis{
+			Endpoints: []string{server.Addr()},
+		},
+	}
+
+	h, err := New(context.Background(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), config, "rate-limiter")
+	require.NoError(t, err)
+
+	server.Close()
+
+	serve := func() int {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// The requests are limited with the local buckets.
+	assert.Equal(t, http.StatusOK, serve())
+	assert.Equal(t, http.StatusTooManyRequests, serve())
+
+	rtl, ok := h.(*rateLimiter)
+	require.True(t, ok)
+
+	rtl.redisBuckets.mu.Lock()
+	defer rtl.redisBuckets.mu.Unlock()
+	assert.True(t, rtl.redisBuckets.unavailableUntil.After(time.Now()))
+}
+
+// slowStore is a store whose reads only return once released.
+type slowStore struct {
+	store.Store
+	release chan struct{}
+}
+
+func (s slowStore) Get(string, *store.ReadOptions) (*store.KVPair, error) {
+	<-s.release
+	return nil, store.ErrKeyNotFound
+}
+
+func TestRedisBuckets_timeout(t *testing.T) {
+	kv := slowStore{release: make(chan struct{})}
+	defer close(kv.release)
+
+	buckets := &redisBuckets{
+		kv:       kv,
+		prefix:   "traefik/ratelimit/foo",
+		interval: time.Second,
+		burst:    1,
+		timeout:  10 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err := buckets.reserve(context.Background(), "127.0.0.1", time.Second)
+	assert.Equal(t, errStoreUnavailable, err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	// The source is not left locked by the pending call.
+	buckets.unavailableUntil = time.Time{}
+
+	_, err = buckets.reserve(context.Background(), "127.0.0.1", time.Second)
+	assert.Equal(t, errStoreUnavailable, err)
+}
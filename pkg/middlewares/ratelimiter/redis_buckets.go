@@ -0,0 +1,224 @@
+package ratelimiter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abronan/valkeyrie/store"
+	"github.com/abronan/valkeyrie/store/redis"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+const (
+	// storeRetryInterval is how long the buckets are kept in memory after the store could not be reached.
+	storeRetryInterval = 10 * time.Second
+	// maxStoreAttempts is the maximum number of attempts to update a bucket modified concurrently.
+	maxStoreAttempts = 5
+	// sourceLocks is the number of locks serializing the updates of the buckets within an instance.
+	sourceLocks = 64
+	// storeTimeout bounds each call to the store, made with the lock of the source held,
+	// as the Redis client only times out its reads after 30 seconds.
+	storeTimeout = 100 * time.Millisecond
+)
+
+var (
+	errStoreUnavailable = errors.New("store unavailable")
+	errStoreContention  = errors.New("too many concurrent updates")
+	errStoreTimeout     = errors.New("store call timed out")
+)
+
+var (
+	redisStoresMu sync.Mutex
+	redisStores   = make(map[string]store.Store)
+)
+
+// getRedisStore returns the client of the given Redis server, which is shared by all the rate limiters,
+// as they are created again on each configuration change.
+func getRedisStore(config *dynamic.RateLimitRedis) (store.Store, error) {
+	id := strings.Join(config.Endpoints, ",") + "@" + config.Password
+
+	redisStoresMu.Lock()
+	defer redisStoresMu.Unlock()
+
+	if kv, ok := redisStores[id]; ok {
+		return kv, nil
+	}
+
+	kv, err := redis.New(config.Endpoints, &store.Config{Password: config.Password})
+	if err != nil {
+		return nil, err
+	}
+
+	redisStores[id] = kv
+	return kv, nil
+}
+
+// bucketState is the state of a bucket in the store.
+// The Redis store compares the LastIndex field of the JSON values in its atomic operations,
+// and the theoretical arrival time, which increases on each update, is used as such.
+type bucketState struct {
+	TAT string `json:"LastIndex"`
+}
+
+// redisBuckets implements the token buckets with the generic cell rate algorithm (GCRA),
+// whose theoretical arrival times are stored in Redis to share the buckets between the Traefik instances.
+type redisBuckets struct {
+	kv     store.Store
+	prefix string
+	// interval is the time between two requests at the average rate.
+	interval time.Duration
+	burst    int64
+	timeout  time.Duration
+
+	// locks serialize the updates of the buckets within the instance, by source hash,
+	// so that only the other instances can modify a bucket concurrently.
+	locks [sourceLocks]sync.Mutex
+
+	mu               sync.Mutex
+	unavailableUntil time.Time
+}
+
+func newRedisBuckets(config *dynamic.RateLimitRedis, name string, interval time.Duration, burst int64) (*redisBuckets, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, errors.New("no Redis endpoint")
+	}
+
+	kv, err := getRedisStore(config)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create Redis client: %w", err)
+	}
+
+	prefix := config.KeyPrefix
+	if prefix == "" {
+		prefix = "traefik/ratelimit"
+	}
+
+	return &redisBuckets{
+		kv:       kv,
+		prefix:   strings.TrimSuffix(prefix, "/") + "/" + url.PathEscape(name),
+		interval: interval,
+		burst:    burst,
+		timeout:  storeTimeout,
+	}, nil
+}
+
+// reserve reserves a request for the given source, unless it would have to be delayed more than maxDelay,
+// and returns the delay after which the request can be performed.
+// errStoreContention is returned when the bucket is modified concurrently too many times,
+// and errStoreUnavailable when the store cannot be reached.
+func (b *redisBuckets) reserve(ctx context.Context, source string, maxDelay time.Duration) (time.Duration, error) {
+	b.mu.Lock()
+	unavailable := time.Now().Before(b.unavailableUntil)
+	b.mu.Unlock()
+
+	if unavailable {
+		return 0, errStoreUnavailable
+	}
+
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(source))
+	lock := &b.locks[hash.Sum32()%sourceLocks]
+
+	lock.Lock()
+	delay, err := b.update(b.prefix+"/"+url.PathEscape(source), maxDelay)
+	lock.Unlock()
+
+	if err == nil || errors.Is(err, errStoreContention) {
+		return delay, err
+	}
+
+	log.FromContext(ctx).Errorf("Unable to reach the rate limit store, the buckets are kept in memory for %s: %v", storeRetryInterval, err)
+
+	b.mu.Lock()
+	b.unavailableUntil = time.Now().Add(storeRetryInterval)
+	b.mu.Unlock()
+
+	return 0, errStoreUnavailable
+}
+
+func (b *redisBuckets) update(key string, maxDelay time.Duration) (time.Duration, error) {
+	for i := 0; i < maxStoreAttempts; i++ {
+		var previous *store.KVPair
+		err := b.withTimeout(func() error {
+			var err error
+			previous, err = b.kv.Get(key, nil)
+			return err
+		})
+		if err != nil && !errors.Is(err, store.ErrKeyNotFound) {
+			return 0, err
+		}
+
+		now := time.Now()
+
+		tat := now
+		if previous != nil {
+			var state bucketState
+			if json.Unmarshal(previous.Value, &state) == nil {
+				if nanos, err := strconv.ParseInt(state.TAT, 10, 64); err == nil && nanos > now.UnixNano() {
+					tat = time.Unix(0, nanos)
+				}
+			}
+		}
+
+		// The bucket is full when the theoretical arrival time is now,
+		// and a request can be performed as long as it is less than burst intervals ahead.
+		newTAT := tat.Add(b.interval)
+		delay := newTAT.Sub(now) - time.Duration(b.burst)*b.interval
+		if delay < 0 {
+			delay = 0
+		}
+
+		if delay > maxDelay {
+			return delay, nil
+		}
+
+		value, err := json.Marshal(bucketState{TAT: strconv.FormatInt(newTAT.UnixNano(), 10)})
+		if err != nil {
+			return 0, err
+		}
+
+		// The bucket is full again, and can be forgotten, once the theoretical arrival time is reached.
+		// The Redis store only supports TTLs in seconds.
+		ttl := newTAT.Sub(now).Truncate(time.Second) + time.Second
+
+		err = b.withTimeout(func() error {
+			_, _, err := b.kv.AtomicPut(key, value, previous, &store.WriteOptions{TTL: ttl})
+			return err
+		})
+		if err == nil {
+			return delay, nil
+		}
+
+		if !errors.Is(err, store.ErrKeyExists) && !errors.Is(err, store.ErrKeyModified) && !errors.Is(err, store.ErrKeyNotFound) {
+			return 0, err
+		}
+	}
+
+	return 0, errStoreContention
+}
+
+// withTimeout returns the error of the given call to the store, or errStoreTimeout if it does not return in time.
+// The call is then left running in the background, until the Redis client times it out.
+func (b *redisBuckets) withTimeout(call func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- call() }()
+
+	timer := time.NewTimer(b.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return errStoreTimeout
+	}
+}
@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// StatusRecorder is a http.ResponseWriter which records the status code of the response.
+type StatusRecorder interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	// Code returns the status code of the response, which is 200 if none was written.
+	Code() int
+}
+
+// NewStatusRecorder returns a StatusRecorder wrapping rw,
+// which implements http.CloseNotifier if rw implements it.
+func NewStatusRecorder(rw http.ResponseWriter) StatusRecorder {
+	rec := &statusRecorder{
+		ResponseWriter: rw,
+		statusCode:     http.StatusOK,
+	}
+	if _, ok := rw.(http.CloseNotifier); !ok {
+		return rec
+	}
+	return &statusRecorderWithCloseNotify{rec}
+}
+
+// statusRecorder captures the status code of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+type statusRecorderWithCloseNotify struct {
+	*statusRecorder
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone away.
+func (r *statusRecorderWithCloseNotify) CloseNotify() <-chan bool {
+	return r.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// Code returns the status code of the response.
+func (r *statusRecorder) Code() int {
+	return r.statusCode
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.ResponseWriter.WriteHeader(status)
+	r.statusCode = status
+}
+
+// Hijack hijacks the connection, if the underlying http.ResponseWriter supports it.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,96 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// budgetWindow is the number of seconds over which the requests and the retries are counted.
+const budgetWindow = 10
+
+type budgetBucket struct {
+	second   int64
+	requests int
+	retries  int
+}
+
+// budget caps the retries to a percentage of the requests of the last budgetWindow seconds,
+// so that the retries do not overload the backends when most of the requests fail.
+type budget struct {
+	percent             int
+	minRetriesPerSecond int
+	now                 func() time.Time
+
+	mu      sync.Mutex
+	buckets [budgetWindow]budgetBucket
+}
+
+func newBudget(percent, minRetriesPerSecond int) *budget {
+	return &budget{
+		percent:             percent,
+		minRetriesPerSecond: minRetriesPerSecond,
+		now:                 time.Now,
+	}
+}
+
+// recordRequest counts a request, which is not a retry.
+func (b *budget) recordRequest() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket().requests++
+}
+
+// recordRetry counts a retry.
+func (b *budget) recordRetry() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bucket().retries++
+}
+
+// allowRetry reports whether a retry would still be within the budget.
+func (b *budget) allowRetry() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	second := b.now().Unix()
+
+	var requests, retries int
+	for _, bucket := range b.buckets {
+		if second-bucket.second < budgetWindow {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+
+	allowed := requests * b.percent / 100
+	if min := b.minRetriesPerSecond * budgetWindow; allowed < min {
+		allowed = min
+	}
+
+	return retries < allowed
+}
+
+// bucket returns the bucket of the current second, and must be called with the lock held.
+func (b *budget) bucket() *budgetBucket {
+	second := b.now().Unix()
+
+	bucket := &b.buckets[second%budgetWindow]
+	if bucket.second != second {
+		*bucket = budgetBucket{second: second}
+	}
+	return bucket
+}
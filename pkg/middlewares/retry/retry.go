@@ -2,17 +2,24 @@ package retry
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares"
 	"github.com/containous/traefik/v2/pkg/tracing"
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/opentracing/opentracing-go/ext"
 )
 
@@ -34,12 +41,23 @@ type Listener interface {
 // each of them about a retry attempt.
 type Listeners []Listener
 
+// maxBufferedBody is the maximum size of a request body kept in memory to be sent again when retrying.
+const maxBufferedBody = 1 << 20
+
+// defaultMethods are the methods retried once the request was sent to the backend, by default.
+var defaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete}
+
 // retry is a middleware that retries requests.
 type retry struct {
-	attempts int
-	next     http.Handler
-	listener Listener
-	name     string
+	attempts        int
+	initialInterval time.Duration
+	status          types.HTTPCodeRanges
+	methods         map[string]struct{}
+	perTryTimeout   time.Duration
+	budget          *budget
+	next            http.Handler
+	listener        Listener
+	name            string
 }
 
 // New returns a new retry middleware.
@@ -50,12 +68,40 @@ func New(ctx context.Context, next http.Handler, config dynamic.Retry, listener
 		return nil, fmt.Errorf("incorrect (or empty) value for attempt (%d)", config.Attempts)
 	}
 
-	return &retry{
-		attempts: config.Attempts,
-		next:     next,
-		listener: listener,
-		name:     name,
-	}, nil
+	status, err := types.NewHTTPCodeRanges(config.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = defaultMethods
+	}
+
+	r := &retry{
+		attempts:        config.Attempts,
+		initialInterval: time.Duration(config.InitialInterval),
+		status:          status,
+		methods:         make(map[string]struct{}),
+		perTryTimeout:   time.Duration(config.PerTryTimeout),
+		next:            next,
+		listener:        listener,
+		name:            name,
+	}
+
+	for _, method := range methods {
+		r.methods[strings.ToUpper(method)] = struct{}{}
+	}
+
+	if config.Budget != nil {
+		if config.Budget.Percent < 0 || config.Budget.MinRetriesPerSecond < 0 {
+			return nil, fmt.Errorf("invalid retry budget: percent (%d) and minRetriesPerSecond (%d) must not be negative",
+				config.Budget.Percent, config.Budget.MinRetriesPerSecond)
+		}
+		r.budget = newBudget(config.Budget.Percent, config.Budget.MinRetriesPerSecond)
+	}
+
+	return r, nil
 }
 
 func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -63,45 +109,194 @@ func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := log.FromContext(middlewares.GetLoggerCtx(req.Context(), r.name, typeName))
+
+	r.budget.recordRequest()
+
+	// Requests already sent to the backend are retried only for a response status or a timeout,
+	// if their method allows it, and if their body can be sent again.
+	retrySent := r.attempts > 1 && (len(r.status) > 0 || r.perTryTimeout > 0) && r.allowMethod(req.Method)
+
+	var body []byte
+	if retrySent {
+		var err error
+		body, retrySent, err = bufferBody(req)
+		if err != nil {
+			logger.Debugf("Error while reading the request body: %v", err)
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// if we might make multiple attempts, swap the body for an ioutil.NopCloser
 	// cf https://github.com/containous/traefik/issues/1008
-	if r.attempts > 1 {
-		body := req.Body
-		defer body.Close()
-		req.Body = ioutil.NopCloser(body)
+	if r.attempts > 1 && req.Body != nil {
+		original := req.Body
+		defer original.Close()
+		req.Body = ioutil.NopCloser(original)
 	}
 
+	req, servers := withServers(req)
+	backOff := r.newBackOff()
+
 	attempts := 1
 	for {
-		shouldRetry := attempts < r.attempts
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		shouldRetry := attempts < r.attempts && r.budget.allowRetry()
+
+		// The attempts are timed out only when they can be retried, and only until they get their response headers,
+		// so that the streaming of a response body is never interrupted.
+		attemptCtx := req.Context()
+		cancel := func() {}
+		var timeout *attemptTimeout
+		if retrySent && shouldRetry && r.perTryTimeout > 0 {
+			attemptCtx, cancel = context.WithCancel(attemptCtx)
+			timeout = newAttemptTimeout(r.perTryTimeout, cancel)
+		}
+
 		retryResponseWriter := newResponseWriter(rw, shouldRetry)
+		if retrySent {
+			retryResponseWriter.RetrySent(r.status, timeout)
+		}
 
-		// Disable retries when the backend already received request data
+		// Disable retries when the backend already received request data,
+		// unless the request can be retried once sent.
 		trace := &httptrace.ClientTrace{
 			WroteHeaders: func() {
-				retryResponseWriter.DisableRetries()
+				retryResponseWriter.RequestSent()
 			},
 			WroteRequest: func(httptrace.WroteRequestInfo) {
-				retryResponseWriter.DisableRetries()
+				retryResponseWriter.RequestSent()
 			},
 		}
-		newCtx := httptrace.WithClientTrace(req.Context(), trace)
+		newCtx := httptrace.WithClientTrace(attemptCtx, trace)
 
 		r.next.ServeHTTP(retryResponseWriter, req.WithContext(newCtx))
+		timeout.stop()
+		cancel()
 
-		if !retryResponseWriter.ShouldRetry() {
+		if !retryResponseWriter.ShouldRetry() || req.Context().Err() != nil {
 			break
 		}
 
+		servers.attemptFailed()
+		r.budget.recordRetry()
 		attempts++
 
-		log.FromContext(middlewares.GetLoggerCtx(req.Context(), r.name, typeName)).
-			Debugf("New attempt %d for request: %v", attempts, req.URL)
+		interval := backOff.NextBackOff()
+		logger.Debugf("New attempt %d for request: %v, in %s", attempts, req.URL, interval)
 
 		r.listener.Retried(req, attempts)
+
+		if !sleep(req.Context(), interval) {
+			return
+		}
+	}
+}
+
+func (r *retry) allowMethod(method string) bool {
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	_, ok := r.methods[method]
+	return ok
+}
+
+// newBackOff returns the back-off of the retries of a request,
+// which grows exponentially (by 1.5, randomized by +/-50%) from the initial interval.
+func (r *retry) newBackOff() backoff.BackOff {
+	if r.initialInterval <= 0 {
+		return &backoff.ZeroBackOff{}
+	}
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = r.initialInterval
+	b.MaxElapsedTime = 0
+	b.Reset()
+	return b
+}
+
+// sleep waits for the given duration, and returns false if the context is done in the meantime.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
 
+// bufferBody reads the body of the request in memory, so that it can be sent again.
+// It returns false when the body is larger than maxBufferedBody, in which case the request body is left readable once.
+func bufferBody(req *http.Request) ([]byte, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.ContentLength > maxBufferedBody {
+		return nil, req.ContentLength <= maxBufferedBody, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxBufferedBody+1))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(body) > maxBufferedBody {
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		return nil, false, nil
+	}
+
+	return body, true, nil
+}
+
+// attemptTimeout cancels an attempt which did not get its response headers in time.
+type attemptTimeout struct {
+	timer *time.Timer
+
+	mu      sync.Mutex
+	stopped bool
+	expired bool
+}
+
+func newAttemptTimeout(d time.Duration, cancel context.CancelFunc) *attemptTimeout {
+	t := &attemptTimeout{}
+	t.timer = time.AfterFunc(d, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		if !t.stopped {
+			t.expired = true
+			cancel()
+		}
+	})
+	return t
+}
+
+// stop stops the timeout, and returns whether it expired before.
+func (t *attemptTimeout) stop() bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+	t.timer.Stop()
+	return t.expired
+}
+
 // Retried exists to implement the Listener interface. It calls Retried on each of its slice entries.
 func (l Listeners) Retried(req *http.Request, attempt int) {
 	for _, listener := range l {
@@ -114,6 +309,8 @@ type responseWriter interface {
 	http.Flusher
 	ShouldRetry() bool
 	DisableRetries()
+	RequestSent()
+	RetrySent(status types.HTTPCodeRanges, timeout *attemptTimeout)
 }
 
 func newResponseWriter(rw http.ResponseWriter, shouldRetry bool) responseWriter {
@@ -135,6 +332,15 @@ type responseWriterWithoutCloseNotify struct {
 	headers        http.Header
 	shouldRetry    bool
 	written        bool
+
+	// retrySent is whether the attempt is retried after the request was sent to the backend,
+	// when the response status is in status, or when timeout expires.
+	retrySent bool
+	status    types.HTTPCodeRanges
+	timeout   *attemptTimeout
+	sent      bool
+	// answered is whether the status of the response of the attempt was written.
+	answered bool
 }
 
 func (r *responseWriterWithoutCloseNotify) ShouldRetry() bool {
@@ -145,6 +351,20 @@ func (r *responseWriterWithoutCloseNotify) DisableRetries() {
 	r.shouldRetry = false
 }
 
+func (r *responseWriterWithoutCloseNotify) RequestSent() {
+	if r.retrySent {
+		r.sent = true
+		return
+	}
+	r.DisableRetries()
+}
+
+func (r *responseWriterWithoutCloseNotify) RetrySent(status types.HTTPCodeRanges, timeout *attemptTimeout) {
+	r.retrySent = true
+	r.status = status
+	r.timeout = timeout
+}
+
 func (r *responseWriterWithoutCloseNotify) Header() http.Header {
 	if r.written {
 		return r.responseWriter.Header()
@@ -153,6 +373,10 @@ func (r *responseWriterWithoutCloseNotify) Header() http.Header {
 }
 
 func (r *responseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
+	if r.ShouldRetry() && r.sent && !r.answered {
+		r.WriteHeader(http.StatusOK)
+	}
+
 	if r.ShouldRetry() {
 		return len(buf), nil
 	}
@@ -160,7 +384,12 @@ func (r *responseWriterWithoutCloseNotify) Write(buf []byte) (int, error) {
 }
 
 func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
-	if r.ShouldRetry() && code == http.StatusServiceUnavailable {
+	r.answered = true
+
+	// The response headers arrived, so the rest of the attempt is not timed out.
+	timedOut := r.timeout.stop()
+
+	if r.ShouldRetry() && !r.sent && code == http.StatusServiceUnavailable {
 		// We get a 503 HTTP Status Code when there is no backend server in the pool
 		// to which the request could be sent.  Also, note that r.ShouldRetry()
 		// will never return true in case there was a connection established to
@@ -169,6 +398,11 @@ func (r *responseWriterWithoutCloseNotify) WriteHeader(code int) {
 		r.DisableRetries()
 	}
 
+	if r.ShouldRetry() && r.sent && !r.status.Contains(code) && !timedOut {
+		// The backend answered with a status which is not retried, in time.
+		r.DisableRetries()
+	}
+
 	if r.ShouldRetry() {
 		return
 	}
@@ -195,6 +429,11 @@ func (r *responseWriterWithoutCloseNotify) Hijack() (net.Conn, *bufio.ReadWriter
 }
 
 func (r *responseWriterWithoutCloseNotify) Flush() {
+	if r.ShouldRetry() {
+		// The response may still be discarded for a retry.
+		return
+	}
+
 	if flusher, ok := r.responseWriter.(http.Flusher); ok {
 		flusher.Flush()
 	}
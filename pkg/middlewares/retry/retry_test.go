@@ -3,16 +3,19 @@ package retry
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httptrace"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/middlewares/emptybackendhandler"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/containous/traefik/v2/pkg/types"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -313,3 +316,211 @@ func TestRetryWebsocket(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryStatus(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		config            dynamic.Retry
+		method            string
+		wantRetryAttempts int
+		wantStatus        int
+	}{
+		{
+			desc:              "retried status",
+			config:            dynamic.Retry{Attempts: 3, Status: []string{"500-503"}},
+			method:            http.MethodPut,
+			wantRetryAttempts: 2,
+			wantStatus:        http.StatusOK,
+		},
+		{
+			desc:              "not retried status",
+			config:            dynamic.Retry{Attempts: 3, Status: []string{"503"}},
+			method:            http.MethodPut,
+			wantRetryAttempts: 0,
+			wantStatus:        http.StatusInternalServerError,
+		},
+		{
+			desc:              "non idempotent method",
+			config:            dynamic.Retry{Attempts: 3, Status: []string{"500"}},
+			method:            http.MethodPost,
+			wantRetryAttempts: 0,
+			wantStatus:        http.StatusInternalServerError,
+		},
+		{
+			desc:              "allowed method",
+			config:            dynamic.Retry{Attempts: 3, Status: []string{"500"}, Methods: []string{"post"}},
+			method:            http.MethodPost,
+			wantRetryAttempts: 2,
+			wantStatus:        http.StatusOK,
+		},
+		{
+			desc:              "attempts exhausted",
+			config:            dynamic.Retry{Attempts: 2, Status: []string{"500"}},
+			method:            http.MethodPut,
+			wantRetryAttempts: 1,
+			wantStatus:        http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			attempt := 0
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, "payload", string(body))
+
+				httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+				attempt++
+				if attempt < 3 {
+					rw.Header().Set("X-Attempt", strconv.Itoa(attempt))
+					rw.WriteHeader(http.StatusInternalServerError)
+					_, _ = rw.Write([]byte("KO"))
+					return
+				}
+
+				_, _ = rw.Write([]byte("OK"))
+			})
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(context.Background(), next, test.config, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://localhost:3000/ok", strings.NewReader("payload"))
+
+			retry.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.wantStatus, recorder.Code)
+			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+			if test.wantStatus == http.StatusOK {
+				assert.Equal(t, "OK", recorder.Body.String())
+				assert.Empty(t, recorder.Header().Get("X-Attempt"))
+			}
+		})
+	}
+}
+
+func TestRetryPerTryTimeout(t *testing.T) {
+	attempt := 0
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+		attempt++
+		if attempt == 1 {
+			<-req.Context().Done()
+			rw.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	config := dynamic.Retry{Attempts: 2, PerTryTimeout: types.Duration(10 * time.Millisecond)}
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 1, retryListener.timesCalled)
+}
+
+func TestRetryPerTryTimeout_notApplied(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		method string
+		next   http.HandlerFunc
+	}{
+		{
+			desc:   "method not retried",
+			method: http.MethodPost,
+			next: func(rw http.ResponseWriter, req *http.Request) {
+				httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+				time.Sleep(30 * time.Millisecond)
+				assert.NoError(t, req.Context().Err())
+				rw.WriteHeader(http.StatusOK)
+			},
+		},
+		{
+			desc:   "response body streamed after the response headers",
+			method: http.MethodGet,
+			next: func(rw http.ResponseWriter, req *http.Request) {
+				httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+				rw.WriteHeader(http.StatusOK)
+				time.Sleep(30 * time.Millisecond)
+				assert.NoError(t, req.Context().Err())
+				_, _ = rw.Write([]byte("OK"))
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.Retry{Attempts: 2, PerTryTimeout: types.Duration(10 * time.Millisecond)}
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(context.Background(), test.next, config, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			retry.ServeHTTP(recorder, httptest.NewRequest(test.method, "http://localhost:3000/ok", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, 0, retryListener.timesCalled)
+		})
+	}
+}
+
+func TestRetryBackOff(t *testing.T) {
+	var attempts []time.Time
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		attempts = append(attempts, time.Now())
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	config := dynamic.Retry{Attempts: 3, InitialInterval: types.Duration(50 * time.Millisecond)}
+
+	retry, err := New(context.Background(), next, config, &countingRetryListener{}, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	require.Len(t, attempts, 3)
+
+	// The first interval is randomized by +/-50%, and the second one is 1.5 times larger.
+	assert.GreaterOrEqual(t, int64(attempts[1].Sub(attempts[0])), int64(25*time.Millisecond))
+	assert.GreaterOrEqual(t, int64(attempts[2].Sub(attempts[1])), int64(37*time.Millisecond))
+}
+
+func TestRetryBudget(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	config := dynamic.Retry{Attempts: 2, Budget: &dynamic.RetryBudget{Percent: 50, MinRetriesPerSecond: 0}}
+
+	retryListener := &countingRetryListener{}
+	retry, err := New(context.Background(), next, config, retryListener, "traefikTest")
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		retry.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost:3000/ok", nil))
+	}
+
+	assert.Equal(t, 5, retryListener.timesCalled)
+}
@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+type serversKey struct{}
+
+// servers records the servers to which the attempts of a request are sent,
+// so that the load-balancers can avoid the servers on which a previous attempt failed.
+type servers struct {
+	mu      sync.Mutex
+	failed  map[string]struct{}
+	current []string
+}
+
+func withServers(req *http.Request) (*http.Request, *servers) {
+	s := &servers{failed: make(map[string]struct{})}
+	return req.WithContext(context.WithValue(req.Context(), serversKey{}, s)), s
+}
+
+// attemptFailed marks the servers of the current attempt as failed.
+func (s *servers) attemptFailed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, server := range s.current {
+		s.failed[server] = struct{}{}
+	}
+	s.current = nil
+}
+
+// AvoidServer reports whether the given server, chosen by a load-balancer for the request,
+// should be avoided because a previous attempt of the request failed on it.
+func AvoidServer(req *http.Request, server string) bool {
+	s, ok := req.Context().Value(serversKey{}).(*servers)
+	if !ok {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, failed := s.failed[server]
+	return failed
+}
+
+// UseServer records that the current attempt of the request is sent to the given server.
+func UseServer(req *http.Request, server string) {
+	s, ok := req.Context().Value(serversKey{}).(*servers)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.current = append(s.current, server)
+}
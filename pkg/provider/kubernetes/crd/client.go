@@ -49,6 +49,7 @@ type Client interface {
 	GetIngressRouteTCPs() []*v1alpha1.IngressRouteTCP
 	GetIngressRouteUDPs() []*v1alpha1.IngressRouteUDP
 	GetMiddlewares() []*v1alpha1.Middleware
+	GetServersTransports() []*v1alpha1.ServersTransport
 	GetTraefikService(namespace, name string) (*v1alpha1.TraefikService, bool, error)
 	GetTraefikServices() []*v1alpha1.TraefikService
 	GetTLSOptions() []*v1alpha1.TLSOption
@@ -164,6 +165,7 @@ func (c *clientWrapper) WatchAll(namespaces []string, stopCh <-chan struct{}) (<
 		factoryCrd.Traefik().V1alpha1().TLSOptions().Informer().AddEventHandler(eventHandler)
 		factoryCrd.Traefik().V1alpha1().TLSStores().Informer().AddEventHandler(eventHandler)
 		factoryCrd.Traefik().V1alpha1().TraefikServices().Informer().AddEventHandler(eventHandler)
+		factoryCrd.Traefik().V1alpha1().ServersTransports().Informer().AddEventHandler(eventHandler)
 
 		factoryKube := informers.NewSharedInformerFactoryWithOptions(c.csKube, resyncPeriod, informers.WithNamespace(ns))
 		factoryKube.Extensions().V1beta1().Ingresses().Informer().AddEventHandler(eventHandler)
@@ -279,6 +281,21 @@ func (c *clientWrapper) GetTraefikServices() []*v1alpha1.TraefikService {
 	return result
 }
 
+// GetServersTransports returns all ServersTransports.
+func (c *clientWrapper) GetServersTransports() []*v1alpha1.ServersTransport {
+	var result []*v1alpha1.ServersTransport
+
+	for ns, factory := range c.factoriesCrd {
+		serversTransports, err := factory.Traefik().V1alpha1().ServersTransports().Lister().List(c.labelSelector)
+		if err != nil {
+			log.Errorf("Failed to list servers transports in namespace %s: %v", ns, err)
+		}
+		result = append(result, serversTransports...)
+	}
+
+	return result
+}
+
 // GetTLSOptions returns all TLS options.
 func (c *clientWrapper) GetTLSOptions() []*v1alpha1.TLSOption {
 	var result []*v1alpha1.TLSOption
@@ -372,6 +389,8 @@ func (c *clientWrapper) newResourceEventHandler(events chan<- interface{}) cache
 				return c.labelSelector.Matches(labels.Set(v.GetLabels()))
 			case *v1alpha1.Middleware:
 				return c.labelSelector.Matches(labels.Set(v.GetLabels()))
+			case *v1alpha1.ServersTransport:
+				return c.labelSelector.Matches(labels.Set(v.GetLabels()))
 			default:
 				return true
 			}
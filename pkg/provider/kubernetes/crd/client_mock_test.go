@@ -30,13 +30,14 @@ type clientMock struct {
 	apiSecretError    error
 	apiEndpointsError error
 
-	ingressRoutes    []*v1alpha1.IngressRoute
-	ingressRouteTCPs []*v1alpha1.IngressRouteTCP
-	ingressRouteUDPs []*v1alpha1.IngressRouteUDP
-	middlewares      []*v1alpha1.Middleware
-	tlsOptions       []*v1alpha1.TLSOption
-	tlsStores        []*v1alpha1.TLSStore
-	traefikServices  []*v1alpha1.TraefikService
+	ingressRoutes     []*v1alpha1.IngressRoute
+	ingressRouteTCPs  []*v1alpha1.IngressRouteTCP
+	ingressRouteUDPs  []*v1alpha1.IngressRouteUDP
+	middlewares       []*v1alpha1.Middleware
+	serversTransports []*v1alpha1.ServersTransport
+	tlsOptions        []*v1alpha1.TLSOption
+	tlsStores         []*v1alpha1.TLSStore
+	traefikServices   []*v1alpha1.TraefikService
 
 	watchChan chan interface{}
 }
@@ -67,6 +68,8 @@ func newClientMock(paths ...string) clientMock {
 				c.middlewares = append(c.middlewares, o)
 			case *v1alpha1.TraefikService:
 				c.traefikServices = append(c.traefikServices, o)
+			case *v1alpha1.ServersTransport:
+				c.serversTransports = append(c.serversTransports, o)
 			case *v1alpha1.TLSOption:
 				c.tlsOptions = append(c.tlsOptions, o)
 			case *v1alpha1.TLSStore:
@@ -98,6 +101,10 @@ func (c clientMock) GetMiddlewares() []*v1alpha1.Middleware {
 	return c.middlewares
 }
 
+func (c clientMock) GetServersTransports() []*v1alpha1.ServersTransport {
+	return c.serversTransports
+}
+
 func (c clientMock) GetTraefikService(namespace, name string) (*v1alpha1.TraefikService, bool, error) {
 	for _, svc := range c.traefikServices {
 		if svc.Namespace == namespace && svc.Name == name {
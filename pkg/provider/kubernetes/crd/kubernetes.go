@@ -199,6 +199,12 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			continue
 		}
 
+		apiKey, err := createAPIKeyMiddleware(client, middleware.Namespace, middleware.Spec.APIKey)
+		if err != nil {
+			log.FromContext(ctxMid).Errorf("Error while reading API key middleware: %v", err)
+			continue
+		}
+
 		errorPage, errorPageService, err := createErrorPageMiddleware(client, middleware.Namespace, middleware.Spec.Errors)
 		if err != nil {
 			log.FromContext(ctxMid).Errorf("Error while reading error page middleware: %v", err)
@@ -212,30 +218,37 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 		}
 
 		conf.HTTP.Middlewares[id] = &dynamic.Middleware{
-			AddPrefix:         middleware.Spec.AddPrefix,
-			StripPrefix:       middleware.Spec.StripPrefix,
-			StripPrefixRegex:  middleware.Spec.StripPrefixRegex,
-			ReplacePath:       middleware.Spec.ReplacePath,
-			ReplacePathRegex:  middleware.Spec.ReplacePathRegex,
-			Chain:             createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
-			IPWhiteList:       middleware.Spec.IPWhiteList,
-			Headers:           middleware.Spec.Headers,
-			Errors:            errorPage,
-			RateLimit:         middleware.Spec.RateLimit,
-			RedirectRegex:     middleware.Spec.RedirectRegex,
-			RedirectScheme:    middleware.Spec.RedirectScheme,
-			BasicAuth:         basicAuth,
-			DigestAuth:        digestAuth,
-			ForwardAuth:       forwardAuth,
-			InFlightReq:       middleware.Spec.InFlightReq,
-			Buffering:         middleware.Spec.Buffering,
-			CircuitBreaker:    middleware.Spec.CircuitBreaker,
-			Compress:          middleware.Spec.Compress,
-			PassTLSClientCert: middleware.Spec.PassTLSClientCert,
-			Retry:             middleware.Spec.Retry,
+			AddPrefix:           middleware.Spec.AddPrefix,
+			StripPrefix:         middleware.Spec.StripPrefix,
+			StripPrefixRegex:    middleware.Spec.StripPrefixRegex,
+			ReplacePath:         middleware.Spec.ReplacePath,
+			ReplacePathRegex:    middleware.Spec.ReplacePathRegex,
+			Chain:               createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
+			IPWhiteList:         middleware.Spec.IPWhiteList,
+			Headers:             middleware.Spec.Headers,
+			Errors:              errorPage,
+			RateLimit:           middleware.Spec.RateLimit,
+			RedirectRegex:       middleware.Spec.RedirectRegex,
+			RedirectScheme:      middleware.Spec.RedirectScheme,
+			BasicAuth:           basicAuth,
+			DigestAuth:          digestAuth,
+			ForwardAuth:         forwardAuth,
+			JWT:                 middleware.Spec.JWT,
+			OIDC:                middleware.Spec.OIDC,
+			APIKey:              apiKey,
+			InFlightReq:         middleware.Spec.InFlightReq,
+			AdaptiveInFlightReq: middleware.Spec.AdaptiveInFlightReq,
+			Buffering:           middleware.Spec.Buffering,
+			Cache:               middleware.Spec.Cache,
+			CircuitBreaker:      middleware.Spec.CircuitBreaker,
+			Compress:            middleware.Spec.Compress,
+			PassTLSClientCert:   middleware.Spec.PassTLSClientCert,
+			Retry:               middleware.Spec.Retry,
 		}
 	}
 
+	conf.HTTP.ServersTransports = buildServersTransports(ctx, client)
+
 	cb := configBuilder{client}
 	for _, service := range client.GetTraefikServices() {
 		err := cb.buildTraefikService(ctx, service, conf.HTTP.Services)
@@ -249,6 +262,21 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 	return conf
 }
 
+// serverWeight returns the weight set in servers for the pod behind the given endpoint address, if any.
+func serverWeight(servers []v1alpha1.Server, addr corev1.EndpointAddress) *int {
+	if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+		return nil
+	}
+
+	for _, server := range servers {
+		if server.Pod == addr.TargetRef.Name {
+			return server.Weight
+		}
+	}
+
+	return nil
+}
+
 func getServicePort(svc *corev1.Service, port int32) (*corev1.ServicePort, error) {
 	if svc == nil {
 		return nil, errors.New("service is not defined")
@@ -311,6 +339,11 @@ func createForwardAuthMiddleware(k8sClient Client, namespace string, auth *v1alp
 		Address:             auth.Address,
 		TrustForwardHeader:  auth.TrustForwardHeader,
 		AuthResponseHeaders: auth.AuthResponseHeaders,
+		AuthRequestHeaders:  auth.AuthRequestHeaders,
+		Method:              auth.Method,
+		ForwardBody:         auth.ForwardBody,
+		MaxBodySize:         auth.MaxBodySize,
+		Cache:               auth.Cache,
 	}
 
 	if auth.TLS == nil {
@@ -417,6 +450,26 @@ func createDigestAuthMiddleware(client Client, namespace string, digestAuth *v1a
 	}, nil
 }
 
+func createAPIKeyMiddleware(client Client, namespace string, apiKey *v1alpha1.APIKey) (*dynamic.APIKey, error) {
+	if apiKey == nil {
+		return nil, nil
+	}
+
+	keys, err := getAuthCredentials(client, apiKey.Secret, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamic.APIKey{
+		Keys:        keys,
+		Header:      apiKey.Header,
+		Query:       apiKey.Query,
+		HeaderField: apiKey.HeaderField,
+		RemoveKey:   apiKey.RemoveKey,
+		Tiers:       apiKey.Tiers,
+	}, nil
+}
+
 func getAuthCredentials(k8sClient Client, authSecret, namespace string) ([]string, error) {
 	if authSecret == "" {
 		return nil, fmt.Errorf("auth secret must be set")
@@ -556,6 +609,64 @@ func buildTLSOptions(ctx context.Context, client Client) map[string]tls.Options
 	return tlsOptions
 }
 
+func buildServersTransports(ctx context.Context, client Client) map[string]*dynamic.ServersTransport {
+	serversTransportsCRD := client.GetServersTransports()
+	var serversTransports map[string]*dynamic.ServersTransport
+
+	if len(serversTransportsCRD) == 0 {
+		return serversTransports
+	}
+	serversTransports = make(map[string]*dynamic.ServersTransport)
+
+	for _, serversTransport := range serversTransportsCRD {
+		namespace := serversTransport.Namespace
+		logger := log.FromContext(log.With(ctx, log.Str("serversTransport", serversTransport.Name), log.Str("namespace", namespace)))
+
+		var rootCAs []tls.FileOrContent
+		for _, secretName := range serversTransport.Spec.RootCAsSecrets {
+			ca, err := loadCASecret(namespace, secretName, client)
+			if err != nil {
+				logger.Errorf("Failed to extract CA from secret %s/%s: %v", namespace, secretName, err)
+				continue
+			}
+
+			rootCAs = append(rootCAs, tls.FileOrContent(ca))
+		}
+
+		var certs tls.Certificates
+		for _, secretName := range serversTransport.Spec.CertificatesSecrets {
+			tlsCert, tlsKey, err := loadAuthTLSSecret(namespace, secretName, client)
+			if err != nil {
+				logger.Errorf("Failed to extract certificate from secret %s/%s: %v", namespace, secretName, err)
+				continue
+			}
+
+			certs = append(certs, tls.Certificate{
+				CertFile: tls.FileOrContent(tlsCert),
+				KeyFile:  tls.FileOrContent(tlsKey),
+			})
+		}
+
+		st := &dynamic.ServersTransport{}
+		st.SetDefaults()
+		st.ServerName = serversTransport.Spec.ServerName
+		st.InsecureSkipVerify = serversTransport.Spec.InsecureSkipVerify
+		st.RootCAs = rootCAs
+		st.Certificates = certs
+		st.DisableHTTP2 = serversTransport.Spec.DisableHTTP2
+		if serversTransport.Spec.MaxIdleConnsPerHost != 0 {
+			st.MaxIdleConnsPerHost = serversTransport.Spec.MaxIdleConnsPerHost
+		}
+		if serversTransport.Spec.ForwardingTimeouts != nil {
+			st.ForwardingTimeouts = serversTransport.Spec.ForwardingTimeouts
+		}
+
+		serversTransports[makeID(namespace, serversTransport.Name)] = st
+	}
+
+	return serversTransports
+}
+
 func buildTLSStores(ctx context.Context, client Client) map[string]tls.Store {
 	tlsStoreCRD := client.GetTLSStores()
 	var tlsStores map[string]tls.Store
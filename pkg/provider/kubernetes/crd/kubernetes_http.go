@@ -169,6 +169,8 @@ func (c configBuilder) buildTraefikService(ctx context.Context, tService *v1alph
 		return c.buildServicesLB(ctx, tService.Namespace, tService.Spec, id, conf)
 	} else if tService.Spec.Mirroring != nil {
 		return c.buildMirroring(ctx, tService, id, conf)
+	} else if tService.Spec.Failover != nil {
+		return c.buildFailover(ctx, tService, id, conf)
 	}
 
 	return errors.New("unspecified service type")
@@ -243,6 +245,39 @@ func (c configBuilder) buildMirroring(ctx context.Context, tService *v1alpha1.Tr
 			Service:     fullNameMain,
 			Mirrors:     mirrorServices,
 			MaxBodySize: tService.Spec.Mirroring.MaxBodySize,
+			Compare:     tService.Spec.Mirroring.Compare,
+		},
+	}
+
+	return nil
+}
+
+// buildFailover creates the configuration for the failover service named id, and defined by tService.
+// It adds it to the given conf map.
+func (c configBuilder) buildFailover(ctx context.Context, tService *v1alpha1.TraefikService, id string, conf map[string]*dynamic.Service) error {
+	fullNameMain, k8sService, err := c.nameAndService(ctx, tService.Namespace, tService.Spec.Failover.Service)
+	if err != nil {
+		return err
+	}
+
+	if k8sService != nil {
+		conf[fullNameMain] = k8sService
+	}
+
+	fullNameFallback, k8sService, err := c.nameAndService(ctx, tService.Namespace, tService.Spec.Failover.Fallback)
+	if err != nil {
+		return err
+	}
+
+	if k8sService != nil {
+		conf[fullNameFallback] = k8sService
+	}
+
+	conf[id] = &dynamic.Service{
+		Failover: &dynamic.Failover{
+			Service:  fullNameMain,
+			Fallback: fullNameFallback,
+			Errors:   tService.Spec.Failover.Errors,
 		},
 	}
 
@@ -270,18 +305,35 @@ func (c configBuilder) buildServersLB(namespace string, svc v1alpha1.LoadBalance
 
 	lb.Sticky = svc.Sticky
 
+	// RoundRobin is the historical name of the default (weighted round robin) strategy.
+	if svc.Strategy != roundRobinStrategy {
+		lb.Strategy = svc.Strategy
+	}
+	lb.ConsistentHash = svc.ConsistentHash
+	lb.ServersTransport = serversTransportName(namespace, svc)
+	lb.SlowStart = svc.SlowStart
+	lb.Hedging = svc.Hedging
+	if svc.ProxyProtocol != nil {
+		lb.ProxyProtocol = &dynamic.ProxyProtocol{Version: svc.ProxyProtocol.Version}
+		if lb.ProxyProtocol.Version == 0 {
+			lb.ProxyProtocol.SetDefaults()
+		}
+	}
+
 	return &dynamic.Service{LoadBalancer: lb}, nil
 }
 
-func (c configBuilder) loadServers(fallbackNamespace string, svc v1alpha1.LoadBalancerSpec) ([]dynamic.Server, error) {
-	strategy := svc.Strategy
-	if strategy == "" {
-		strategy = roundRobinStrategy
-	}
-	if strategy != roundRobinStrategy {
-		return nil, fmt.Errorf("load balancing strategy %s is not supported", strategy)
+// serversTransportName returns the name of the ServersTransport referenced by the given service,
+// which is looked up in the namespace of the service unless it is a cross-provider reference.
+func serversTransportName(fallbackNamespace string, svc v1alpha1.LoadBalancerSpec) string {
+	if svc.ServersTransport == "" || strings.Contains(svc.ServersTransport, providerNamespaceSeparator) {
+		return svc.ServersTransport
 	}
 
+	return makeID(namespaceOrFallback(svc, fallbackNamespace), svc.ServersTransport)
+}
+
+func (c configBuilder) loadServers(fallbackNamespace string, svc v1alpha1.LoadBalancerSpec) ([]dynamic.Server, error) {
 	namespace := namespaceOrFallback(svc, fallbackNamespace)
 
 	// If the service uses explicitly the provider suffix
@@ -342,7 +394,8 @@ func (c configBuilder) loadServers(fallbackNamespace string, svc v1alpha1.LoadBa
 
 		for _, addr := range subset.Addresses {
 			servers = append(servers, dynamic.Server{
-				URL: fmt.Sprintf("%s://%s:%d", protocol, addr.IP, port),
+				URL:    fmt.Sprintf("%s://%s:%d", protocol, addr.IP, port),
+				Weight: serverWeight(svc.Servers, addr),
 			})
 		}
 	}
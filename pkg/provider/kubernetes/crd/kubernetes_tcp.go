@@ -149,6 +149,13 @@ func createLoadBalancerServerTCP(client Client, namespace string, service v1alph
 		tcpService.LoadBalancer.TerminationDelay = service.TerminationDelay
 	}
 
+	if service.ProxyProtocol != nil {
+		tcpService.LoadBalancer.ProxyProtocol = &dynamic.ProxyProtocol{Version: service.ProxyProtocol.Version}
+		if tcpService.LoadBalancer.ProxyProtocol.Version == 0 {
+			tcpService.LoadBalancer.ProxyProtocol.SetDefaults()
+		}
+	}
+
 	return tcpService, nil
 }
 
@@ -202,6 +209,7 @@ func loadTCPServers(client Client, namespace string, svc v1alpha1.ServiceTCP) ([
 			for _, addr := range subset.Addresses {
 				servers = append(servers, dynamic.TCPServer{
 					Address: fmt.Sprintf("%s:%d", addr.IP, port),
+					Weight:  serverWeight(svc.Servers, addr),
 				})
 			}
 		}
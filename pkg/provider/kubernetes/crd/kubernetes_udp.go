@@ -152,6 +152,7 @@ func loadUDPServers(client Client, namespace string, svc v1alpha1.ServiceUDP) ([
 			for _, addr := range subset.Addresses {
 				servers = append(servers, dynamic.UDPServer{
 					Address: fmt.Sprintf("%s:%d", addr.IP, port),
+					Weight:  serverWeight(svc.Servers, addr),
 				})
 			}
 		}
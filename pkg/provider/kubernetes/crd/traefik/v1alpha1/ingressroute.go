@@ -26,10 +26,10 @@ type Route struct {
 // To enable Let's Encrypt, use an empty TLS struct,
 // e.g. in YAML:
 //
-//	 tls: {} # inline format
+//	tls: {} # inline format
 //
-//	 tls:
-//	   secretName: # block format
+//	tls:
+//	  secretName: # block format
 type TLS struct {
 	// SecretName is the name of the referenced Kubernetes Secret to specify the
 	// certificate details.
@@ -70,14 +70,34 @@ type LoadBalancerSpec struct {
 	Port               int32                       `json:"port"`
 	Scheme             string                      `json:"scheme,omitempty"`
 	Strategy           string                      `json:"strategy,omitempty"`
+	ConsistentHash     *dynamic.ConsistentHash     `json:"consistentHash,omitempty"`
 	PassHostHeader     *bool                       `json:"passHostHeader,omitempty"`
 	ResponseForwarding *dynamic.ResponseForwarding `json:"responseForwarding,omitempty"`
+	// ServersTransport is the name of a ServersTransport resource, in the same namespace as the Service.
+	ServersTransport string `json:"serversTransport,omitempty"`
+	// SlowStart is the duration over which the weight of a new server (e.g. a new pod) is raised to its full value.
+	SlowStart types.Duration `json:"slowStart,omitempty"`
+	// Hedging sends copies of the slow requests to other pods, and keeps the first response.
+	Hedging *dynamic.Hedging `json:"hedging,omitempty"`
+	// ProxyProtocol sends a PROXY protocol header, describing the client connection, to the pods.
+	ProxyProtocol *dynamic.ProxyProtocol `json:"proxyProtocol,omitempty"`
+	// Servers sets the weight of the servers of some pods.
+	Servers []Server `json:"servers,omitempty"`
 
 	// Weight should only be specified when Name references a TraefikService object
 	// (and to be precise, one that embeds a Weighted Round Robin).
 	Weight *int `json:"weight,omitempty"`
 }
 
+// Server sets the weight of the server of a pod of a Kubernetes Service,
+// e.g. to send more traffic to the bigger pods of a StatefulSet.
+type Server struct {
+	// Pod is the name of the pod.
+	Pod string `json:"pod"`
+	// Weight is the weight of the server of the pod, which defaults to 1.
+	Weight *int `json:"weight,omitempty"`
+}
+
 // Service defines an upstream to proxy traffic.
 type Service struct {
 	LoadBalancerSpec
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -58,6 +59,10 @@ type ServiceTCP struct {
 	Port             int32  `json:"port"`
 	Weight           *int   `json:"weight,omitempty"`
 	TerminationDelay *int   `json:"terminationDelay,omitempty"`
+	// ProxyProtocol sends a PROXY protocol header, describing the client connection, to the pods.
+	ProxyProtocol *dynamic.ProxyProtocol `json:"proxyProtocol,omitempty"`
+	// Servers sets the weight of the servers of some pods.
+	Servers []Server `json:"servers,omitempty"`
 }
 
 // +genclient
@@ -27,6 +27,8 @@ type ServiceUDP struct {
 	Namespace string `json:"namespace"`
 	Port      int32  `json:"port"`
 	Weight    *int   `json:"weight,omitempty"`
+	// Servers sets the weight of the servers of some pods.
+	Servers []Server `json:"servers,omitempty"`
 }
 
 // +genclient
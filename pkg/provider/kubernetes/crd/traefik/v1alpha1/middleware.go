@@ -20,28 +20,33 @@ type Middleware struct {
 
 // MiddlewareSpec holds the Middleware configuration.
 type MiddlewareSpec struct {
-	AddPrefix         *dynamic.AddPrefix         `json:"addPrefix,omitempty"`
-	StripPrefix       *dynamic.StripPrefix       `json:"stripPrefix,omitempty"`
-	StripPrefixRegex  *dynamic.StripPrefixRegex  `json:"stripPrefixRegex,omitempty"`
-	ReplacePath       *dynamic.ReplacePath       `json:"replacePath,omitempty"`
-	ReplacePathRegex  *dynamic.ReplacePathRegex  `json:"replacePathRegex,omitempty"`
-	Chain             *Chain                     `json:"chain,omitempty"`
-	IPWhiteList       *dynamic.IPWhiteList       `json:"ipWhiteList,omitempty"`
-	Headers           *dynamic.Headers           `json:"headers,omitempty"`
-	Errors            *ErrorPage                 `json:"errors,omitempty"`
-	RateLimit         *dynamic.RateLimit         `json:"rateLimit,omitempty"`
-	RedirectRegex     *dynamic.RedirectRegex     `json:"redirectRegex,omitempty"`
-	RedirectScheme    *dynamic.RedirectScheme    `json:"redirectScheme,omitempty"`
-	BasicAuth         *BasicAuth                 `json:"basicAuth,omitempty"`
-	DigestAuth        *DigestAuth                `json:"digestAuth,omitempty"`
-	ForwardAuth       *ForwardAuth               `json:"forwardAuth,omitempty"`
-	InFlightReq       *dynamic.InFlightReq       `json:"inFlightReq,omitempty"`
-	Buffering         *dynamic.Buffering         `json:"buffering,omitempty"`
-	CircuitBreaker    *dynamic.CircuitBreaker    `json:"circuitBreaker,omitempty"`
-	Compress          *dynamic.Compress          `json:"compress,omitempty"`
-	PassTLSClientCert *dynamic.PassTLSClientCert `json:"passTLSClientCert,omitempty"`
-	Retry             *dynamic.Retry             `json:"retry,omitempty"`
-	ContentType       *dynamic.ContentType       `json:"contentType,omitempty"`
+	AddPrefix           *dynamic.AddPrefix           `json:"addPrefix,omitempty"`
+	StripPrefix         *dynamic.StripPrefix         `json:"stripPrefix,omitempty"`
+	StripPrefixRegex    *dynamic.StripPrefixRegex    `json:"stripPrefixRegex,omitempty"`
+	ReplacePath         *dynamic.ReplacePath         `json:"replacePath,omitempty"`
+	ReplacePathRegex    *dynamic.ReplacePathRegex    `json:"replacePathRegex,omitempty"`
+	Chain               *Chain                       `json:"chain,omitempty"`
+	IPWhiteList         *dynamic.IPWhiteList         `json:"ipWhiteList,omitempty"`
+	Headers             *dynamic.Headers             `json:"headers,omitempty"`
+	Errors              *ErrorPage                   `json:"errors,omitempty"`
+	RateLimit           *dynamic.RateLimit           `json:"rateLimit,omitempty"`
+	RedirectRegex       *dynamic.RedirectRegex       `json:"redirectRegex,omitempty"`
+	RedirectScheme      *dynamic.RedirectScheme      `json:"redirectScheme,omitempty"`
+	BasicAuth           *BasicAuth                   `json:"basicAuth,omitempty"`
+	DigestAuth          *DigestAuth                  `json:"digestAuth,omitempty"`
+	ForwardAuth         *ForwardAuth                 `json:"forwardAuth,omitempty"`
+	JWT                 *dynamic.JWT                 `json:"jwt,omitempty"`
+	OIDC                *dynamic.OIDC                `json:"oidc,omitempty"`
+	APIKey              *APIKey                      `json:"apiKey,omitempty"`
+	InFlightReq         *dynamic.InFlightReq         `json:"inFlightReq,omitempty"`
+	AdaptiveInFlightReq *dynamic.AdaptiveInFlightReq `json:"adaptiveInFlightReq,omitempty"`
+	Buffering           *dynamic.Buffering           `json:"buffering,omitempty"`
+	Cache               *dynamic.Cache               `json:"cache,omitempty"`
+	CircuitBreaker      *dynamic.CircuitBreaker      `json:"circuitBreaker,omitempty"`
+	Compress            *dynamic.Compress            `json:"compress,omitempty"`
+	PassTLSClientCert   *dynamic.PassTLSClientCert   `json:"passTLSClientCert,omitempty"`
+	Retry               *dynamic.Retry               `json:"retry,omitempty"`
+	ContentType         *dynamic.ContentType         `json:"contentType,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -72,6 +77,18 @@ type BasicAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// APIKey holds the API key authentication configuration.
+type APIKey struct {
+	Secret      string                         `json:"secret,omitempty"`
+	Header      string                         `json:"header,omitempty"`
+	Query       string                         `json:"query,omitempty"`
+	HeaderField string                         `json:"headerField,omitempty"`
+	RemoveKey   bool                           `json:"removeKey,omitempty"`
+	Tiers       map[string]*dynamic.APIKeyTier `json:"tiers,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // DigestAuth holds the Digest HTTP authentication configuration.
 type DigestAuth struct {
 	Secret       string `json:"secret,omitempty"`
@@ -84,10 +101,15 @@ type DigestAuth struct {
 
 // ForwardAuth holds the http forward authentication configuration.
 type ForwardAuth struct {
-	Address             string     `json:"address,omitempty"`
-	TrustForwardHeader  bool       `json:"trustForwardHeader,omitempty"`
-	AuthResponseHeaders []string   `json:"authResponseHeaders,omitempty"`
-	TLS                 *ClientTLS `json:"tls,omitempty"`
+	Address             string                    `json:"address,omitempty"`
+	TrustForwardHeader  bool                      `json:"trustForwardHeader,omitempty"`
+	AuthResponseHeaders []string                  `json:"authResponseHeaders,omitempty"`
+	AuthRequestHeaders  []string                  `json:"authRequestHeaders,omitempty"`
+	Method              string                    `json:"method,omitempty"`
+	ForwardBody         bool                      `json:"forwardBody,omitempty"`
+	MaxBodySize         int64                     `json:"maxBodySize,omitempty"`
+	Cache               *dynamic.ForwardAuthCache `json:"cache,omitempty"`
+	TLS                 *ClientTLS                `json:"tls,omitempty"`
 }
 
 // ClientTLS holds TLS specific configurations as client.
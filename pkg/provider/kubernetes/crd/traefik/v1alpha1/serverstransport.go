@@ -0,0 +1,47 @@
+package v1alpha1
+
+import (
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServersTransport is a specification for a ServersTransport resource.
+type ServersTransport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+
+	Spec ServersTransportSpec `json:"spec"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ServersTransportSpec options to configure communication between Traefik and the servers.
+type ServersTransportSpec struct {
+	// ServerName used to contact the server.
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables SSL certificate verification.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// RootCAsSecrets are the names of the secrets holding the root CAs used to verify the servers' certificates, each as their single entry.
+	RootCAsSecrets []string `json:"rootCAsSecrets,omitempty"`
+	// CertificatesSecrets are the names of the secrets holding the client certificates (tls.crt and tls.key entries) used for mTLS.
+	CertificatesSecrets []string `json:"certificatesSecrets,omitempty"`
+	// MaxIdleConnsPerHost controls the maximum idle (keep-alive) connections to keep per-host.
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"`
+	// ForwardingTimeouts are the timeouts for requests forwarded to the servers.
+	ForwardingTimeouts *dynamic.ForwardingTimeouts `json:"forwardingTimeouts,omitempty"`
+	// DisableHTTP2 disables HTTP/2 for connections with the servers.
+	DisableHTTP2 bool `json:"disableHTTP2,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ServersTransportList is a list of ServersTransport resources.
+type ServersTransportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []ServersTransport `json:"items"`
+}
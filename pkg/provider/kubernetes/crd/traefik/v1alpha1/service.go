@@ -31,11 +31,12 @@ type TraefikServiceList struct {
 
 // +k8s:deepcopy-gen=true
 
-// ServiceSpec defines whether a TraefikService is a load-balancer of services or a
-// mirroring service.
+// ServiceSpec defines whether a TraefikService is a load-balancer of services, a
+// mirroring service, or a failover service.
 type ServiceSpec struct {
 	Weighted  *WeightedRoundRobin `json:"weighted,omitempty"`
 	Mirroring *Mirroring          `json:"mirroring,omitempty"`
+	Failover  *Failover           `json:"failover,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -45,7 +46,18 @@ type ServiceSpec struct {
 type Mirroring struct {
 	LoadBalancerSpec
 	MaxBodySize *int64
-	Mirrors     []MirrorService `json:"mirrors,omitempty"`
+	Mirrors     []MirrorService        `json:"mirrors,omitempty"`
+	Compare     *dynamic.MirrorCompare `json:"compare,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Failover defines a failover service, which sends the requests to a main service,
+// and to a fallback service when all the servers of the main one are down.
+type Failover struct {
+	Service  LoadBalancerSpec        `json:"service"`
+	Fallback LoadBalancerSpec        `json:"fallback"`
+	Errors   *dynamic.FailoverErrors `json:"errors,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
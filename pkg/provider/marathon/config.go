@@ -281,6 +281,7 @@ func (p *Provider) getTCPServer(app marathon.Application, task marathon.Task, ex
 
 	server := dynamic.TCPServer{
 		Address: net.JoinHostPort(host, port),
+		Weight:  defaultServer.Weight,
 	}
 
 	return server, nil
@@ -299,6 +300,7 @@ func (p *Provider) getUDPServer(app marathon.Application, task marathon.Task, ex
 
 	server := dynamic.UDPServer{
 		Address: net.JoinHostPort(host, port),
+		Weight:  defaultServer.Weight,
 	}
 
 	return server, nil
@@ -316,7 +318,8 @@ func (p *Provider) getServer(app marathon.Application, task marathon.Task, extra
 	}
 
 	server := dynamic.Server{
-		URL: fmt.Sprintf("%s://%s", defaultServer.Scheme, net.JoinHostPort(host, port)),
+		URL:    fmt.Sprintf("%s://%s", defaultServer.Scheme, net.JoinHostPort(host, port)),
+		Weight: defaultServer.Weight,
 	}
 
 	return server, nil
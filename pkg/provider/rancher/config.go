@@ -212,6 +212,7 @@ func (p *Provider) addServerTCP(ctx context.Context, service rancherData, loadBa
 	for _, containerIP := range service.Containers {
 		servers = append(servers, dynamic.TCPServer{
 			Address: net.JoinHostPort(containerIP, port),
+			Weight:  loadBalancer.Servers[0].Weight,
 		})
 	}
 
@@ -249,6 +250,7 @@ func (p *Provider) addServerUDP(ctx context.Context, service rancherData, loadBa
 	for _, containerIP := range service.Containers {
 		servers = append(servers, dynamic.UDPServer{
 			Address: net.JoinHostPort(containerIP, port),
+			Weight:  loadBalancer.Servers[0].Weight,
 		})
 	}
 
@@ -281,7 +283,8 @@ func (p *Provider) addServers(ctx context.Context, service rancherData, loadBala
 	var servers []dynamic.Server
 	for _, containerIP := range service.Containers {
 		servers = append(servers, dynamic.Server{
-			URL: fmt.Sprintf("%s://%s", loadBalancer.Servers[0].Scheme, net.JoinHostPort(containerIP, port)),
+			URL:    fmt.Sprintf("%s://%s", loadBalancer.Servers[0].Scheme, net.JoinHostPort(containerIP, port)),
+			Weight: loadBalancer.Servers[0].Weight,
 		})
 	}
 
@@ -9,9 +9,13 @@ import (
 
 	"github.com/containous/alice"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/containous/traefik/v2/pkg/middlewares/adaptiveinflightreq"
 	"github.com/containous/traefik/v2/pkg/middlewares/addprefix"
 	"github.com/containous/traefik/v2/pkg/middlewares/auth"
 	"github.com/containous/traefik/v2/pkg/middlewares/buffering"
+	"github.com/containous/traefik/v2/pkg/middlewares/cache"
 	"github.com/containous/traefik/v2/pkg/middlewares/chain"
 	"github.com/containous/traefik/v2/pkg/middlewares/circuitbreaker"
 	"github.com/containous/traefik/v2/pkg/middlewares/compress"
@@ -29,6 +33,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/middlewares/stripprefixregex"
 	"github.com/containous/traefik/v2/pkg/middlewares/tracing"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 )
 
 type middlewareStackType int
@@ -39,8 +44,10 @@ const (
 
 // Builder the middleware builder.
 type Builder struct {
-	configs        map[string]*runtime.MiddlewareInfo
-	serviceBuilder serviceBuilder
+	configs         map[string]*runtime.MiddlewareInfo
+	serviceBuilder  serviceBuilder
+	metricsRegistry metrics.Registry
+	cacheStores     *cache.Stores
 }
 
 type serviceBuilder interface {
@@ -48,8 +55,13 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder}
+func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{configs: configs, serviceBuilder: serviceBuilder, metricsRegistry: metricsRegistry}
+}
+
+// SetCacheStores sets the stores of the cache middlewares, shared with the builders of the previous configurations.
+func (b *Builder) SetCacheStores(stores *cache.Stores) {
+	b.cacheStores = stores
 }
 
 // BuildChain creates a middleware chain.
@@ -136,6 +148,22 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// Cache
+	if config.Cache != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+
+		var requestsCounter gokitmetrics.Counter
+		if b.metricsRegistry != nil && b.metricsRegistry.IsMdlwEnabled() {
+			requestsCounter = b.metricsRegistry.MiddlewareCacheRequestsCounter()
+		}
+
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return cache.New(ctx, next, *config.Cache, b.cacheStores, requestsCounter, middlewareName)
+		}
+	}
+
 	// Chain
 	if config.Chain != nil {
 		if middleware != nil {
@@ -247,6 +275,52 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// AdaptiveInFlightReq
+	if config.AdaptiveInFlightReq != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+
+		var limitGauge gokitmetrics.Gauge
+		if b.metricsRegistry != nil && b.metricsRegistry.IsMdlwEnabled() {
+			limitGauge = b.metricsRegistry.MiddlewareConcurrencyLimitGauge()
+		}
+
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return adaptiveinflightreq.New(ctx, next, *config.AdaptiveInFlightReq, limitGauge, middlewareName)
+		}
+	}
+
+	// JWT
+	if config.JWT != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewJWT(ctx, next, *config.JWT, middlewareName)
+		}
+	}
+
+	// OIDC
+	if config.OIDC != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewOIDC(ctx, next, *config.OIDC, middlewareName)
+		}
+	}
+
+	// APIKey
+	if config.APIKey != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return auth.NewAPIKey(ctx, next, *config.APIKey, middlewareName)
+		}
+	}
+
 	// PassTLSClientCert
 	if config.PassTLSClientCert != nil {
 		if middleware != nil {
@@ -313,8 +387,8 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			// FIXME missing metrics / accessLog
-			return retry.New(ctx, next, *config.Retry, retry.Listeners{}, middlewareName)
+			// FIXME missing metrics
+			return retry.New(ctx, next, *config.Retry, retry.Listeners{&accesslog.SaveRetries{}}, middlewareName)
 		}
 	}
 
@@ -3,16 +3,20 @@ package server
 import (
 	"context"
 
+	"github.com/containous/traefik/v2/pkg/affinity"
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/metrics"
+	"github.com/containous/traefik/v2/pkg/middlewares/cache"
 	"github.com/containous/traefik/v2/pkg/responsemodifiers"
 	"github.com/containous/traefik/v2/pkg/server/middleware"
 	"github.com/containous/traefik/v2/pkg/server/router"
 	routertcp "github.com/containous/traefik/v2/pkg/server/router/tcp"
 	routerudp "github.com/containous/traefik/v2/pkg/server/router/udp"
 	"github.com/containous/traefik/v2/pkg/server/service"
+	"github.com/containous/traefik/v2/pkg/server/service/resolver"
 	"github.com/containous/traefik/v2/pkg/server/service/tcp"
 	"github.com/containous/traefik/v2/pkg/server/service/udp"
 	tcpCore "github.com/containous/traefik/v2/pkg/tcp"
@@ -25,14 +29,25 @@ type RouterFactory struct {
 	entryPointsTCP []string
 	entryPointsUDP []string
 
-	managerFactory *service.ManagerFactory
+	managerFactory  *service.ManagerFactory
+	metricsRegistry metrics.Registry
+
+	// The affinity tables of the TCP and UDP services, kept across configuration reloads.
+	tcpAffinityTables *affinity.Tables
+	udpAffinityTables *affinity.Tables
+
+	// The watchers of the host names of the TCP and UDP servers, stopped at each configuration reload.
+	dnsWatchers *resolver.Watchers
+
+	// The stores of the cache middlewares, kept across configuration reloads.
+	cacheStores *cache.Stores
 
 	chainBuilder *middleware.ChainBuilder
 	tlsManager   *tls.Manager
 }
 
 // NewRouterFactory creates a new RouterFactory.
-func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager, chainBuilder *middleware.ChainBuilder) *RouterFactory {
+func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager, chainBuilder *middleware.ChainBuilder, metricsRegistry metrics.Registry) *RouterFactory {
 	var entryPointsTCP, entryPointsUDP []string
 	for name, cfg := range staticConfiguration.EntryPoints {
 		protocol, err := cfg.GetProtocol()
@@ -49,11 +64,16 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 	}
 
 	return &RouterFactory{
-		entryPointsTCP: entryPointsTCP,
-		entryPointsUDP: entryPointsUDP,
-		managerFactory: managerFactory,
-		tlsManager:     tlsManager,
-		chainBuilder:   chainBuilder,
+		entryPointsTCP:    entryPointsTCP,
+		entryPointsUDP:    entryPointsUDP,
+		managerFactory:    managerFactory,
+		metricsRegistry:   metricsRegistry,
+		tlsManager:        tlsManager,
+		chainBuilder:      chainBuilder,
+		tcpAffinityTables: affinity.NewTables(),
+		udpAffinityTables: affinity.NewTables(),
+		dnsWatchers:       resolver.NewWatchers(resolver.NewResolver(nil)),
+		cacheStores:       cache.NewStores(),
 	}
 }
 
@@ -64,9 +84,14 @@ func (f *RouterFactory) CreateRouters(conf dynamic.Configuration) (map[string]*t
 	rtConf := runtime.NewConfig(conf)
 
 	// HTTP
+	if conf.HTTP != nil {
+		f.managerFactory.UpdateServersTransports(conf.HTTP.ServersTransports)
+	}
 	serviceManager := f.managerFactory.Build(rtConf)
 
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.metricsRegistry)
+	f.cacheStores.NewGeneration()
+	middlewaresBuilder.SetCacheStores(f.cacheStores)
 	responseModifierFactory := responsemodifiers.NewBuilder(rtConf.Middlewares)
 
 	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, responseModifierFactory, f.chainBuilder)
@@ -76,17 +101,29 @@ func (f *RouterFactory) CreateRouters(conf dynamic.Configuration) (map[string]*t
 
 	serviceManager.LaunchHealthCheck()
 
+	f.dnsWatchers.NewGeneration()
+
 	// TCP
 	svcTCPManager := tcp.NewManager(rtConf)
+	f.tcpAffinityTables.NewGeneration()
+	svcTCPManager.SetAffinityTables(f.tcpAffinityTables)
+	svcTCPManager.SetDNSWatchers(f.dnsWatchers)
 
 	rtTCPManager := routertcp.NewManager(rtConf, svcTCPManager, handlersNonTLS, handlersTLS, f.tlsManager)
 	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
 
+	svcTCPManager.LaunchHealthCheck()
+
 	// UDP
 	svcUDPManager := udp.NewManager(rtConf)
+	f.udpAffinityTables.NewGeneration()
+	svcUDPManager.SetAffinityTables(f.udpAffinityTables)
+	svcUDPManager.SetDNSWatchers(f.dnsWatchers)
 	rtUDPManager := routerudp.NewManager(rtConf, svcUDPManager)
 	routersUDP := rtUDPManager.BuildHandlers(ctx, f.entryPointsUDP)
 
+	svcUDPManager.LaunchHealthCheck()
+
 	rtConf.PopulateUsedBy()
 
 	return routersTCP, routersUDP
@@ -0,0 +1,230 @@
+package failover
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer"
+	"github.com/containous/traefik/v2/pkg/types"
+)
+
+// Failover is an http.Handler sending the requests to a main handler,
+// and to a fallback handler when the main one is down,
+// or when it responds with one of the configured error status codes.
+type Failover struct {
+	handler         http.Handler
+	fallbackHandler http.Handler
+
+	// statuses are the status codes of the main handler responses that trigger the fallback.
+	statuses    types.HTTPCodeRanges
+	maxBodySize int64
+
+	statusMu       sync.RWMutex
+	handlerStatus  bool
+	fallbackStatus bool
+	// updaters are called whenever the status of the Failover changes,
+	// i.e. when both of its handlers are down, or when one of them is back up.
+	updaters []func(up bool)
+}
+
+// New creates a new Failover.
+// When statuses is not empty, the requests answered by the main handler with one of these status codes are sent again to the fallback handler,
+// provided that their body is not larger than maxBodySize (-1 for unlimited).
+func New(handler, fallbackHandler http.Handler, statuses types.HTTPCodeRanges, maxBodySize int64) *Failover {
+	return &Failover{
+		handler:         handler,
+		fallbackHandler: fallbackHandler,
+		statuses:        statuses,
+		maxBodySize:     maxBodySize,
+		handlerStatus:   true,
+		fallbackStatus:  true,
+	}
+}
+
+// RegisterStatusUpdater adds fn to the list of functions called when the status of the Failover changes.
+// As the Failover is assumed to be up, fn is called right away if both of its handlers are down.
+func (f *Failover) RegisterStatusUpdater(fn func(up bool)) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+
+	f.updaters = append(f.updaters, fn)
+
+	if !f.handlerStatus && !f.fallbackStatus {
+		fn(false)
+	}
+}
+
+// SetHandlerStatus sets the status (UP or DOWN) of the main handler.
+func (f *Failover) SetHandlerStatus(ctx context.Context, up bool) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+
+	if f.handlerStatus != up {
+		log.FromContext(ctx).Debugf("Failover: main service status changed, up: %v", up)
+	}
+
+	upBefore := f.handlerStatus || f.fallbackStatus
+	f.handlerStatus = up
+	f.propagate(ctx, upBefore)
+}
+
+// SetFallbackHandlerStatus sets the status (UP or DOWN) of the fallback handler.
+func (f *Failover) SetFallbackHandlerStatus(ctx context.Context, up bool) {
+	f.statusMu.Lock()
+	defer f.statusMu.Unlock()
+
+	if f.fallbackStatus != up {
+		log.FromContext(ctx).Debugf("Failover: fallback service status changed, up: %v", up)
+	}
+
+	upBefore := f.handlerStatus || f.fallbackStatus
+	f.fallbackStatus = up
+	f.propagate(ctx, upBefore)
+}
+
+// propagate must be called with the lock held.
+func (f *Failover) propagate(ctx context.Context, upBefore bool) {
+	upAfter := f.handlerStatus || f.fallbackStatus
+	if upBefore == upAfter {
+		return
+	}
+
+	log.FromContext(ctx).Debugf("Failover: propagating new status, up: %v", upAfter)
+	for _, fn := range f.updaters {
+		fn(upAfter)
+	}
+}
+
+func (f *Failover) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.statusMu.RLock()
+	handlerStatus := f.handlerStatus
+	fallbackStatus := f.fallbackStatus
+	f.statusMu.RUnlock()
+
+	if !handlerStatus {
+		if !fallbackStatus {
+			http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		f.fallbackHandler.ServeHTTP(rw, req)
+		return
+	}
+
+	if len(f.statuses) == 0 || !fallbackStatus {
+		f.handler.ServeHTTP(rw, req)
+		return
+	}
+
+	logger := log.FromContext(req.Context())
+
+	rr, bytesRead, err := loadbalancer.NewReusableRequest(req, f.maxBodySize)
+	if err != nil && err != loadbalancer.ErrBodyTooLarge {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError)+
+			fmt.Sprintf("error creating reusable request: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if err == loadbalancer.ErrBodyTooLarge {
+		req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(bytesRead), req.Body))
+		f.handler.ServeHTTP(rw, req)
+		logger.Debug("Failover: no fallback on error, request body larger than allowed size")
+		return
+	}
+
+	recorder := newResponseWriter(rw, f.statuses)
+	f.handler.ServeHTTP(recorder, rr.Clone(req.Context()))
+	if !recorder.failed {
+		return
+	}
+
+	logger.Debugf("Failover: main service responded with status %d, sending the request to the fallback service", recorder.status)
+	f.fallbackHandler.ServeHTTP(rw, rr.Clone(req.Context()))
+}
+
+// responseWriter forwards the response of the main handler to the client,
+// unless its status code is one of the statuses triggering the fallback, in which case the response is discarded.
+type responseWriter struct {
+	rw       http.ResponseWriter
+	header   http.Header
+	statuses types.HTTPCodeRanges
+
+	wroteHeader bool
+	status      int
+	failed      bool
+}
+
+func newResponseWriter(rw http.ResponseWriter, statuses types.HTTPCodeRanges) *responseWriter {
+	return &responseWriter{
+		rw:       rw,
+		header:   make(http.Header),
+		statuses: statuses,
+	}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+	w.status = statusCode
+
+	if w.statuses.Contains(statusCode) {
+		w.failed = true
+		return
+	}
+
+	for k, v := range w.header {
+		w.rw.Header()[k] = v
+	}
+	w.rw.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.failed {
+		return len(b), nil
+	}
+
+	return w.rw.Write(b)
+}
+
+// Flush sends any buffered data to the client.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.failed {
+		return
+	}
+
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.rw)
+	}
+	return hijacker.Hijack()
+}
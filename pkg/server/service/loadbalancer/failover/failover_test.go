@@ -0,0 +1,146 @@
+package failover
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover(t *testing.T) {
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "handler")
+		rw.WriteHeader(http.StatusOK)
+	})
+	fallback := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "fallback")
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	failover := New(handler, fallback, nil, -1)
+
+	recorder := httptest.NewRecorder()
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "handler", recorder.Header().Get("server"))
+
+	failover.SetHandlerStatus(context.Background(), false)
+
+	recorder = httptest.NewRecorder()
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "fallback", recorder.Header().Get("server"))
+
+	failover.SetFallbackHandlerStatus(context.Background(), false)
+
+	recorder = httptest.NewRecorder()
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	failover.SetHandlerStatus(context.Background(), true)
+
+	recorder = httptest.NewRecorder()
+	failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "handler", recorder.Header().Get("server"))
+}
+
+func TestFailoverOnErrors(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		handlerStatus  int
+		maxBodySize    int64
+		body           string
+		expectedServer string
+		expectedStatus int
+	}{
+		{
+			desc:           "success",
+			handlerStatus:  http.StatusOK,
+			maxBodySize:    -1,
+			body:           "foo",
+			expectedServer: "handler",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "error not matching the statuses",
+			handlerStatus:  http.StatusNotFound,
+			maxBodySize:    -1,
+			body:           "foo",
+			expectedServer: "handler",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "error matching the statuses",
+			handlerStatus:  http.StatusBadGateway,
+			maxBodySize:    -1,
+			body:           "foo",
+			expectedServer: "fallback",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "error matching the statuses with a body within limits",
+			handlerStatus:  http.StatusBadGateway,
+			maxBodySize:    3,
+			body:           "foo",
+			expectedServer: "fallback",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "error matching the statuses with a body too large",
+			handlerStatus:  http.StatusBadGateway,
+			maxBodySize:    2,
+			body:           "foo",
+			expectedServer: "handler",
+			expectedStatus: http.StatusBadGateway,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.body, string(body))
+
+				rw.Header().Set("server", "handler")
+				rw.WriteHeader(test.handlerStatus)
+				_, _ = rw.Write([]byte("handler"))
+			})
+			fallback := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := ioutil.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, test.body, string(body))
+
+				rw.Header().Set("fallback", "true")
+				rw.Header().Set("server", "fallback")
+				rw.WriteHeader(http.StatusOK)
+				_, _ = rw.Write([]byte("fallback"))
+			})
+
+			statuses, err := types.NewHTTPCodeRanges([]string{"500-599"})
+			require.NoError(t, err)
+
+			failover := New(handler, fallback, statuses, test.maxBodySize)
+
+			recorder := httptest.NewRecorder()
+			failover.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body)))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedServer, recorder.Header().Get("server"))
+			assert.Equal(t, test.expectedServer, recorder.Body.String())
+			if test.expectedServer == "handler" {
+				assert.Empty(t, recorder.Header().Get("fallback"))
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package hashring
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/ip"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+// digestsPerWeight is the number of MD5 digests computed for each unit of weight of a server.
+// As in ketama, each digest yields 4 points on the ring, hence 160 points per unit of weight.
+const digestsPerWeight = 40
+
+type server struct {
+	url    *url.URL
+	weight int
+}
+
+type point struct {
+	hash   uint32
+	server *server
+}
+
+// Balancer is a consistent hashing load-balancer of servers, based on a ketama hash ring.
+// Requests with the same hash key are sent to the same server,
+// and adding or removing a server only moves the keys that belonged to (or now belong to) that server.
+type Balancer struct {
+	next          http.Handler
+	stickySession *roundrobin.StickySession
+	keyFunc       func(req *http.Request) string
+
+	mutex   sync.RWMutex
+	servers []*server
+	ring    []point
+}
+
+// New creates a new consistent hashing Balancer,
+// which takes the hash key of each request from the criterion defined in config.
+func New(next http.Handler, stickySession *roundrobin.StickySession, config *dynamic.ConsistentHash) (*Balancer, error) {
+	keyFunc, err := newKeyFunc(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Balancer{
+		next:          next,
+		stickySession: stickySession,
+		keyFunc:       keyFunc,
+	}, nil
+}
+
+// newKeyFunc returns the function extracting the hash key of a request.
+// When the configured criterion is missing from a request, the client IP is used instead.
+func newKeyFunc(config *dynamic.ConsistentHash) (func(req *http.Request) string, error) {
+	if config == nil {
+		config = &dynamic.ConsistentHash{}
+	}
+
+	var count int
+	for _, set := range []bool{config.IPStrategy != nil, config.RequestHeaderName != "", config.RequestCookieName != "", config.RequestQueryParameter != ""} {
+		if set {
+			count++
+		}
+	}
+	if count > 1 {
+		return nil, errors.New("ipStrategy, requestHeaderName, requestCookieName and requestQueryParameter are mutually exclusive")
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	remoteAddr := &ip.RemoteAddrStrategy{}
+
+	switch {
+	case config.RequestHeaderName != "":
+		return func(req *http.Request) string {
+			if value := req.Header.Get(config.RequestHeaderName); value != "" {
+				return value
+			}
+			return remoteAddr.GetIP(req)
+		}, nil
+	case config.RequestCookieName != "":
+		return func(req *http.Request) string {
+			if cookie, err := req.Cookie(config.RequestCookieName); err == nil && cookie.Value != "" {
+				return cookie.Value
+			}
+			return remoteAddr.GetIP(req)
+		}, nil
+	case config.RequestQueryParameter != "":
+		return func(req *http.Request) string {
+			if value := req.URL.Query().Get(config.RequestQueryParameter); value != "" {
+				return value
+			}
+			return remoteAddr.GetIP(req)
+		}, nil
+	default:
+		return strategy.GetIP, nil
+	}
+}
+
+func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// make a shallow copy of the request before changing anything to avoid side effects.
+	newReq := *req
+
+	if b.stickySession != nil {
+		cookieURL, present, err := b.stickySession.GetBackend(&newReq, b.Servers())
+		if err != nil {
+			log.WithoutContext().Warnf("Error while reading sticky cookie: %v", err)
+		}
+
+		if present {
+			newReq.URL = cookieURL
+			b.next.ServeHTTP(w, &newReq)
+			return
+		}
+	}
+
+	srv, err := b.nextServer(b.keyFunc(req))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	if b.stickySession != nil {
+		b.stickySession.StickBackend(srv.url, &w)
+	}
+
+	newReq.URL = utils.CopyURL(srv.url)
+	b.next.ServeHTTP(w, &newReq)
+}
+
+// nextServer returns the first server found on the ring, clockwise from the hash of the key.
+func (b *Balancer) nextServer(key string) (*server, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if len(b.ring) == 0 {
+		return nil, fmt.Errorf("no servers in the pool")
+	}
+
+	hash := keyHash(key)
+	index := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= hash })
+	if index == len(b.ring) {
+		index = 0
+	}
+
+	return b.ring[index].server, nil
+}
+
+// buildRing must be called with the write lock held.
+func (b *Balancer) buildRing() {
+	var ring []point
+	for _, srv := range b.servers {
+		for i := 0; i < digestsPerWeight*srv.weight; i++ {
+			digest := md5.Sum([]byte(srv.url.String() + "-" + strconv.Itoa(i)))
+			for j := 0; j < 4; j++ {
+				ring = append(ring, point{hash: binary.LittleEndian.Uint32(digest[j*4:]), server: srv})
+			}
+		}
+	}
+
+	sort.Slice(ring, func(i, j int) bool { return ring[i].hash < ring[j].hash })
+	b.ring = ring
+}
+
+func keyHash(key string) uint32 {
+	digest := md5.Sum([]byte(key))
+	return binary.LittleEndian.Uint32(digest[:4])
+}
+
+// findServerByURL must be called with the lock held.
+func (b *Balancer) findServerByURL(u *url.URL) (*server, int) {
+	index := loadbalancer.FindURL(len(b.servers), func(i int) *url.URL { return b.servers[i].url }, u)
+	if index == -1 {
+		return nil, -1
+	}
+	return b.servers[index], index
+}
+
+// Servers returns the list of servers the balancer is currently sending requests to.
+func (b *Balancer) Servers() []*url.URL {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	out := make([]*url.URL, len(b.servers))
+	for i, srv := range b.servers {
+		out[i] = srv.url
+	}
+	return out
+}
+
+// ServerWeight returns the weight of the server identified by the given URL.
+func (b *Balancer) ServerWeight(u *url.URL) (int, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if srv, _ := b.findServerByURL(u); srv != nil {
+		return srv.weight, true
+	}
+	return -1, false
+}
+
+// RemoveServer removes the given server from the balancer, and from the hash ring.
+func (b *Balancer) RemoveServer(u *url.URL) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	_, index := b.findServerByURL(u)
+	if index == -1 {
+		return fmt.Errorf("server not found")
+	}
+
+	b.servers = append(b.servers[:index], b.servers[index+1:]...)
+	b.buildRing()
+	return nil
+}
+
+// UpsertServer adds the given server to the balancer,
+// or updates its weight (i.e. its share of the hash ring) if it is already known.
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if u == nil {
+		return fmt.Errorf("server URL can't be nil")
+	}
+
+	weight, err := loadbalancer.ServerWeight(u, options...)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if srv, _ := b.findServerByURL(u); srv != nil {
+		if srv.weight != weight {
+			srv.weight = weight
+			b.buildRing()
+		}
+		return nil
+	}
+
+	b.servers = append(b.servers, &server{url: utils.CopyURL(u), weight: weight})
+	b.buildRing()
+	return nil
+}
@@ -0,0 +1,193 @@
+package hashring
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u
+}
+
+func TestNew_MutuallyExclusiveCriteria(t *testing.T) {
+	_, err := New(http.NotFoundHandler(), nil, &dynamic.ConsistentHash{
+		RequestHeaderName: "X-Key",
+		RequestCookieName: "key",
+	})
+	assert.Error(t, err)
+}
+
+func TestBalancer_Criteria(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		config  *dynamic.ConsistentHash
+		withKey func(req *http.Request, key string)
+	}{
+		{
+			desc:   "request header",
+			config: &dynamic.ConsistentHash{RequestHeaderName: "X-Key"},
+			withKey: func(req *http.Request, key string) {
+				req.Header.Set("X-Key", key)
+			},
+		},
+		{
+			desc:   "request cookie",
+			config: &dynamic.ConsistentHash{RequestCookieName: "key"},
+			withKey: func(req *http.Request, key string) {
+				req.AddCookie(&http.Cookie{Name: "key", Value: key})
+			},
+		},
+		{
+			desc:   "request query parameter",
+			config: &dynamic.ConsistentHash{RequestQueryParameter: "key"},
+			withKey: func(req *http.Request, key string) {
+				req.URL.RawQuery = url.Values{"key": {key}}.Encode()
+			},
+		},
+		{
+			desc:   "client IP",
+			config: nil,
+			withKey: func(req *http.Request, key string) {
+				req.RemoteAddr = key + ":1234"
+			},
+		},
+		{
+			desc:   "forwarded client IP",
+			config: &dynamic.ConsistentHash{IPStrategy: &dynamic.IPStrategy{Depth: 1}},
+			withKey: func(req *http.Request, key string) {
+				req.Header.Set("X-Forwarded-For", key)
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var got string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				got = req.URL.Host
+			})
+
+			balancer, err := New(next, nil, test.config)
+			require.NoError(t, err)
+
+			for i := 0; i < 5; i++ {
+				require.NoError(t, balancer.UpsertServer(mustParse(t, fmt.Sprintf("http://server%d", i))))
+			}
+
+			seen := map[string]struct{}{}
+			for _, key := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"} {
+				var first string
+				for i := 0; i < 3; i++ {
+					req := httptest.NewRequest(http.MethodGet, "/", nil)
+					test.withKey(req, key)
+					balancer.ServeHTTP(httptest.NewRecorder(), req)
+
+					if first == "" {
+						first = got
+					}
+					assert.Equal(t, first, got, "key %s", key)
+				}
+				seen[first] = struct{}{}
+			}
+
+			assert.Greater(t, len(seen), 1)
+		})
+	}
+}
+
+func TestBalancer_RemoveServerMovesFewKeys(t *testing.T) {
+	var got string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req.URL.Host
+	})
+
+	balancer, err := New(next, nil, &dynamic.ConsistentHash{RequestHeaderName: "X-Key"})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, balancer.UpsertServer(mustParse(t, fmt.Sprintf("http://server%d", i))))
+	}
+
+	route := func() map[string]string {
+		routes := map[string]string{}
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Key", key)
+			balancer.ServeHTTP(httptest.NewRecorder(), req)
+			routes[key] = got
+		}
+		return routes
+	}
+
+	before := route()
+	require.NoError(t, balancer.RemoveServer(mustParse(t, "http://server3")))
+	after := route()
+
+	var moved int
+	for key, host := range before {
+		if host == "server3" {
+			assert.NotEqual(t, "server3", after[key])
+			continue
+		}
+		if after[key] != host {
+			moved++
+		}
+	}
+
+	assert.Zero(t, moved, "only the keys of the removed server should move")
+
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://server3")))
+	assert.Equal(t, before, route())
+}
+
+func TestBalancer_Weights(t *testing.T) {
+	counts := map[string]int{}
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		counts[req.URL.Host]++
+	})
+
+	balancer, err := New(next, nil, &dynamic.ConsistentHash{RequestHeaderName: "X-Key"})
+	require.NoError(t, err)
+
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://first"), roundrobin.Weight(3)))
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://second"), roundrobin.Weight(1)))
+
+	weight, ok := balancer.ServerWeight(mustParse(t, "http://first"))
+	assert.True(t, ok)
+	assert.Equal(t, 3, weight)
+
+	for i := 0; i < 4000; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Key", fmt.Sprintf("key-%d", i))
+		balancer.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.InDelta(t, 3000, counts["first"], 300)
+	assert.InDelta(t, 1000, counts["second"], 300)
+}
+
+func TestBalancer_NoServer(t *testing.T) {
+	balancer, err := New(http.NotFoundHandler(), nil, nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
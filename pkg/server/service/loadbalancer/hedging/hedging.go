@@ -0,0 +1,371 @@
+package hedging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/healthcheck"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	"github.com/go-kit/kit/metrics"
+)
+
+const (
+	resultWon  = "won"
+	resultLost = "lost"
+)
+
+// idempotentMethods are the methods of the requests which can be hedged.
+var idempotentMethods = map[string]struct{}{
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+	http.MethodPut:     {},
+	http.MethodDelete:  {},
+}
+
+// Handler sends copies of a request (hedges) to other servers once the request has been outstanding for a delay,
+// and keeps the first response, canceling the other attempts.
+type Handler struct {
+	next        http.Handler
+	serviceName string
+	delay       time.Duration
+	maxHedges   int
+	latencies   *latencies
+	hedges      metrics.Counter
+}
+
+// New creates a new Handler, sending the requests and their hedges to next.
+// hedges, if not nil, counts the hedges sent, partitioned by result.
+func New(next http.Handler, serviceName string, config dynamic.Hedging, hedges metrics.Counter) (*Handler, error) {
+	if config.MaxHedges < 1 {
+		return nil, fmt.Errorf("invalid maxHedges %d: it must be at least 1", config.MaxHedges)
+	}
+
+	if config.Percentile < 0 || config.Percentile >= 100 {
+		return nil, fmt.Errorf("invalid percentile %v: it must be between 0 and 100", config.Percentile)
+	}
+
+	if config.Delay <= 0 && config.Percentile == 0 {
+		return nil, errors.New("a delay or a percentile is required")
+	}
+
+	h := &Handler{
+		next:        next,
+		serviceName: serviceName,
+		delay:       time.Duration(config.Delay),
+		maxHedges:   config.MaxHedges,
+		hedges:      hedges,
+	}
+
+	if config.Percentile > 0 {
+		h.latencies = newLatencies(config.Percentile)
+	}
+
+	return h, nil
+}
+
+// RegisterStatusUpdater registers fn on the wrapped handler, if it is able to report its status changes.
+func (h *Handler) RegisterStatusUpdater(fn func(up bool)) {
+	if updater, ok := h.next.(healthcheck.StatusUpdater); ok {
+		updater.RegisterStatusUpdater(fn)
+	}
+}
+
+func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	delay := h.hedgingDelay()
+	if delay <= 0 || !hedgeable(req) {
+		start := time.Now()
+		h.next.ServeHTTP(rw, req)
+		h.latencies.observe(time.Since(start))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+
+	logData := accesslog.GetLogData(req)
+	servers := &servers{used: make(map[string]struct{})}
+	race := newRace()
+	done := make(chan *attemptWriter, h.maxHedges+1)
+
+	launch := func(hedge bool) *attemptWriter {
+		attemptCtx, attemptCancel := context.WithCancel(ctx)
+		attemptCtx = context.WithValue(attemptCtx, attemptKey{}, &attempt{hedge: hedge, servers: servers})
+
+		aw := &attemptWriter{rw: rw, race: race, header: make(http.Header), cancel: attemptCancel, hedge: hedge, start: time.Now()}
+
+		// Each attempt gets its own log data, as they run concurrently,
+		// and the log data of the winner is kept.
+		if logData != nil {
+			aw.logData = cloneLogData(logData)
+			attemptCtx = context.WithValue(attemptCtx, accesslog.DataTableKey, aw.logData)
+		}
+
+		race.add(aw)
+
+		outReq := req.Clone(attemptCtx)
+		go func() {
+			defer func() {
+				if err := recover(); err != nil {
+					aw.panic = err
+				}
+
+				// An attempt which did not write anything responds with an empty 200, as net/http would.
+				aw.claim(false)
+				race.done()
+				done <- aw
+			}()
+
+			h.next.ServeHTTP(aw, outReq)
+		}()
+
+		return aw
+	}
+
+	attempts := []*attemptWriter{launch(false)}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case aw := <-done:
+			if !aw.won {
+				// The attempt lost the race, and the winner is still writing its response.
+				continue
+			}
+
+			h.finish(aw, attempts, logData)
+
+			if aw.panic != nil {
+				panic(aw.panic)
+			}
+			return
+
+		case <-timer.C:
+			if len(attempts) > h.maxHedges || race.decided() {
+				continue
+			}
+
+			log.FromContext(req.Context()).Debugf("Sending hedge %d for request %s after %s", len(attempts), req.URL, delay)
+			attempts = append(attempts, launch(true))
+			timer.Reset(delay)
+		}
+	}
+}
+
+// finish records the outcome of the attempts of a request, once the winner has sent its response.
+func (h *Handler) finish(winner *attemptWriter, attempts []*attemptWriter, logData *accesslog.LogData) {
+	h.latencies.observe(winner.duration)
+
+	if logData != nil {
+		*logData = *winner.logData
+		if len(attempts) > 1 {
+			logData.Core[accesslog.HedgeAttempts] = len(attempts) - 1
+		}
+	}
+
+	if h.hedges == nil {
+		return
+	}
+
+	for _, aw := range attempts {
+		if !aw.hedge {
+			continue
+		}
+
+		result := resultLost
+		if aw == winner {
+			result = resultWon
+		}
+		h.hedges.With("service", h.serviceName, "result", result).Add(1)
+	}
+}
+
+// hedgingDelay returns the delay after which a request is hedged, or zero if the requests are not hedged yet,
+// for lack of latency observations.
+func (h *Handler) hedgingDelay() time.Duration {
+	if h.latencies == nil {
+		return h.delay
+	}
+
+	percentile, ok := h.latencies.percentile()
+	if !ok {
+		return h.delay
+	}
+
+	if percentile < h.delay {
+		return h.delay
+	}
+	return percentile
+}
+
+// hedgeable reports whether the request can be sent several times, concurrently.
+func hedgeable(req *http.Request) bool {
+	if _, ok := idempotentMethods[req.Method]; !ok {
+		return false
+	}
+
+	// The body of the request can only be read once, and a protocol upgrade hijacks the connection.
+	return (req.Body == nil || req.Body == http.NoBody) && req.ContentLength == 0 && req.Header.Get("Upgrade") == ""
+}
+
+func cloneLogData(data *accesslog.LogData) *accesslog.LogData {
+	clone := *data
+	clone.Core = make(accesslog.CoreLogData, len(data.Core))
+	for k, v := range data.Core {
+		clone.Core[k] = v
+	}
+	return &clone
+}
+
+// race elects the first attempt responding as the winner, and cancels the other ones.
+// An attempt failing with a server error (or a transport error, reported as such by the forwarder)
+// only wins once no other attempt is left running to respond instead.
+type race struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	attempts []*attemptWriter
+	winner   *attemptWriter
+	// running is the number of attempts still being served, and failing the number of them waiting to respond with an error.
+	running int
+	failing int
+}
+
+func newRace() *race {
+	r := &race{}
+	r.cond = sync.NewCond(&r.mu)
+	return r
+}
+
+func (r *race) add(aw *attemptWriter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts = append(r.attempts, aw)
+	r.running++
+}
+
+// done records that an attempt has been served.
+func (r *race) done() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.running--
+	r.cond.Broadcast()
+}
+
+// claim reports whether the given attempt is the winner, electing it if there is none yet.
+// A failed attempt waits until another attempt wins, or until all the other running attempts failed too.
+func (r *race) claim(aw *attemptWriter, failed bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if failed && r.winner == nil {
+		r.failing++
+		for r.winner == nil && r.running > r.failing {
+			r.cond.Wait()
+		}
+		r.failing--
+	}
+
+	if r.winner != nil {
+		return r.winner == aw
+	}
+
+	r.winner = aw
+	for _, other := range r.attempts {
+		if other != aw {
+			other.cancel()
+		}
+	}
+	r.cond.Broadcast()
+	return true
+}
+
+func (r *race) decided() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.winner != nil
+}
+
+// attemptWriter is the response writer of an attempt,
+// which writes to the client only once the attempt has won the race, and discards the response otherwise.
+type attemptWriter struct {
+	rw      http.ResponseWriter
+	race    *race
+	header  http.Header
+	cancel  context.CancelFunc
+	hedge   bool
+	logData *accesslog.LogData
+	start   time.Time
+
+	// The following fields are only accessed by the goroutine of the attempt, until it sends itself on the done channel.
+	won      bool
+	lost     bool
+	duration time.Duration
+	panic    interface{}
+}
+
+// claim reports whether the attempt won the race, and is the one responding to the client.
+// failed is whether the attempt responds with a server error.
+func (w *attemptWriter) claim(failed bool) bool {
+	if w.won {
+		return true
+	}
+	if w.lost {
+		return false
+	}
+
+	if !w.race.claim(w, failed) {
+		w.lost = true
+		return false
+	}
+
+	w.won = true
+	w.duration = time.Since(w.start)
+
+	headers := w.rw.Header()
+	for k, v := range w.header {
+		headers[k] = v
+	}
+	return true
+}
+
+func (w *attemptWriter) Header() http.Header {
+	if w.won {
+		return w.rw.Header()
+	}
+	return w.header
+}
+
+func (w *attemptWriter) WriteHeader(code int) {
+	if w.claim(code >= http.StatusInternalServerError) {
+		w.rw.WriteHeader(code)
+	}
+}
+
+func (w *attemptWriter) Write(buf []byte) (int, error) {
+	if w.claim(false) {
+		return w.rw.Write(buf)
+	}
+	return len(buf), nil
+}
+
+func (w *attemptWriter) Flush() {
+	if !w.claim(false) {
+		return
+	}
+
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
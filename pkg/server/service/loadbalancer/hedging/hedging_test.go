@@ -0,0 +1,271 @@
+package hedging
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
+	ptypes "github.com/containous/traefik/v2/pkg/types"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCounter struct {
+	labels []string
+	counts map[string]float64
+}
+
+func (c *testCounter) With(labelValues ...string) metrics.Counter {
+	return &testCounter{labels: labelValues, counts: c.counts}
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.counts[strings.Join(c.labels, ",")] += delta
+}
+
+// slowThenFast is a handler whose first request never answers until canceled, and whose next ones answer right away.
+type slowThenFast struct {
+	calls    int32
+	canceled chan struct{}
+}
+
+func (h *slowThenFast) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if atomic.AddInt32(&h.calls, 1) == 1 {
+		<-req.Context().Done()
+		close(h.canceled)
+		rw.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	rw.Header().Set("X-Attempt", "hedge")
+	_, _ = rw.Write([]byte("fast"))
+}
+
+func TestHedging(t *testing.T) {
+	next := &slowThenFast{canceled: make(chan struct{})}
+	hedges := &testCounter{counts: make(map[string]float64)}
+
+	handler, err := New(next, "foo", dynamic.Hedging{Delay: ptypes.Duration(10 * time.Millisecond), MaxHedges: 1}, hedges)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "fast", recorder.Body.String())
+	assert.Equal(t, "hedge", recorder.Header().Get("X-Attempt"))
+	assert.Equal(t, map[string]float64{"service,foo,result,won": 1}, hedges.counts)
+
+	select {
+	case <-next.canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the slow attempt was not canceled")
+	}
+}
+
+func TestHedging_notHedged(t *testing.T) {
+	testCases := []struct {
+		desc string
+		req  *http.Request
+	}{
+		{
+			desc: "non idempotent method",
+			req:  httptest.NewRequest(http.MethodPost, "http://localhost/", nil),
+		},
+		{
+			desc: "request with a body",
+			req:  httptest.NewRequest(http.MethodPut, "http://localhost/", strings.NewReader("body")),
+		},
+		{
+			desc: "protocol upgrade",
+			req: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+				req.Header.Set("Connection", "Upgrade")
+				req.Header.Set("Upgrade", "websocket")
+				return req
+			}(),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(30 * time.Millisecond)
+			})
+
+			handler, err := New(next, "foo", dynamic.Hedging{Delay: ptypes.Duration(time.Millisecond), MaxHedges: 1}, nil)
+			require.NoError(t, err)
+
+			handler.ServeHTTP(httptest.NewRecorder(), test.req)
+
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestHedging_maxHedges(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 3 {
+			// The last hedge waits for the others to be sent.
+			time.Sleep(50 * time.Millisecond)
+			close(release)
+			return
+		}
+
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	})
+
+	hedges := &testCounter{counts: make(map[string]float64)}
+	handler, err := New(next, "foo", dynamic.Hedging{Delay: ptypes.Duration(5 * time.Millisecond), MaxHedges: 2}, hedges)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Equal(t, map[string]float64{"service,foo,result,won": 1, "service,foo,result,lost": 1}, hedges.counts)
+}
+
+func TestHedging_serverError(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		slowStatus int
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			desc:       "a server error does not win while another attempt is running",
+			slowStatus: http.StatusOK,
+			wantStatus: http.StatusOK,
+			wantBody:   "slow",
+		},
+		{
+			desc:       "a server error wins once all attempts failed",
+			slowStatus: http.StatusBadGateway,
+			wantStatus: http.StatusBadGateway,
+			wantBody:   "slow",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls int32
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if atomic.AddInt32(&calls, 1) == 1 {
+					// The first attempt answers after its hedge failed.
+					time.Sleep(50 * time.Millisecond)
+					rw.WriteHeader(test.slowStatus)
+					_, _ = rw.Write([]byte("slow"))
+					return
+				}
+
+				rw.WriteHeader(http.StatusInternalServerError)
+				_, _ = rw.Write([]byte("hedge"))
+			})
+
+			handler, err := New(next, "foo", dynamic.Hedging{Delay: ptypes.Duration(5 * time.Millisecond), MaxHedges: 1}, nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+			assert.Equal(t, test.wantStatus, recorder.Code)
+			assert.Equal(t, test.wantBody, recorder.Body.String())
+			assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestHedging_accessLog(t *testing.T) {
+	var mu sync.Mutex
+	var servers []string
+
+	next := &slowThenFast{canceled: make(chan struct{})}
+	fields := accesslog.NewFieldHandler(next, accesslog.ServiceURL, "", func(rw http.ResponseWriter, req *http.Request, next http.Handler, data *accesslog.LogData) {
+		mu.Lock()
+		servers = append(servers, req.URL.String())
+		mu.Unlock()
+
+		data.Core[accesslog.ServiceURL] = req.URL.String()
+		next.ServeHTTP(rw, req)
+	})
+
+	handler, err := New(fields, "foo", dynamic.Hedging{Delay: ptypes.Duration(10 * time.Millisecond), MaxHedges: 1}, nil)
+	require.NoError(t, err)
+
+	logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 1, logData.Core[accesslog.HedgeAttempts])
+	assert.Len(t, servers, 2)
+}
+
+func TestHedging_percentile(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	handler, err := New(next, "foo", dynamic.Hedging{Delay: ptypes.Duration(time.Millisecond), Percentile: 90, MaxHedges: 1}, nil)
+	require.NoError(t, err)
+
+	// The delay is used until enough latencies are observed.
+	assert.Equal(t, time.Millisecond, handler.hedgingDelay())
+
+	for i := 1; i <= minLatencies; i++ {
+		handler.latencies.observe(time.Duration(i) * time.Second)
+	}
+	assert.Equal(t, 90*time.Second, handler.hedgingDelay())
+}
+
+func TestNew_invalid(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.Hedging
+	}{
+		{
+			desc:   "no delay nor percentile",
+			config: dynamic.Hedging{MaxHedges: 1},
+		},
+		{
+			desc:   "no hedges",
+			config: dynamic.Hedging{Delay: ptypes.Duration(time.Second)},
+		},
+		{
+			desc:   "invalid percentile",
+			config: dynamic.Hedging{Percentile: 100, MaxHedges: 1},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(http.NotFoundHandler(), "foo", test.config, nil)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package hedging
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// latencyWindow is the number of the most recent latencies from which the percentile is computed.
+	latencyWindow = 1000
+	// minLatencies is the number of latencies required to compute the percentile,
+	// which is also how often it is computed again.
+	minLatencies = 100
+)
+
+// latencies keeps the most recent latencies of a service, and computes their percentile.
+type latencies struct {
+	rank float64
+
+	mu      sync.Mutex
+	values  []time.Duration
+	next    int
+	pending int
+	value   time.Duration
+	ready   bool
+}
+
+func newLatencies(percentile float64) *latencies {
+	return &latencies{
+		rank:   percentile / 100,
+		values: make([]time.Duration, 0, latencyWindow),
+	}
+}
+
+func (l *latencies) observe(d time.Duration) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.values) < latencyWindow {
+		l.values = append(l.values, d)
+	} else {
+		l.values[l.next] = d
+		l.next = (l.next + 1) % latencyWindow
+	}
+
+	l.pending++
+	if l.pending < minLatencies {
+		return
+	}
+	l.pending = 0
+
+	sorted := make([]time.Duration, len(l.values))
+	copy(sorted, l.values)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	l.value = sorted[int(l.rank*float64(len(sorted)-1))]
+	l.ready = true
+}
+
+// percentile returns the percentile of the latencies, if enough of them were observed.
+func (l *latencies) percentile() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.value, l.ready
+}
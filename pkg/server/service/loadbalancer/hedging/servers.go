@@ -0,0 +1,49 @@
+package hedging
+
+import (
+	"net/http"
+	"sync"
+)
+
+type attemptKey struct{}
+
+// attempt is an attempt of a hedged request.
+type attempt struct {
+	hedge   bool
+	servers *servers
+}
+
+// servers records the servers to which the attempts of a request are sent,
+// so that the load-balancers can send the hedges to other servers.
+type servers struct {
+	mu   sync.Mutex
+	used map[string]struct{}
+}
+
+// AvoidServer reports whether the given server, chosen by a load-balancer for the request,
+// should be avoided because the request is a hedge, and another attempt of the request was sent to this server.
+func AvoidServer(req *http.Request, server string) bool {
+	a, ok := req.Context().Value(attemptKey{}).(*attempt)
+	if !ok || !a.hedge {
+		return false
+	}
+
+	a.servers.mu.Lock()
+	defer a.servers.mu.Unlock()
+
+	_, used := a.servers.used[server]
+	return used
+}
+
+// UseServer records that an attempt of the request is sent to the given server.
+func UseServer(req *http.Request, server string) {
+	a, ok := req.Context().Value(attemptKey{}).(*attempt)
+	if !ok {
+		return
+	}
+
+	a.servers.mu.Lock()
+	defer a.servers.mu.Unlock()
+
+	a.servers.used[server] = struct{}{}
+}
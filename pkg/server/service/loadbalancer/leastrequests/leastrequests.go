@@ -0,0 +1,227 @@
+package leastrequests
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer"
+	"github.com/vulcand/oxy/roundrobin"
+	"github.com/vulcand/oxy/utils"
+)
+
+type server struct {
+	// outstanding is the number of requests currently in flight to this server.
+	// It is accessed atomically, and is kept first in the struct for alignment purposes.
+	outstanding int64
+	url         *url.URL
+	weight      int
+}
+
+// lessLoadedThan reports whether s is strictly less loaded than other,
+// weighing their outstanding requests (counting the one about to be sent) by their weight.
+func (s *server) lessLoadedThan(other *server) bool {
+	sLoad := (atomic.LoadInt64(&s.outstanding) + 1) * int64(other.weight)
+	otherLoad := (atomic.LoadInt64(&other.outstanding) + 1) * int64(s.weight)
+	return sLoad < otherLoad
+}
+
+// Balancer is a load-balancer of servers that sends each request to the server with the fewest outstanding requests,
+// relative to its weight.
+// When choices is positive, only that many servers, picked at random,
+// are compared for each request (e.g. 2 for the power of two random choices algorithm),
+// which keeps the pick time constant and avoids herding on the least loaded server
+// when several load-balancers share the same servers.
+type Balancer struct {
+	next          http.Handler
+	stickySession *roundrobin.StickySession
+	choices       int
+
+	mutex   sync.Mutex
+	servers []*server
+	rand    *rand.Rand
+	// offset rotates the starting point of the full scan, so that ties are not always won by the first server.
+	offset int
+}
+
+// New creates a new least outstanding requests Balancer.
+// choices is the number of random servers compared for each request,
+// and a non-positive value means that all servers are compared.
+func New(next http.Handler, stickySession *roundrobin.StickySession, choices int) *Balancer {
+	return &Balancer{
+		next:          next,
+		stickySession: stickySession,
+		choices:       choices,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// make a shallow copy of the request before changing anything to avoid side effects.
+	newReq := *req
+
+	var srv *server
+	if b.stickySession != nil {
+		cookieURL, present, err := b.stickySession.GetBackend(&newReq, b.Servers())
+		if err != nil {
+			log.WithoutContext().Warnf("Error while reading sticky cookie: %v", err)
+		}
+
+		if present {
+			srv = b.findServer(cookieURL)
+		}
+	}
+
+	if srv == nil {
+		var err error
+		srv, err = b.nextServer()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		if b.stickySession != nil {
+			b.stickySession.StickBackend(srv.url, &w)
+		}
+	}
+
+	newReq.URL = utils.CopyURL(srv.url)
+
+	atomic.AddInt64(&srv.outstanding, 1)
+	defer atomic.AddInt64(&srv.outstanding, -1)
+
+	b.next.ServeHTTP(w, &newReq)
+}
+
+func (b *Balancer) nextServer() (*server, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.servers) == 0 {
+		return nil, fmt.Errorf("no servers in the pool")
+	}
+
+	if len(b.servers) == 1 {
+		return b.servers[0], nil
+	}
+
+	if b.choices > 0 && b.choices < len(b.servers) {
+		return b.pickRandom(), nil
+	}
+
+	b.offset = (b.offset + 1) % len(b.servers)
+
+	best := b.servers[b.offset]
+	for i := 1; i < len(b.servers); i++ {
+		candidate := b.servers[(b.offset+i)%len(b.servers)]
+		if candidate.lessLoadedThan(best) {
+			best = candidate
+		}
+	}
+
+	return best, nil
+}
+
+// pickRandom compares b.choices distinct servers picked at random, and returns the least loaded one.
+// It must be called with the lock held.
+func (b *Balancer) pickRandom() *server {
+	picked := make(map[int]struct{}, b.choices)
+
+	var best *server
+	for len(picked) < b.choices {
+		index := b.rand.Intn(len(b.servers))
+		if _, ok := picked[index]; ok {
+			continue
+		}
+		picked[index] = struct{}{}
+
+		if candidate := b.servers[index]; best == nil || candidate.lessLoadedThan(best) {
+			best = candidate
+		}
+	}
+
+	return best
+}
+
+func (b *Balancer) findServer(u *url.URL) *server {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	srv, _ := b.findServerByURL(u)
+	return srv
+}
+
+// findServerByURL must be called with the lock held.
+func (b *Balancer) findServerByURL(u *url.URL) (*server, int) {
+	index := loadbalancer.FindURL(len(b.servers), func(i int) *url.URL { return b.servers[i].url }, u)
+	if index == -1 {
+		return nil, -1
+	}
+	return b.servers[index], index
+}
+
+// Servers returns the list of servers the balancer is currently sending requests to.
+func (b *Balancer) Servers() []*url.URL {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	out := make([]*url.URL, len(b.servers))
+	for i, srv := range b.servers {
+		out[i] = srv.url
+	}
+	return out
+}
+
+// ServerWeight returns the weight of the server identified by the given URL.
+func (b *Balancer) ServerWeight(u *url.URL) (int, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if srv, _ := b.findServerByURL(u); srv != nil {
+		return srv.weight, true
+	}
+	return -1, false
+}
+
+// RemoveServer removes the given server from the balancer.
+func (b *Balancer) RemoveServer(u *url.URL) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	_, index := b.findServerByURL(u)
+	if index == -1 {
+		return fmt.Errorf("server not found")
+	}
+
+	b.servers = append(b.servers[:index], b.servers[index+1:]...)
+	return nil
+}
+
+// UpsertServer adds the given server to the balancer,
+// or updates its weight if it is already known.
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if u == nil {
+		return fmt.Errorf("server URL can't be nil")
+	}
+
+	weight, err := loadbalancer.ServerWeight(u, options...)
+	if err != nil {
+		return err
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if srv, _ := b.findServerByURL(u); srv != nil {
+		srv.weight = weight
+		return nil
+	}
+
+	b.servers = append(b.servers, &server{url: utils.CopyURL(u), weight: weight})
+	return nil
+}
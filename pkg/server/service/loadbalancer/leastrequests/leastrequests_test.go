@@ -0,0 +1,194 @@
+package leastrequests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u
+}
+
+func TestBalancer_LeastRequests(t *testing.T) {
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+
+	counts := map[string]int{}
+	var countsMu sync.Mutex
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		countsMu.Lock()
+		counts[req.URL.Host]++
+		countsMu.Unlock()
+
+		if req.Header.Get("slow") != "" {
+			<-release
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	balancer := New(next, nil, 0)
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://first")))
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://second")))
+
+	// Keep one request in flight on one of the servers.
+	slowReq := httptest.NewRequest(http.MethodGet, "/", nil)
+	slowReq.Header.Set("slow", "true")
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		balancer.ServeHTTP(httptest.NewRecorder(), slowReq)
+	}()
+
+	assert.Eventually(t, func() bool {
+		countsMu.Lock()
+		defer countsMu.Unlock()
+		return counts["first"]+counts["second"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	countsMu.Lock()
+	busy := "first"
+	if counts["second"] == 1 {
+		busy = "second"
+	}
+	countsMu.Unlock()
+
+	for i := 0; i < 10; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	close(release)
+	wg.Wait()
+
+	countsMu.Lock()
+	defer countsMu.Unlock()
+	assert.Equal(t, 1, counts[busy])
+	assert.Equal(t, 11, counts["first"]+counts["second"])
+}
+
+func TestBalancer_Weights(t *testing.T) {
+	counts := map[string]int{}
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		counts[req.URL.Host]++
+	})
+
+	balancer := New(next, nil, 0)
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://first"), roundrobin.Weight(3)))
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://second"), roundrobin.Weight(1)))
+
+	weight, ok := balancer.ServerWeight(mustParse(t, "http://first"))
+	assert.True(t, ok)
+	assert.Equal(t, 3, weight)
+
+	// Sequential requests never overlap, so the heavier server is always the least loaded one.
+	for i := 0; i < 4; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 4, counts["first"])
+	assert.Equal(t, 0, counts["second"])
+}
+
+func TestBalancer_Ties(t *testing.T) {
+	counts := map[string]int{}
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		counts[req.URL.Host]++
+	})
+
+	balancer := New(next, nil, 0)
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://first")))
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://second")))
+
+	// Without outstanding requests, every server is equally loaded, so ties are rotated.
+	for i := 0; i < 4; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 2, counts["first"])
+	assert.Equal(t, 2, counts["second"])
+}
+
+func TestBalancer_PowerOfTwoChoices(t *testing.T) {
+	counts := map[string]int{}
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		counts[req.URL.Host]++
+	})
+
+	balancer := New(next, nil, 2)
+	for _, host := range []string{"first", "second", "third"} {
+		require.NoError(t, balancer.UpsertServer(mustParse(t, "http://"+host)))
+	}
+
+	for i := 0; i < 300; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Len(t, counts, 3)
+}
+
+func TestBalancer_RemoveServer(t *testing.T) {
+	var got []string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = append(got, req.URL.Host)
+	})
+
+	balancer := New(next, nil, 2)
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://first")))
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://second")))
+	require.NoError(t, balancer.RemoveServer(mustParse(t, "http://first")))
+	assert.Error(t, balancer.RemoveServer(mustParse(t, "http://first")))
+
+	assert.Len(t, balancer.Servers(), 1)
+
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, []string{"second", "second", "second"}, got)
+}
+
+func TestBalancer_NoServer(t *testing.T) {
+	balancer := New(http.NotFoundHandler(), nil, 0)
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestBalancer_StickySession(t *testing.T) {
+	var got []string
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = append(got, req.URL.Host)
+	})
+
+	balancer := New(next, roundrobin.NewStickySession("test"), 0)
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://first")))
+	require.NoError(t, balancer.UpsertServer(mustParse(t, "http://second")))
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookies[0])
+		balancer.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, []string{got[0], got[0], got[0], got[0]}, got)
+}
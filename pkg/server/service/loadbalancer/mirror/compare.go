@@ -0,0 +1,408 @@
+package mirror
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/go-kit/kit/metrics"
+)
+
+// Body comparison modes.
+const (
+	// BodyHash compares the SHA-256 hashes of the bodies.
+	BodyHash = "hash"
+	// BodyJSON compares the bodies as JSON documents, and reports the paths of the differences.
+	BodyJSON = "json"
+)
+
+// Mismatch reasons, used as the reason label of the mismatches metric.
+const (
+	reasonStatus = "status"
+	reasonHeader = "header"
+	reasonBody   = "body"
+)
+
+// maxJSONDiffs is the maximum number of differences reported for a JSON body.
+const maxJSONDiffs = 10
+
+// requestIDHeader is the header holding the ID of the request, reported with the mismatches.
+const requestIDHeader = "X-Request-Id"
+
+// CompareOptions configures the comparison of the responses of the mirrors with the response of the main handler.
+type CompareOptions struct {
+	// Headers are the names of the response headers to compare.
+	Headers []string
+	// Body is the body comparison mode, either BodyHash, BodyJSON, or empty to not compare the bodies.
+	Body string
+	// IgnorePaths are the dot-separated paths of the JSON body fields to ignore,
+	// where * matches any object key or array index.
+	IgnorePaths []string
+	// MaxBodySize is the maximum size of the bodies buffered to be compared as JSON documents,
+	// where a negative value means no limit.
+	// The bodies are hashed as they are written, so they are not buffered with BodyHash.
+	MaxBodySize int64
+	// Mismatches counts the mismatches, if not nil.
+	Mismatches metrics.Counter
+}
+
+type comparator struct {
+	serviceName string
+	headers     []string
+	body        string
+	ignorePaths [][]string
+	maxBodySize int64
+	mismatches  metrics.Counter
+}
+
+func newComparator(serviceName string, opts CompareOptions) (*comparator, error) {
+	switch opts.Body {
+	case "", BodyHash, BodyJSON:
+	default:
+		return nil, fmt.Errorf("unknown body comparison mode %q", opts.Body)
+	}
+
+	var ignorePaths [][]string
+	for _, path := range opts.IgnorePaths {
+		if path == "" {
+			continue
+		}
+		ignorePaths = append(ignorePaths, strings.Split(path, "."))
+	}
+
+	headers := make([]string, 0, len(opts.Headers))
+	for _, name := range opts.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(name))
+	}
+
+	return &comparator{
+		serviceName: serviceName,
+		headers:     headers,
+		body:        opts.Body,
+		ignorePaths: ignorePaths,
+		maxBodySize: opts.MaxBodySize,
+		mismatches:  opts.Mismatches,
+	}, nil
+}
+
+// newCaptureResponseWriter returns a captureResponseWriter capturing what is needed from the body for the comparison.
+func (c *comparator) newCaptureResponseWriter(rw http.ResponseWriter) *captureResponseWriter {
+	return newCaptureResponseWriter(rw, c.body, c.maxBodySize)
+}
+
+type mismatch struct {
+	reason string
+	detail string
+}
+
+// compare returns the differences between the response of a mirror and the one of the main handler.
+func (c *comparator) compare(main, mirrored *capturedResponse) []mismatch {
+	var mismatches []mismatch
+
+	if main.status != mirrored.status {
+		mismatches = append(mismatches, mismatch{
+			reason: reasonStatus,
+			detail: fmt.Sprintf("status %d, mirror status %d", main.status, mirrored.status),
+		})
+	}
+
+	for _, name := range c.headers {
+		value := strings.Join(main.header[name], ",")
+		mirroredValue := strings.Join(mirrored.header[name], ",")
+		if value != mirroredValue {
+			mismatches = append(mismatches, mismatch{
+				reason: reasonHeader,
+				detail: fmt.Sprintf("header %s %q, mirror header %s %q", name, value, name, mirroredValue),
+			})
+		}
+	}
+
+	if c.body == "" || main.truncated || mirrored.truncated {
+		return mismatches
+	}
+
+	if detail := c.compareBodies(main, mirrored); detail != "" {
+		mismatches = append(mismatches, mismatch{reason: reasonBody, detail: detail})
+	}
+
+	return mismatches
+}
+
+// compareBodies returns a description of the differences between the bodies, or an empty string if they are the same.
+func (c *comparator) compareBodies(main, mirrored *capturedResponse) string {
+	if c.body == BodyHash {
+		if bytes.Equal(main.hash, mirrored.hash) {
+			return ""
+		}
+		return fmt.Sprintf("body hash %s, mirror body hash %s", hex.EncodeToString(main.hash), hex.EncodeToString(mirrored.hash))
+	}
+
+	if bytes.Equal(main.body, mirrored.body) {
+		return ""
+	}
+
+	var doc, mirroredDoc interface{}
+	if json.Unmarshal(main.body, &doc) != nil || json.Unmarshal(mirrored.body, &mirroredDoc) != nil {
+		return "bodies differ and are not both valid JSON"
+	}
+
+	var diffs []string
+	c.diffJSON(nil, doc, mirroredDoc, &diffs)
+	if len(diffs) == 0 {
+		return ""
+	}
+
+	return "body differs at " + strings.Join(diffs, ", ")
+}
+
+// diffJSON appends to diffs the paths where the given JSON values differ, up to maxJSONDiffs.
+func (c *comparator) diffJSON(path []string, value, mirrored interface{}, diffs *[]string) {
+	if len(*diffs) >= maxJSONDiffs || c.ignored(path) {
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m, ok := mirrored.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make(map[string]struct{}, len(v)+len(m))
+		for key := range v {
+			keys[key] = struct{}{}
+		}
+		for key := range m {
+			keys[key] = struct{}{}
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			c.diffJSON(appendPath(path, key), v[key], m[key], diffs)
+		}
+		return
+
+	case []interface{}:
+		m, ok := mirrored.([]interface{})
+		if !ok {
+			break
+		}
+
+		length := len(v)
+		if len(m) > length {
+			length = len(m)
+		}
+
+		for i := 0; i < length; i++ {
+			var item, mirroredItem interface{}
+			if i < len(v) {
+				item = v[i]
+			}
+			if i < len(m) {
+				mirroredItem = m[i]
+			}
+			c.diffJSON(appendPath(path, strconv.Itoa(i)), item, mirroredItem, diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(value, mirrored) {
+		p := strings.Join(path, ".")
+		if p == "" {
+			p = "."
+		}
+		*diffs = append(*diffs, p)
+	}
+}
+
+// ignored returns whether the given path matches one of the ignored paths.
+func (c *comparator) ignored(path []string) bool {
+	for _, ignorePath := range c.ignorePaths {
+		if len(ignorePath) != len(path) {
+			continue
+		}
+
+		match := true
+		for i, segment := range ignorePath {
+			if segment != "*" && segment != path[i] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+func appendPath(path []string, segment string) []string {
+	p := make([]string, len(path), len(path)+1)
+	copy(p, path)
+	return append(p, segment)
+}
+
+// report logs the mismatches, and counts them in the mismatches metric.
+func (c *comparator) report(ctx context.Context, requestID, mirrorName string, mismatches []mismatch) {
+	logger := log.FromContext(ctx).
+		WithField("service", c.serviceName).
+		WithField("mirror", mirrorName).
+		WithField("requestID", requestID)
+
+	for _, m := range mismatches {
+		logger.WithField("reason", m.reason).Warnf("Mirrored response differs from the main response: %s", m.detail)
+
+		if c.mismatches != nil {
+			c.mismatches.With("service", c.serviceName, "mirror", mirrorName, "reason", m.reason).Add(1)
+		}
+	}
+}
+
+// capturedResponse is the part of a response needed for the comparison.
+type capturedResponse struct {
+	status int
+	header http.Header
+	// body is the body, captured with BodyJSON.
+	body []byte
+	// hash is the SHA-256 hash of the body, captured with BodyHash.
+	hash      []byte
+	truncated bool
+}
+
+// captureResponseWriter captures the response written to it.
+// Depending on the body comparison mode, the body is either not captured, hashed as it is written,
+// or buffered up to maxBodySize.
+// If rw is not nil, the response is also written to it, as is, so that the captured response is not delayed.
+type captureResponseWriter struct {
+	rw          http.ResponseWriter
+	header      http.Header
+	body        string
+	maxBodySize int64
+	hash        hash.Hash
+
+	wroteHeader bool
+	hijacked    bool
+	response    capturedResponse
+}
+
+func newCaptureResponseWriter(rw http.ResponseWriter, body string, maxBodySize int64) *captureResponseWriter {
+	w := &captureResponseWriter{rw: rw, body: body, maxBodySize: maxBodySize}
+	if body == BodyHash {
+		w.hash = sha256.New()
+	}
+	if rw != nil {
+		w.header = rw.Header()
+	} else {
+		w.header = make(http.Header)
+	}
+	return w
+}
+
+func (w *captureResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *captureResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+	w.response.status = statusCode
+	w.response.header = w.header.Clone()
+
+	if w.rw != nil {
+		w.rw.WriteHeader(statusCode)
+	}
+}
+
+func (w *captureResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	w.capture(b)
+
+	if w.rw != nil {
+		return w.rw.Write(b)
+	}
+	return len(b), nil
+}
+
+func (w *captureResponseWriter) capture(b []byte) {
+	switch {
+	case w.body == BodyHash:
+		// Writing to a hash never returns an error.
+		_, _ = w.hash.Write(b)
+		return
+	case w.body != BodyJSON || w.response.truncated:
+		return
+	}
+
+	if w.maxBodySize >= 0 && int64(len(w.response.body)+len(b)) > w.maxBodySize {
+		w.response.truncated = true
+		w.response.body = nil
+		return
+	}
+
+	w.response.body = append(w.response.body, b...)
+}
+
+// Flush sends any buffered data to the client.
+func (w *captureResponseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack hijacks the connection, in which case the response is not compared.
+func (w *captureResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.rw)
+	}
+
+	w.hijacked = true
+	return hijacker.Hijack()
+}
+
+// captured returns the captured response, or nil if the connection has been hijacked.
+func (w *captureResponseWriter) captured() *capturedResponse {
+	if w.hijacked {
+		return nil
+	}
+
+	if !w.wroteHeader {
+		w.response.status = http.StatusOK
+		w.response.header = w.header.Clone()
+	}
+
+	if w.hash != nil {
+		w.response.hash = w.hash.Sum(nil)
+	}
+
+	return &w.response
+}
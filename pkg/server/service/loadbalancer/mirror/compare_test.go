@@ -0,0 +1,223 @@
+package mirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCounter struct {
+	labels []string
+	counts map[string]float64
+}
+
+func (c *testCounter) With(labelValues ...string) metrics.Counter {
+	return &testCounter{labels: labelValues, counts: c.counts}
+}
+
+func (c *testCounter) Add(delta float64) {
+	c.counts[strings.Join(c.labels, ",")] += delta
+}
+
+func sum(body string) []byte {
+	hash := sha256.Sum256([]byte(body))
+	return hash[:]
+}
+
+func TestComparator_compare(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		opts       CompareOptions
+		main       capturedResponse
+		mirrored   capturedResponse
+		mismatches []mismatch
+	}{
+		{
+			desc:     "same responses",
+			opts:     CompareOptions{Headers: []string{"content-type"}, Body: BodyHash},
+			main:     capturedResponse{status: 200, header: http.Header{"Content-Type": {"text/plain"}}, hash: sum("foo")},
+			mirrored: capturedResponse{status: 200, header: http.Header{"Content-Type": {"text/plain"}}, hash: sum("foo")},
+		},
+		{
+			desc:     "different status",
+			main:     capturedResponse{status: 200},
+			mirrored: capturedResponse{status: 500},
+			mismatches: []mismatch{
+				{reason: reasonStatus, detail: "status 200, mirror status 500"},
+			},
+		},
+		{
+			desc:     "different compared header",
+			opts:     CompareOptions{Headers: []string{"content-type"}},
+			main:     capturedResponse{status: 200, header: http.Header{"Content-Type": {"text/plain"}, "Date": {"foo"}}},
+			mirrored: capturedResponse{status: 200, header: http.Header{"Content-Type": {"application/json"}, "Date": {"bar"}}},
+			mismatches: []mismatch{
+				{reason: reasonHeader, detail: `header Content-Type "text/plain", mirror header Content-Type "application/json"`},
+			},
+		},
+		{
+			desc:     "different bodies not compared",
+			main:     capturedResponse{status: 200, body: []byte("foo")},
+			mirrored: capturedResponse{status: 200, body: []byte("bar")},
+		},
+		{
+			desc:     "different body hashes",
+			opts:     CompareOptions{Body: BodyHash},
+			main:     capturedResponse{status: 200, hash: sum("foo")},
+			mirrored: capturedResponse{status: 200, hash: sum("bar")},
+			mismatches: []mismatch{
+				{
+					reason: reasonBody,
+					detail: "body hash 2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae, mirror body hash fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9",
+				},
+			},
+		},
+		{
+			desc:     "truncated body",
+			opts:     CompareOptions{Body: BodyJSON},
+			main:     capturedResponse{status: 200, body: []byte(`{"a": 1}`)},
+			mirrored: capturedResponse{status: 200, truncated: true},
+		},
+		{
+			desc:     "same JSON documents",
+			opts:     CompareOptions{Body: BodyJSON},
+			main:     capturedResponse{status: 200, body: []byte(`{"a": 1, "b": [1, 2]}`)},
+			mirrored: capturedResponse{status: 200, body: []byte(`{"b":[1,2],"a":1}`)},
+		},
+		{
+			desc:     "different JSON documents",
+			opts:     CompareOptions{Body: BodyJSON, IgnorePaths: []string{"time", "items.*.id"}},
+			main:     capturedResponse{status: 200, body: []byte(`{"time": 1, "a": "foo", "items": [{"id": 1, "v": 1}, {"id": 2, "v": 2}]}`)},
+			mirrored: capturedResponse{status: 200, body: []byte(`{"time": 2, "a": "bar", "b": true, "items": [{"id": 3, "v": 1}, {"id": 4, "v": 3}, {}]}`)},
+			mismatches: []mismatch{
+				{reason: reasonBody, detail: "body differs at a, b, items.1.v, items.2"},
+			},
+		},
+		{
+			desc:     "only ignored JSON differences",
+			opts:     CompareOptions{Body: BodyJSON, IgnorePaths: []string{"time"}},
+			main:     capturedResponse{status: 200, body: []byte(`{"time": 1, "a": "foo"}`)},
+			mirrored: capturedResponse{status: 200, body: []byte(`{"time": 2, "a": "foo"}`)},
+		},
+		{
+			desc:     "invalid JSON document",
+			opts:     CompareOptions{Body: BodyJSON},
+			main:     capturedResponse{status: 200, body: []byte(`{"a": 1}`)},
+			mirrored: capturedResponse{status: 200, body: []byte(`internal error`)},
+			mismatches: []mismatch{
+				{reason: reasonBody, detail: "bodies differ and are not both valid JSON"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cmp, err := newComparator("foo", test.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.mismatches, cmp.compare(&test.main, &test.mirrored))
+		})
+	}
+}
+
+func TestComparator_unknownBodyMode(t *testing.T) {
+	_, err := newComparator("foo", CompareOptions{Body: "xml"})
+	assert.EqualError(t, err, `unknown body comparison mode "xml"`)
+}
+
+func TestCaptureResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	capture := newCaptureResponseWriter(recorder, BodyJSON, 5)
+
+	capture.Header().Set("Content-Type", "text/plain")
+	_, err := capture.Write([]byte("foo"))
+	require.NoError(t, err)
+
+	response := capture.captured()
+	assert.Equal(t, http.StatusOK, response.status)
+	assert.Equal(t, "text/plain", response.header.Get("Content-Type"))
+	assert.Equal(t, "foo", string(response.body))
+	assert.False(t, response.truncated)
+
+	// The body larger than the maximum size is not captured, but still written.
+	_, err = capture.Write([]byte("barbaz"))
+	require.NoError(t, err)
+
+	response = capture.captured()
+	assert.Nil(t, response.body)
+	assert.True(t, response.truncated)
+	assert.Equal(t, "foobarbaz", recorder.Body.String())
+}
+
+func TestCaptureResponseWriter_hash(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	capture := newCaptureResponseWriter(recorder, BodyHash, 5)
+
+	// The body is hashed as it is written, whatever its size, and not buffered.
+	for _, chunk := range []string{"foo", "barbaz"} {
+		_, err := capture.Write([]byte(chunk))
+		require.NoError(t, err)
+	}
+
+	response := capture.captured()
+	assert.Equal(t, sum("foobarbaz"), response.hash)
+	assert.Nil(t, response.body)
+	assert.False(t, response.truncated)
+	assert.Equal(t, "foobarbaz", recorder.Body.String())
+}
+
+func TestMirroringCompare(t *testing.T) {
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"value": "main"}`))
+	})
+
+	pool := safe.NewPool(context.Background())
+	mirror := New(handler, pool, defaultMaxBodySize)
+
+	counter := &testCounter{counts: make(map[string]float64)}
+	err := mirror.EnableComparison("foo", CompareOptions{
+		Headers:     []string{"Content-Type"},
+		Body:        BodyJSON,
+		MaxBodySize: defaultMaxBodySize,
+		Mismatches:  counter,
+	})
+	require.NoError(t, err)
+
+	err = mirror.AddNamedMirror("same", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`{"value": "main"}`))
+	}), 100)
+	require.NoError(t, err)
+
+	err = mirror.AddNamedMirror("different", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+		_, _ = rw.Write([]byte(`{"value": "mirror"}`))
+	}), 100)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	mirror.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	pool.Stop()
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, `{"value": "main"}`, recorder.Body.String())
+
+	assert.Equal(t, map[string]float64{
+		"service,foo,mirror,different,reason,status": 1,
+		"service,foo,mirror,different,reason,header": 1,
+		"service,foo,mirror,different,reason,body":   1,
+	}, counter.counts)
+}
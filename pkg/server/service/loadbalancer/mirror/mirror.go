@@ -15,6 +15,7 @@ import (
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/middlewares/accesslog"
 	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer"
 )
 
 // Mirroring is an http.Handler that can mirror requests.
@@ -25,6 +26,7 @@ type Mirroring struct {
 	routinePool    *safe.Pool
 
 	maxBodySize int64
+	comparator  *comparator
 
 	lock  sync.RWMutex
 	total uint64
@@ -47,20 +49,51 @@ func (m *Mirroring) inc() uint64 {
 	return m.total
 }
 
+// statusUpdater is implemented by the handlers able to report when they go down, or back up.
+type statusUpdater interface {
+	RegisterStatusUpdater(fn func(up bool))
+}
+
 type mirrorHandler struct {
 	http.Handler
+	name    string
 	percent int
 
 	lock  sync.RWMutex
 	count uint64
+	down  bool
+}
+
+// RegisterStatusUpdater registers fn on the main handler, if it is able to report its status changes,
+// as the status of a Mirroring is the one of its main handler.
+func (m *Mirroring) RegisterStatusUpdater(fn func(up bool)) {
+	if updater, ok := m.handler.(statusUpdater); ok {
+		updater.RegisterStatusUpdater(fn)
+	}
 }
 
-func (m *Mirroring) getActiveMirrors() []http.Handler {
+// EnableComparison makes the Mirroring compare the responses of the mirrors with the response of the main handler,
+// and report the differences.
+func (m *Mirroring) EnableComparison(serviceName string, opts CompareOptions) error {
+	cmp, err := newComparator(serviceName, opts)
+	if err != nil {
+		return err
+	}
+
+	m.comparator = cmp
+	return nil
+}
+
+func (m *Mirroring) getActiveMirrors() []*mirrorHandler {
 	total := m.inc()
 
-	var mirrors []http.Handler
+	var mirrors []*mirrorHandler
 	for _, handler := range m.mirrorHandlers {
 		handler.lock.Lock()
+		if handler.down {
+			handler.lock.Unlock()
+			continue
+		}
 		if handler.count*100 < total*uint64(handler.percent) {
 			handler.count++
 			handler.lock.Unlock()
@@ -80,21 +113,29 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	logger := log.FromContext(req.Context())
-	rr, bytesRead, err := newReusableRequest(req, m.maxBodySize)
-	if err != nil && err != errBodyTooLarge {
+	rr, bytesRead, err := loadbalancer.NewReusableRequest(req, m.maxBodySize)
+	if err != nil && err != loadbalancer.ErrBodyTooLarge {
 		http.Error(rw, http.StatusText(http.StatusInternalServerError)+
 			fmt.Sprintf("error creating reusable request: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	if err == errBodyTooLarge {
+	if err == loadbalancer.ErrBodyTooLarge {
 		req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(bytesRead), req.Body))
 		m.handler.ServeHTTP(rw, req)
 		logger.Debugf("no mirroring, request body larger than allowed size")
 		return
 	}
 
-	m.handler.ServeHTTP(rw, rr.clone(req.Context()))
+	var mainResponse *capturedResponse
+	if m.comparator != nil {
+		// The main response is written as is to rw, while being captured.
+		capture := m.comparator.newCaptureResponseWriter(rw)
+		m.handler.ServeHTTP(capture, rr.Clone(req.Context()))
+		mainResponse = capture.captured()
+	} else {
+		m.handler.ServeHTTP(rw, rr.Clone(req.Context()))
+	}
 
 	select {
 	case <-req.Context().Done():
@@ -104,10 +145,12 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	default:
 	}
 
+	requestID := req.Header.Get(requestIDHeader)
+
 	m.routinePool.GoCtx(func(_ context.Context) {
 		for _, handler := range mirrors {
 			// prepare request, update body from buffer
-			r := rr.clone(req.Context())
+			r := rr.Clone(req.Context())
 
 			// In ServeHTTP, we rely on the presence of the accessLog datatable found in the request's context
 			// to know whether we should mutate said datatable (and contribute some fields to the log).
@@ -121,17 +164,52 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			// which would trigger a cancellation of the ongoing mirrored requests.
 			// Therefore, we give a new, non-cancellable context  to each of the mirrored calls,
 			// so they can terminate by themselves.
-			handler.ServeHTTP(m.rw, r.WithContext(contextStopPropagation{ctx}))
+			r = r.WithContext(contextStopPropagation{ctx})
+
+			if mainResponse == nil {
+				handler.ServeHTTP(m.rw, r)
+				continue
+			}
+
+			capture := m.comparator.newCaptureResponseWriter(nil)
+			handler.ServeHTTP(capture, r)
+
+			mirrorResponse := capture.captured()
+			if mirrorResponse == nil {
+				continue
+			}
+
+			if mismatches := m.comparator.compare(mainResponse, mirrorResponse); len(mismatches) > 0 {
+				m.comparator.report(ctx, requestID, handler.name, mismatches)
+			}
 		}
 	})
 }
 
 // AddMirror adds an httpHandler to mirror to.
+// The requests are not mirrored to the handler while it is down, if it is able to report its status.
 func (m *Mirroring) AddMirror(handler http.Handler, percent int) error {
+	return m.AddNamedMirror("", handler, percent)
+}
+
+// AddNamedMirror adds an httpHandler to mirror to, identified by name when its responses differ from the main ones.
+// The requests are not mirrored to the handler while it is down, if it is able to report its status.
+func (m *Mirroring) AddNamedMirror(name string, handler http.Handler, percent int) error {
 	if percent < 0 || percent > 100 {
 		return errors.New("percent must be between 0 and 100")
 	}
-	m.mirrorHandlers = append(m.mirrorHandlers, &mirrorHandler{Handler: handler, percent: percent})
+
+	mirror := &mirrorHandler{Handler: handler, name: name, percent: percent}
+	m.mirrorHandlers = append(m.mirrorHandlers, mirror)
+
+	if updater, ok := handler.(statusUpdater); ok {
+		updater.RegisterStatusUpdater(func(up bool) {
+			mirror.lock.Lock()
+			mirror.down = !up
+			mirror.lock.Unlock()
+		})
+	}
+
 	return nil
 }
 
@@ -160,65 +238,3 @@ type contextStopPropagation struct {
 func (c contextStopPropagation) Done() <-chan struct{} {
 	return make(chan struct{})
 }
-
-// reusableRequest keeps in memory the body of the given request,
-// so that the request can be fully cloned by each mirror.
-type reusableRequest struct {
-	req  *http.Request
-	body []byte
-}
-
-var errBodyTooLarge = errors.New("request body too large")
-
-// if the returned error is errBodyTooLarge, newReusableRequest also returns the
-// bytes that were already consumed from the request's body.
-func newReusableRequest(req *http.Request, maxBodySize int64) (*reusableRequest, []byte, error) {
-	if req == nil {
-		return nil, nil, errors.New("nil input request")
-	}
-	if req.Body == nil {
-		return &reusableRequest{req: req}, nil, nil
-	}
-
-	// unbounded body size
-	if maxBodySize < 0 {
-		body, err := ioutil.ReadAll(req.Body)
-		if err != nil {
-			return nil, nil, err
-		}
-		return &reusableRequest{
-			req:  req,
-			body: body,
-		}, nil, nil
-	}
-
-	// we purposefully try to read _more_ than maxBodySize to detect whether
-	// the request body is larger than what we allow for the mirrors.
-	body := make([]byte, maxBodySize+1)
-	n, err := io.ReadFull(req.Body, body)
-	if err != nil && err != io.ErrUnexpectedEOF {
-		return nil, nil, err
-	}
-
-	// we got an ErrUnexpectedEOF, which means there was less than maxBodySize data to read,
-	// which permits us sending also to all the mirrors later.
-	if err == io.ErrUnexpectedEOF {
-		return &reusableRequest{
-			req:  req,
-			body: body[:n],
-		}, nil, nil
-	}
-
-	// err == nil , which means data size > maxBodySize
-	return nil, body[:n], errBodyTooLarge
-}
-
-func (rr reusableRequest) clone(ctx context.Context) *http.Request {
-	req := rr.req.Clone(ctx)
-
-	if rr.body != nil {
-		req.Body = ioutil.NopCloser(bytes.NewReader(rr.body))
-	}
-
-	return req
-}
@@ -73,6 +73,60 @@ func TestMirroringOn10(t *testing.T) {
 	assert.Equal(t, 5, int(val2))
 }
 
+type statusHandler struct {
+	http.HandlerFunc
+	updaters []func(up bool)
+}
+
+func (s *statusHandler) RegisterStatusUpdater(fn func(up bool)) {
+	s.updaters = append(s.updaters, fn)
+}
+
+func (s *statusHandler) setStatus(up bool) {
+	for _, fn := range s.updaters {
+		fn(up)
+	}
+}
+
+func TestMirroringPropagate(t *testing.T) {
+	handler := &statusHandler{HandlerFunc: func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}}
+
+	var countMirror int32
+	mirrorHandler := &statusHandler{HandlerFunc: func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&countMirror, 1)
+	}}
+
+	pool := safe.NewPool(context.Background())
+	mirror := New(handler, pool, defaultMaxBodySize)
+	err := mirror.AddMirror(mirrorHandler, 100)
+	assert.NoError(t, err)
+
+	var statuses []bool
+	mirror.RegisterStatusUpdater(func(up bool) {
+		statuses = append(statuses, up)
+	})
+
+	// The status of the Mirroring is the one of its main handler.
+	handler.setStatus(false)
+	assert.Equal(t, []bool{false}, statuses)
+
+	mirrorHandler.setStatus(false)
+	for i := 0; i < 10; i++ {
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	mirrorHandler.setStatus(true)
+	for i := 0; i < 10; i++ {
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	pool.Stop()
+
+	assert.Equal(t, 10, int(atomic.LoadInt32(&countMirror)))
+}
+
 func TestInvalidPercent(t *testing.T) {
 	mirror := New(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), safe.NewPool(context.Background()), defaultMaxBodySize)
 	err := mirror.AddMirror(nil, -1)
@@ -176,80 +230,3 @@ func TestMirroringWithBody(t *testing.T) {
 	val := atomic.LoadInt32(&countMirror)
 	assert.Equal(t, numMirrors, int(val))
 }
-
-func TestCloneRequest(t *testing.T) {
-	t.Run("http request body is nil", func(t *testing.T) {
-		req, err := http.NewRequest(http.MethodPost, "/", nil)
-		assert.NoError(t, err)
-
-		ctx := req.Context()
-		rr, _, err := newReusableRequest(req, defaultMaxBodySize)
-		assert.NoError(t, err)
-
-		// first call
-		cloned := rr.clone(ctx)
-		assert.Equal(t, cloned, req)
-		assert.Nil(t, cloned.Body)
-
-		// second call
-		cloned = rr.clone(ctx)
-		assert.Equal(t, cloned, req)
-		assert.Nil(t, cloned.Body)
-	})
-
-	t.Run("http request body is not nil", func(t *testing.T) {
-		bb := []byte(`¯\_(ツ)_/¯`)
-		contentLength := len(bb)
-
-		buf := bytes.NewBuffer(bb)
-		req, err := http.NewRequest(http.MethodPost, "/", buf)
-		assert.NoError(t, err)
-
-		ctx := req.Context()
-		req.ContentLength = int64(contentLength)
-
-		rr, _, err := newReusableRequest(req, defaultMaxBodySize)
-		assert.NoError(t, err)
-
-		// first call
-		cloned := rr.clone(ctx)
-		body, err := ioutil.ReadAll(cloned.Body)
-		assert.NoError(t, err)
-		assert.Equal(t, bb, body)
-
-		// second call
-		cloned = rr.clone(ctx)
-		body, err = ioutil.ReadAll(cloned.Body)
-		assert.NoError(t, err)
-		assert.Equal(t, bb, body)
-	})
-
-	t.Run("failed case", func(t *testing.T) {
-		bb := []byte(`1234567890`)
-		buf := bytes.NewBuffer(bb)
-
-		req, err := http.NewRequest(http.MethodPost, "/", buf)
-		assert.NoError(t, err)
-
-		_, expectedBytes, err := newReusableRequest(req, 2)
-		assert.Error(t, err)
-		assert.Equal(t, bb[:3], expectedBytes)
-	})
-
-	t.Run("valid case with maxBodySize", func(t *testing.T) {
-		bb := []byte(`1234567890`)
-		buf := bytes.NewBuffer(bb)
-
-		req, err := http.NewRequest(http.MethodPost, "/", buf)
-		assert.NoError(t, err)
-
-		_, expectedBytes, err := newReusableRequest(req, 20)
-		assert.NoError(t, err)
-		assert.Nil(t, expectedBytes)
-	})
-
-	t.Run("no request given", func(t *testing.T) {
-		_, _, err := newReusableRequest(nil, defaultMaxBodySize)
-		assert.Error(t, err)
-	})
-}
@@ -0,0 +1,40 @@
+package loadbalancer
+
+import (
+	"net/url"
+
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// ServerWeight returns the weight set by the given server options.
+// The healthcheck.Balancer interface conveys a server weight through oxy server options,
+// whose target is private to oxy, so they are applied to a scratch round robin to read it back.
+func ServerWeight(u *url.URL, options ...roundrobin.ServerOption) (int, error) {
+	rr, err := roundrobin.New(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := rr.UpsertServer(u, options...); err != nil {
+		return 0, err
+	}
+
+	weight, _ := rr.ServerWeight(u)
+	return weight, nil
+}
+
+// sameURL reports whether the given URLs identify the same server, as in the oxy round robin.
+func sameURL(a, b *url.URL) bool {
+	return a.Path == b.Path && a.Host == b.Host && a.Scheme == b.Scheme
+}
+
+// FindURL returns the index of the server identified by u among the n servers whose URLs are given by urlAt,
+// or -1 if there is none.
+func FindURL(n int, urlAt func(i int) *url.URL, u *url.URL) int {
+	for i := 0; i < n; i++ {
+		if sameURL(u, urlAt(i)) {
+			return i
+		}
+	}
+	return -1
+}
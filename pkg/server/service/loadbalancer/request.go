@@ -0,0 +1,68 @@
+package loadbalancer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// ErrBodyTooLarge is returned by NewReusableRequest when the request body is larger than the allowed size.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// ReusableRequest keeps in memory the body of the given request,
+// so that the request can be fully cloned, to be sent several times.
+type ReusableRequest struct {
+	req  *http.Request
+	body []byte
+}
+
+// NewReusableRequest reads the body of the given request, up to maxBodySize (-1 for unlimited).
+// If the returned error is ErrBodyTooLarge, NewReusableRequest also returns the
+// bytes that were already consumed from the request's body.
+func NewReusableRequest(req *http.Request, maxBodySize int64) (*ReusableRequest, []byte, error) {
+	if req == nil {
+		return nil, nil, errors.New("nil input request")
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return &ReusableRequest{req: req}, nil, nil
+	}
+
+	// unbounded body size
+	if maxBodySize < 0 {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &ReusableRequest{req: req, body: body}, nil, nil
+	}
+
+	// we purposefully try to read _more_ than maxBodySize to detect whether
+	// the request body is larger than what we allow.
+	body := make([]byte, maxBodySize+1)
+	n, err := io.ReadFull(req.Body, body)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, nil, err
+	}
+
+	// we got an EOF, which means there was at most maxBodySize data to read.
+	if err != nil {
+		return &ReusableRequest{req: req, body: body[:n]}, nil, nil
+	}
+
+	// err == nil, which means data size > maxBodySize
+	return nil, body[:n], ErrBodyTooLarge
+}
+
+// Clone returns a copy of the request, with a new reader of its body.
+func (rr ReusableRequest) Clone(ctx context.Context) *http.Request {
+	req := rr.req.Clone(ctx)
+
+	if rr.body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(rr.body))
+	}
+
+	return req
+}
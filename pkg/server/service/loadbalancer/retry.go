@@ -0,0 +1,79 @@
+package loadbalancer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/containous/traefik/v2/pkg/middlewares/retry"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/hedging"
+)
+
+type redispatchesKey struct{}
+
+// Balancer is a load-balancer of servers.
+type Balancer interface {
+	http.Handler
+	Servers() []*url.URL
+}
+
+// FailedServersAvoider is set between a load-balancer and its forwarder,
+// to send a retried request again through the load-balancer when it chose a server on which a previous attempt failed,
+// and likewise a hedged request when it chose a server to which another attempt was sent.
+// When the load-balancer has sticky sessions, the retried requests stay on their server,
+// while the hedged requests are sent again without their sticky cookie.
+type FailedServersAvoider struct {
+	next         http.Handler
+	balancer     Balancer
+	stickyCookie string
+}
+
+// NewFailedServersAvoider creates a new FailedServersAvoider, forwarding the requests to next.
+// stickyCookie is the name of the sticky session cookie of the load-balancer, if any.
+func NewFailedServersAvoider(next http.Handler, stickyCookie string) *FailedServersAvoider {
+	return &FailedServersAvoider{next: next, stickyCookie: stickyCookie}
+}
+
+// SetBalancer sets the load-balancer in front of the FailedServersAvoider.
+func (a *FailedServersAvoider) SetBalancer(balancer Balancer) {
+	a.balancer = balancer
+}
+
+func (a *FailedServersAvoider) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	server := req.URL.String()
+
+	// The load-balancer is asked at most once per server, as it may keep choosing the same one.
+	redispatches, _ := req.Context().Value(redispatchesKey{}).(int)
+	avoid := hedging.AvoidServer(req, server) || (a.stickyCookie == "" && retry.AvoidServer(req, server))
+	if a.balancer != nil && avoid && redispatches < len(a.balancer.Servers()) {
+		ctx := context.WithValue(req.Context(), redispatchesKey{}, redispatches+1)
+		a.balancer.ServeHTTP(rw, withoutCookie(req.WithContext(ctx), a.stickyCookie))
+		return
+	}
+
+	retry.UseServer(req, server)
+	hedging.UseServer(req, server)
+	a.next.ServeHTTP(rw, req)
+}
+
+// withoutCookie returns the request without the given cookie, so that the load-balancer does not stick to its server.
+func withoutCookie(req *http.Request, name string) *http.Request {
+	if name == "" {
+		return req
+	}
+
+	if _, err := req.Cookie(name); err != nil {
+		return req
+	}
+
+	cookies := req.Cookies()
+
+	newReq := req.Clone(req.Context())
+	newReq.Header.Del("Cookie")
+	for _, c := range cookies {
+		if c.Name != name {
+			newReq.AddCookie(c)
+		}
+	}
+	return newReq
+}
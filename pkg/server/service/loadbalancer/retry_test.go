@@ -0,0 +1,138 @@
+package loadbalancer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/middlewares/retry"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/hedging"
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceBalancer chooses its servers in the given order.
+type sequenceBalancer struct {
+	next     http.Handler
+	sequence []*url.URL
+	picks    int
+}
+
+func (b *sequenceBalancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	newReq := *req
+	newReq.URL = b.sequence[b.picks%len(b.sequence)]
+	b.picks++
+
+	b.next.ServeHTTP(rw, &newReq)
+}
+
+func (b *sequenceBalancer) Servers() []*url.URL {
+	return []*url.URL{b.sequence[0], b.sequence[len(b.sequence)-1]}
+}
+
+func TestFailedServersAvoider(t *testing.T) {
+	var served []string
+	fwd := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		httptrace.ContextClientTrace(req.Context()).WroteHeaders()
+
+		served = append(served, req.URL.Host)
+		if req.URL.Host == "failing" {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	avoider := NewFailedServersAvoider(fwd, "")
+	balancer := &sequenceBalancer{
+		next: avoider,
+		sequence: []*url.URL{
+			testhelpers.MustParseURL("http://failing"),
+			testhelpers.MustParseURL("http://failing"),
+			testhelpers.MustParseURL("http://working"),
+		},
+	}
+	avoider.SetBalancer(balancer)
+
+	handler, err := retry.New(context.Background(), balancer, dynamic.Retry{Attempts: 2, Status: []string{"503"}}, retry.Listeners{}, "retry")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, []string{"failing", "working"}, served)
+	assert.Equal(t, 3, balancer.picks)
+}
+
+// stickyBalancer chooses the server named by its sticky cookie, or the fallback server.
+type stickyBalancer struct {
+	next     http.Handler
+	sticky   *url.URL
+	fallback *url.URL
+}
+
+func (b *stickyBalancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	newReq := *req
+	newReq.URL = b.fallback
+	if c, err := req.Cookie("sticky"); err == nil && c.Value == b.sticky.Host {
+		newReq.URL = b.sticky
+	}
+
+	b.next.ServeHTTP(rw, &newReq)
+}
+
+func (b *stickyBalancer) Servers() []*url.URL {
+	return []*url.URL{b.sticky, b.fallback}
+}
+
+func TestFailedServersAvoider_stickyHedge(t *testing.T) {
+	var mu sync.Mutex
+	var served []string
+	fwd := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		served = append(served, req.URL.Host)
+		mu.Unlock()
+
+		if req.URL.Host == "slow" {
+			<-req.Context().Done()
+			return
+		}
+
+		_, err := req.Cookie("sticky")
+		assert.Error(t, err)
+		assert.Equal(t, "other=foo", req.Header.Get("Cookie"))
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	avoider := NewFailedServersAvoider(fwd, "sticky")
+	balancer := &stickyBalancer{
+		next:     avoider,
+		sticky:   testhelpers.MustParseURL("http://slow"),
+		fallback: testhelpers.MustParseURL("http://fast"),
+	}
+	avoider.SetBalancer(balancer)
+
+	handler, err := hedging.New(balancer, "foo", dynamic.Hedging{Delay: types.Duration(10 * time.Millisecond), MaxHedges: 1}, nil)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/", nil)
+	req.Header.Set("Cookie", "sticky=slow; other=foo")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"slow", "fast"}, served)
+}
@@ -0,0 +1,215 @@
+package slowstart
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/generation"
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// weightScale is the factor applied to the server weights in the underlying balancer,
+// so that a warming up server gets a fraction of its weight, even when its weight is 1.
+// It is also the number of steps of the ramp.
+const weightScale = 10
+
+// balancer is the set of operations of the underlying load-balancer.
+type balancer interface {
+	http.Handler
+	Servers() []*url.URL
+	RemoveServer(u *url.URL) error
+	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+}
+
+// Tracker keeps track, across configuration reloads, of the time each server of each service became ready,
+// so that the servers already warmed up do not go through a slow start again when the load-balancers are rebuilt.
+// A nil Tracker does not keep track of anything, and every server added goes through a slow start.
+type Tracker struct {
+	servers *generation.Map
+}
+
+// NewTracker creates a new Tracker.
+func NewTracker() *Tracker {
+	return &Tracker{servers: generation.NewMap()}
+}
+
+// NewGeneration must be called before building the load-balancers of a new configuration.
+// It forgets the servers that were not part of the previous configuration,
+// so that a server removed and then added again goes through a slow start.
+func (t *Tracker) NewGeneration() {
+	t.servers.NewGeneration()
+}
+
+// start returns the time the given server became ready, which is now if the server is unknown.
+func (t *Tracker) start(key string, now time.Time) time.Time {
+	if t == nil {
+		return now
+	}
+
+	start, _ := t.servers.Get(key, nil, func() (interface{}, error) {
+		return now, nil
+	})
+	return start.(time.Time)
+}
+
+// forget removes the given server, so that it goes through a slow start the next time it is added.
+func (t *Tracker) forget(key string) {
+	if t == nil {
+		return
+	}
+
+	t.servers.Delete(key)
+}
+
+type rampingServer struct {
+	url    *url.URL
+	weight int
+	start  time.Time
+	// ramping is true until the server has reached its full weight.
+	ramping bool
+}
+
+// SlowStart wraps a load-balancer, and raises linearly over a duration the weight of the servers added to it,
+// from a tenth of their weight to their full weight.
+// The weights known to the underlying load-balancer are scaled accordingly,
+// while ServerWeight reports the configured weights.
+type SlowStart struct {
+	balancer
+	serviceName string
+	duration    time.Duration
+	tracker     *Tracker
+	now         func() time.Time
+	afterFn     func(time.Duration, func())
+
+	mu      sync.Mutex
+	servers map[string]*rampingServer
+}
+
+// New creates a new SlowStart.
+func New(lb balancer, serviceName string, duration time.Duration, tracker *Tracker) *SlowStart {
+	return &SlowStart{
+		balancer:    lb,
+		serviceName: serviceName,
+		duration:    duration,
+		tracker:     tracker,
+		now:         time.Now,
+		afterFn: func(d time.Duration, f func()) {
+			time.AfterFunc(d, f)
+		},
+		servers: make(map[string]*rampingServer),
+	}
+}
+
+// ServerWeight returns the weight of the server identified by the given URL, as configured,
+// regardless of its current slow start.
+func (s *SlowStart) ServerWeight(u *url.URL) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	srv, ok := s.servers[u.String()]
+	if !ok {
+		return -1, false
+	}
+	return srv.weight, true
+}
+
+// UpsertServer adds the given server to the load-balancer, starting its slow start,
+// or updates its weight if it is already known.
+func (s *SlowStart) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	weight, err := loadbalancer.ServerWeight(u, options...)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := u.String()
+	if srv, ok := s.servers[key]; ok {
+		srv.weight = weight
+		return s.balancer.UpsertServer(u, roundrobin.Weight(s.effectiveWeight(srv)))
+	}
+
+	srv := &rampingServer{
+		url:     u,
+		weight:  weight,
+		start:   s.tracker.start(s.trackerKey(key), s.now()),
+		ramping: true,
+	}
+	s.servers[key] = srv
+
+	if err := s.balancer.UpsertServer(u, roundrobin.Weight(s.effectiveWeight(srv))); err != nil {
+		delete(s.servers, key)
+		return err
+	}
+
+	if srv.ramping {
+		log.WithoutContext().Debugf("Slow start of server %s in service %s for %s", key, s.serviceName, s.duration-s.now().Sub(srv.start))
+		s.scheduleStep(srv)
+	}
+	return nil
+}
+
+// RemoveServer removes the given server from the load-balancer,
+// so that it goes through a new slow start when it is added back.
+func (s *SlowStart) RemoveServer(u *url.URL) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.balancer.RemoveServer(u); err != nil {
+		return err
+	}
+
+	key := u.String()
+	delete(s.servers, key)
+	s.tracker.forget(s.trackerKey(key))
+	return nil
+}
+
+// effectiveWeight must be called with the lock held.
+// It computes the current scaled weight of the server, and updates its ramping status.
+func (s *SlowStart) effectiveWeight(srv *rampingServer) int {
+	full := srv.weight * weightScale
+
+	elapsed := s.now().Sub(srv.start)
+	if elapsed >= s.duration {
+		srv.ramping = false
+		return full
+	}
+
+	weight := int(int64(full) * int64(elapsed) / int64(s.duration))
+	if weight < srv.weight {
+		weight = srv.weight
+	}
+	return weight
+}
+
+// scheduleStep must be called with the lock held.
+func (s *SlowStart) scheduleStep(srv *rampingServer) {
+	s.afterFn(s.duration/weightScale, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// The server has been removed, or removed and added again in the meantime.
+		if s.servers[srv.url.String()] != srv {
+			return
+		}
+
+		if err := s.balancer.UpsertServer(srv.url, roundrobin.Weight(s.effectiveWeight(srv))); err != nil {
+			log.WithoutContext().Errorf("Unable to update the weight of server %s in service %s: %v", srv.url, s.serviceName, err)
+			return
+		}
+
+		if srv.ramping {
+			s.scheduleStep(srv)
+		}
+	})
+}
+
+func (s *SlowStart) trackerKey(serverURL string) string {
+	return s.serviceName + "|" + serverURL
+}
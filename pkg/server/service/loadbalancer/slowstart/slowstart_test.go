@@ -0,0 +1,121 @@
+package slowstart
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+type timer struct {
+	delay time.Duration
+	fn    func()
+}
+
+func newTestSlowStart(t *testing.T, tracker *Tracker, now *time.Time) (*SlowStart, *roundrobin.RoundRobin, *[]timer) {
+	t.Helper()
+
+	rr, err := roundrobin.New(http.NotFoundHandler())
+	require.NoError(t, err)
+
+	timers := &[]timer{}
+	slowStart := New(rr, "foo", 10*time.Second, tracker)
+	slowStart.now = func() time.Time { return *now }
+	slowStart.afterFn = func(d time.Duration, f func()) {
+		*timers = append(*timers, timer{delay: d, fn: f})
+	}
+
+	return slowStart, rr, timers
+}
+
+func TestSlowStart(t *testing.T) {
+	now := time.Now()
+	slowStart, rr, timers := newTestSlowStart(t, NewTracker(), &now)
+
+	serverURL := testhelpers.MustParseURL("http://foo:80")
+	require.NoError(t, slowStart.UpsertServer(serverURL, roundrobin.Weight(2)))
+
+	weight, _ := rr.ServerWeight(serverURL)
+	assert.Equal(t, 2, weight)
+
+	reportedWeight, ok := slowStart.ServerWeight(serverURL)
+	assert.True(t, ok)
+	assert.Equal(t, 2, reportedWeight)
+
+	expected := []int{5, 10, 20}
+	start := now
+	for i, elapsed := range []time.Duration{2500 * time.Millisecond, 5 * time.Second, 10 * time.Second} {
+		require.Len(t, *timers, i+1)
+		assert.Equal(t, time.Second, (*timers)[i].delay)
+
+		now = start.Add(elapsed)
+		(*timers)[i].fn()
+
+		weight, _ = rr.ServerWeight(serverURL)
+		assert.Equal(t, expected[i], weight)
+	}
+
+	// The ramp is over.
+	assert.Len(t, *timers, 3)
+}
+
+func TestSlowStart_removedServer(t *testing.T) {
+	now := time.Now()
+	slowStart, rr, timers := newTestSlowStart(t, NewTracker(), &now)
+
+	serverURL := testhelpers.MustParseURL("http://foo:80")
+	require.NoError(t, slowStart.UpsertServer(serverURL))
+
+	now = now.Add(time.Minute)
+	(*timers)[0].fn()
+
+	weight, _ := rr.ServerWeight(serverURL)
+	assert.Equal(t, 10, weight)
+
+	// A server removed, e.g. after a failed health check, goes through a new slow start.
+	require.NoError(t, slowStart.RemoveServer(serverURL))
+	require.NoError(t, slowStart.UpsertServer(serverURL))
+
+	weight, _ = rr.ServerWeight(serverURL)
+	assert.Equal(t, 1, weight)
+}
+
+func TestSlowStart_reload(t *testing.T) {
+	tracker := NewTracker()
+	now := time.Now()
+
+	warmURL := testhelpers.MustParseURL("http://warm:80")
+	goneURL := testhelpers.MustParseURL("http://gone:80")
+
+	tracker.NewGeneration()
+	slowStart, _, _ := newTestSlowStart(t, tracker, &now)
+	require.NoError(t, slowStart.UpsertServer(warmURL))
+	require.NoError(t, slowStart.UpsertServer(goneURL))
+
+	now = now.Add(time.Minute)
+
+	// The servers of the previous configuration are already warmed up.
+	tracker.NewGeneration()
+	slowStart, rr, timers := newTestSlowStart(t, tracker, &now)
+	require.NoError(t, slowStart.UpsertServer(warmURL))
+
+	weight, _ := rr.ServerWeight(warmURL)
+	assert.Equal(t, 10, weight)
+	assert.Empty(t, *timers)
+
+	// A server absent from the previous configuration goes through a new slow start.
+	tracker.NewGeneration()
+	slowStart, rr, timers = newTestSlowStart(t, tracker, &now)
+	require.NoError(t, slowStart.UpsertServer(warmURL))
+	require.NoError(t, slowStart.UpsertServer(goneURL))
+
+	weight, _ = rr.ServerWeight(warmURL)
+	assert.Equal(t, 10, weight)
+	weight, _ = rr.ServerWeight(goneURL)
+	assert.Equal(t, 1, weight)
+	assert.Len(t, *timers, 1)
+}
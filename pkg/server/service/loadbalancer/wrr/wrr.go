@@ -2,6 +2,8 @@ package wrr
 
 import (
 	"container/heap"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -23,9 +25,11 @@ type stickyCookie struct {
 	httpOnly bool
 }
 
+var errNoAvailableServer = errors.New("no available server")
+
 // New creates a new load balancer.
 func New(sticky *dynamic.Sticky) *Balancer {
-	balancer := &Balancer{}
+	balancer := &Balancer{status: make(map[string]struct{})}
 	if sticky != nil && sticky.Cookie != nil {
 		balancer.stickyCookie = &stickyCookie{
 			name:     sticky.Cookie.Name,
@@ -78,6 +82,74 @@ type Balancer struct {
 	mutex       sync.RWMutex
 	handlers    []*namedHandler
 	curDeadline float64
+	// status is the set of the child services which are up, keyed by name.
+	// A child service is up when it is added, and its status is then changed through SetStatus.
+	status map[string]struct{}
+	// updaters are called whenever the status of the Balancer changes,
+	// i.e. when all of its child services are down, or when one of them is back up.
+	updaters []func(up bool)
+}
+
+// SetStatus sets the status (UP or DOWN) of the given child service.
+// The requests are not sent anymore to a child service which is down.
+func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.hasHandler(childName) {
+		// Child services with a non-positive weight are not part of the Balancer.
+		return
+	}
+
+	upBefore := len(b.status) > 0
+
+	log.FromContext(ctx).Debugf("Setting status of child service %s in WRR to up: %v", childName, up)
+	if up {
+		b.status[childName] = struct{}{}
+	} else {
+		delete(b.status, childName)
+	}
+
+	upAfter := len(b.status) > 0
+	if upBefore == upAfter {
+		return
+	}
+
+	log.FromContext(ctx).Debugf("Propagating new status of WRR, up: %v", upAfter)
+	for _, fn := range b.updaters {
+		fn(upAfter)
+	}
+}
+
+// RegisterStatusUpdater adds fn to the list of functions called when the status of the Balancer changes.
+// As the Balancer is assumed to be up, fn is called right away if none of its child services is up.
+func (b *Balancer) RegisterStatusUpdater(fn func(up bool)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.updaters = append(b.updaters, fn)
+
+	if len(b.status) == 0 {
+		fn(false)
+	}
+}
+
+// hasHandler must be called with the lock held.
+func (b *Balancer) hasHandler(name string) bool {
+	for _, handler := range b.handlers {
+		if handler.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Balancer) isUp(name string) bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	_, ok := b.status[name]
+	return ok
 }
 
 func (b *Balancer) nextServer() (*namedHandler, error) {
@@ -88,14 +160,25 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 		return nil, fmt.Errorf("no servers in the pool")
 	}
 
-	// Pick handler with closest deadline.
-	handler := heap.Pop(b).(*namedHandler)
+	if len(b.status) == 0 {
+		return nil, errNoAvailableServer
+	}
+
+	var handler *namedHandler
+	for {
+		// Pick handler with closest deadline.
+		handler = heap.Pop(b).(*namedHandler)
+
+		// curDeadline should be handler's deadline so that new added entry would have a fair competition environment with the old ones.
+		b.curDeadline = handler.deadline
+		handler.deadline += 1 / handler.weight
 
-	// curDeadline should be handler's deadline so that new added entry would have a fair competition environment with the old ones.
-	b.curDeadline = handler.deadline
-	handler.deadline += 1 / handler.weight
+		heap.Push(b, handler)
 
-	heap.Push(b, handler)
+		if _, ok := b.status[handler.name]; ok {
+			break
+		}
+	}
 
 	log.WithoutContext().Debugf("Service selected by WRR: %s", handler.name)
 	return handler, nil
@@ -111,7 +194,7 @@ func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 		if err == nil && cookie != nil {
 			for _, handler := range b.handlers {
-				if handler.name == cookie.Value {
+				if handler.name == cookie.Value && b.isUp(handler.name) {
 					handler.ServeHTTP(w, req)
 					return
 				}
@@ -120,6 +203,10 @@ func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 
 	server, err := b.nextServer()
+	if errors.Is(err, errNoAvailableServer) {
+		http.Error(w, errNoAvailableServer.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
 		http.Error(w, http.StatusText(http.StatusInternalServerError)+err.Error(), http.StatusInternalServerError)
 		return
@@ -147,10 +234,9 @@ func (b *Balancer) AddService(name string, handler http.Handler, weight *int) {
 
 	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
 
-	// use RWLock to protect b.curDeadline
-	b.mutex.RLock()
+	b.mutex.Lock()
 	h.deadline = b.curDeadline + 1/h.weight
-	b.mutex.RUnlock()
-
 	heap.Push(b, h)
+	b.status[name] = struct{}{}
+	b.mutex.Unlock()
 }
@@ -4,17 +4,20 @@ import (
 	"net/http"
 
 	"github.com/containous/traefik/v2/pkg/api"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/metrics"
 	"github.com/containous/traefik/v2/pkg/safe"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/slowstart"
+	"github.com/containous/traefik/v2/pkg/server/service/resolver"
 )
 
 // ManagerFactory a factory of service manager.
 type ManagerFactory struct {
 	metricsRegistry metrics.Registry
 
-	defaultRoundTripper http.RoundTripper
+	roundTripperManager *RoundTripperManager
 
 	api              func(configuration *runtime.Configuration) http.Handler
 	restHandler      http.Handler
@@ -23,14 +26,19 @@ type ManagerFactory struct {
 	pingHandler      http.Handler
 
 	routinesPool *safe.Pool
+
+	slowStartTracker *slowstart.Tracker
+	dnsWatchers      *resolver.Watchers
 }
 
 // NewManagerFactory creates a new ManagerFactory.
 func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *safe.Pool, metricsRegistry metrics.Registry) *ManagerFactory {
 	factory := &ManagerFactory{
 		metricsRegistry:     metricsRegistry,
-		defaultRoundTripper: setupDefaultRoundTripper(staticConfiguration.ServersTransport),
+		roundTripperManager: NewRoundTripperManager(setupDefaultRoundTripper(staticConfiguration.ServersTransport)),
 		routinesPool:        routinesPool,
+		slowStartTracker:    slowstart.NewTracker(),
+		dnsWatchers:         resolver.NewWatchers(resolver.NewResolver(nil)),
 	}
 
 	if staticConfiguration.API != nil {
@@ -59,8 +67,24 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 	return factory
 }
 
+// UpdateServersTransports updates the round trippers built from the given ServersTransports,
+// which must be keyed by qualified name.
+func (f *ManagerFactory) UpdateServersTransports(serversTransports map[string]*dynamic.ServersTransport) {
+	f.roundTripperManager.Update(serversTransports)
+}
+
 // Build creates a service manager.
 func (f *ManagerFactory) Build(configuration *runtime.Configuration) *InternalHandlers {
-	svcManager := NewManager(configuration.Services, f.defaultRoundTripper, f.metricsRegistry, f.routinesPool)
+	svcManager := NewManager(configuration.Services, f.roundTripperManager, f.metricsRegistry, f.routinesPool)
+
+	// The slow start tracker outlives the service managers,
+	// so that the servers already warmed up keep their full weight after a reload.
+	f.slowStartTracker.NewGeneration()
+	svcManager.SetSlowStartTracker(f.slowStartTracker)
+
+	// The host names of the servers of the previous configuration are no longer watched.
+	f.dnsWatchers.NewGeneration()
+	svcManager.SetDNSWatchers(f.dnsWatchers)
+
 	return NewInternalHandlers(f.api, configuration, f.restHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, svcManager)
 }
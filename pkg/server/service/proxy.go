@@ -35,6 +35,11 @@ func buildProxy(passHostHeader *bool, responseForwarding *dynamic.ResponseForwar
 
 	proxy := &httputil.ReverseProxy{
 		Director: func(outReq *http.Request) {
+			// The path of a unix socket server URL is the one of the socket, kept as the host for the round tripper.
+			if outReq.URL.Scheme == unixSocketScheme && outReq.URL.Host == "" {
+				outReq.URL.Host = outReq.URL.Path
+			}
+
 			u := outReq.URL
 			if outReq.RequestURI != "" {
 				parsedURL, err := url.ParseRequestURI(outReq.RequestURI)
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/containous/traefik/v2/pkg/tcp"
+)
+
+type clientAddrKey struct{}
+
+// proxyProtocolRoundTripper sends the requests over connections starting with a PROXY protocol header,
+// which describes the connection of the client the request comes from.
+// As a connection is then bound to a client, the connections are not reused across requests.
+type proxyProtocolRoundTripper struct {
+	transport *http.Transport
+}
+
+func newProxyProtocolRoundTripper(roundTripper http.RoundTripper, version int) (http.RoundTripper, error) {
+	if version < 1 || version > 2 {
+		return nil, fmt.Errorf("unknown PROXY protocol version %d", version)
+	}
+
+	transport, err := httpTransport(roundTripper)
+	if err != nil {
+		return nil, fmt.Errorf("PROXY protocol is not supported by the round tripper %T", roundTripper)
+	}
+
+	transport = transport.Clone()
+	transport.DisableKeepAlives = true
+	// HTTP/2 would multiplex the requests of different clients over the same connection.
+	transport.ForceAttemptHTTP2 = false
+	transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+
+	dialContext := transport.DialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		// Without a client address, as for the health checks, the header tells that the connection is not relayed.
+		src, _ := ctx.Value(clientAddrKey{}).(net.Addr)
+		dst, _ := ctx.Value(http.LocalAddrContextKey).(net.Addr)
+
+		if err := tcp.WriteProxyProtocolHeader(conn, version, src, dst); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("error while writing PROXY protocol header: %w", err)
+		}
+
+		return conn, nil
+	}
+
+	return &proxyProtocolRoundTripper{transport: transport}, nil
+}
+
+func (p *proxyProtocolRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The h2c connections are not dialed by the transport, and multiplex the requests of different clients.
+	if req.URL.Scheme == "h2c" {
+		return nil, errors.New("PROXY protocol is not supported with h2c servers")
+	}
+
+	if clientAddr := parseClientAddr(req.RemoteAddr); clientAddr != nil {
+		req = req.WithContext(context.WithValue(req.Context(), clientAddrKey{}, clientAddr))
+	}
+
+	return p.transport.RoundTrip(req)
+}
+
+func parseClientAddr(remoteAddr string) net.Addr {
+	host, port, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return nil
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return nil
+	}
+
+	return &net.TCPAddr{IP: ip, Port: portNum}
+}
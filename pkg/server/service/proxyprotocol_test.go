@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	proxyprotocol "github.com/c0va23/go-proxyprotocol"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_BuildWithProxyProtocol(t *testing.T) {
+	for _, version := range []int{1, 2} {
+		version := version
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			// The server reads the client address from the PROXY protocol header.
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte(req.RemoteAddr))
+			}))
+			_ = server.Listener.Close()
+			server.Listener = proxyprotocol.NewDefaultListener(listener).WithSourceChecker(func(net.Addr) (bool, error) {
+				return true, nil
+			})
+			server.Start()
+			defer server.Close()
+
+			manager := NewManager(map[string]*runtime.ServiceInfo{
+				"test@file": {
+					Service: &dynamic.Service{
+						LoadBalancer: &dynamic.ServersLoadBalancer{
+							Servers:       []dynamic.Server{{URL: "http://" + listener.Addr().String()}},
+							ProxyProtocol: &dynamic.ProxyProtocol{Version: version},
+						},
+					},
+				},
+			}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+
+			handler, err := manager.BuildHTTP(context.Background(), "test@file", nil)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.com/", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 80}))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, "10.0.0.1:1234", recorder.Body.String())
+		})
+	}
+}
+
+func TestNewProxyProtocolRoundTripper_invalidVersion(t *testing.T) {
+	_, err := newProxyProtocolRoundTripper(http.DefaultTransport, 3)
+	assert.Error(t, err)
+}
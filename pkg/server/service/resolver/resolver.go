@@ -0,0 +1,412 @@
+// Package resolver resolves the host names of the servers of the services,
+// and keeps their addresses up to date with the DNS records.
+package resolver
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Record types a host name can be resolved with.
+const (
+	TypeA   = "A"
+	TypeSRV = "SRV"
+)
+
+const (
+	resolvConf   = "/etc/resolv.conf"
+	hostsFile    = "/etc/hosts"
+	queryTimeout = 5 * time.Second
+	// hostsTTL is the time to live of the addresses found in the hosts file.
+	hostsTTL = time.Minute
+	// systemTTL is the time to live of the addresses given by the system resolver, which does not tell their TTL.
+	systemTTL = time.Minute
+)
+
+// errNotFound is returned when a name does not exist, or has no records of the type.
+var errNotFound = errors.New("no such host")
+
+// Target is an address a host name resolves to.
+type Target struct {
+	IP string
+	// Port is the port given by the SRV record, zero for an A or AAAA record.
+	Port int
+	// Weight is the weight given by the SRV record, zero for an A or AAAA record.
+	Weight int
+}
+
+// Address returns the address of the target, with the given port if the target has none.
+func (t Target) Address(port string) string {
+	if t.Port != 0 {
+		port = fmt.Sprint(t.Port)
+	}
+	if port == "" {
+		if strings.Contains(t.IP, ":") {
+			return "[" + t.IP + "]"
+		}
+		return t.IP
+	}
+	return net.JoinHostPort(t.IP, port)
+}
+
+// Resolver looks up the DNS records of host names.
+type Resolver struct {
+	client      *dns.Client
+	nameservers []string
+	// search and ndots expand the relative host names queried to the given name servers.
+	search []string
+	ndots  int
+	// hostsFile is looked up before the name servers, if set.
+	hostsFile string
+	// resolvConf gives the name servers, if none are given.
+	resolvConf string
+}
+
+// NewResolver creates a Resolver querying the given name servers (host:port),
+// or, if none are given, resolving the host names like the system does,
+// with /etc/hosts and the name servers, search domains and ndots option of /etc/resolv.conf.
+// Without name servers in /etc/resolv.conf, as on Windows, the host names are resolved by the system resolver.
+func NewResolver(nameservers []string) *Resolver {
+	resolver := &Resolver{
+		client:      &dns.Client{Timeout: queryTimeout},
+		nameservers: nameservers,
+		ndots:       1,
+	}
+	if len(nameservers) == 0 {
+		resolver.hostsFile = hostsFile
+		resolver.resolvConf = resolvConf
+	}
+	return resolver
+}
+
+// Lookup returns the targets of the given host name, resolved with the given record type,
+// and the time to live of the records.
+// The targets of SRV records are those with the lowest priority.
+func (r *Resolver) Lookup(ctx context.Context, name, recordType string) ([]Target, time.Duration, error) {
+	recordType = strings.ToUpper(recordType)
+	if recordType != TypeA && recordType != TypeSRV && recordType != "" {
+		return nil, 0, fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	config, err := r.getConfig()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if config == nil {
+		if recordType == TypeSRV {
+			return lookupSystemSRV(ctx, name)
+		}
+		return lookupSystemIPs(ctx, name)
+	}
+
+	if recordType == TypeSRV {
+		return r.lookupSRV(ctx, config, name)
+	}
+	return r.lookupIPs(ctx, config, name)
+}
+
+func (r *Resolver) lookupIPs(ctx context.Context, config *dns.ClientConfig, name string) ([]Target, time.Duration, error) {
+	targets, err := r.lookupHosts(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(targets) > 0 {
+		return targets, hostsTTL, nil
+	}
+
+	var errs []string
+	for _, fqdn := range config.NameList(name) {
+		targets, ttl, err := r.lookupFqdnIPs(ctx, config, fqdn)
+		if err == nil {
+			return targets, ttl, nil
+		}
+
+		errs = append(errs, err.Error())
+		if !errors.Is(err, errNotFound) {
+			break
+		}
+	}
+
+	return nil, 0, fmt.Errorf("failed to resolve %s: %s", name, strings.Join(errs, ", "))
+}
+
+// lookupFqdnIPs looks up the A and AAAA records of the fully qualified name.
+// The failure of the AAAA query is ignored if A records were found.
+func (r *Resolver) lookupFqdnIPs(ctx context.Context, config *dns.ClientConfig, fqdn string) ([]Target, time.Duration, error) {
+	var targets []Target
+	ttl := uint32(math.MaxUint32)
+
+	for _, qType := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		answer, err := r.exchange(ctx, config, fqdn, qType)
+		if err != nil {
+			if qType == dns.TypeAAAA && len(targets) > 0 {
+				break
+			}
+			return nil, 0, err
+		}
+
+		for _, rr := range answer {
+			var ip net.IP
+			switch record := rr.(type) {
+			case *dns.A:
+				ip = record.A
+			case *dns.AAAA:
+				ip = record.AAAA
+			default:
+				continue
+			}
+
+			targets = append(targets, Target{IP: ip.String()})
+			ttl = minTTL(ttl, rr.Header().Ttl)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, 0, fmt.Errorf("no A or AAAA record found for %s: %w", fqdn, errNotFound)
+	}
+
+	sortTargets(targets)
+	return targets, time.Duration(ttl) * time.Second, nil
+}
+
+func (r *Resolver) lookupSRV(ctx context.Context, config *dns.ClientConfig, name string) ([]Target, time.Duration, error) {
+	var errs []string
+	for _, fqdn := range config.NameList(name) {
+		targets, ttl, err := r.lookupFqdnSRV(ctx, config, fqdn)
+		if err == nil {
+			return targets, ttl, nil
+		}
+
+		errs = append(errs, err.Error())
+		if !errors.Is(err, errNotFound) {
+			break
+		}
+	}
+
+	return nil, 0, fmt.Errorf("failed to resolve %s: %s", name, strings.Join(errs, ", "))
+}
+
+func (r *Resolver) lookupFqdnSRV(ctx context.Context, config *dns.ClientConfig, fqdn string) ([]Target, time.Duration, error) {
+	answer, err := r.exchange(ctx, config, fqdn, dns.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []*dns.SRV
+	ttl := uint32(math.MaxUint32)
+	for _, rr := range answer {
+		record, ok := rr.(*dns.SRV)
+		if !ok {
+			continue
+		}
+
+		ttl = minTTL(ttl, record.Hdr.Ttl)
+
+		switch {
+		case len(records) == 0 || record.Priority == records[0].Priority:
+			records = append(records, record)
+		case record.Priority < records[0].Priority:
+			records = []*dns.SRV{record}
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, 0, fmt.Errorf("no SRV record found for %s: %w", fqdn, errNotFound)
+	}
+
+	var targets []Target
+	for _, record := range records {
+		ips, ipTTL, err := r.lookupIPs(ctx, config, record.Target)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		ttl = minTTL(ttl, uint32(ipTTL/time.Second))
+
+		for _, ip := range ips {
+			targets = append(targets, Target{IP: ip.IP, Port: int(record.Port), Weight: int(record.Weight)})
+		}
+	}
+
+	sortTargets(targets)
+	return targets, time.Duration(ttl) * time.Second, nil
+}
+
+// exchange sends the query to the name servers in turn, until one of them answers.
+func (r *Resolver) exchange(ctx context.Context, config *dns.ClientConfig, fqdn string, qType uint16) ([]dns.RR, error) {
+	msg := &dns.Msg{}
+	msg.SetQuestion(fqdn, qType)
+	msg.SetEdns0(4096, false)
+
+	var errs []string
+	for _, server := range config.Servers {
+		nameserver := net.JoinHostPort(server, config.Port)
+		if config.Port == "" {
+			nameserver = server
+		}
+
+		resp, _, err := r.client.ExchangeContext(ctx, msg, nameserver)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", nameserver, err))
+			continue
+		}
+
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+			return resp.Answer, nil
+		case dns.RcodeNameError:
+			return nil, fmt.Errorf("%s: %w", fqdn, errNotFound)
+		default:
+			errs = append(errs, fmt.Sprintf("%s: %s", nameserver, dns.RcodeToString[resp.Rcode]))
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %s", fqdn, strings.Join(errs, ", "))
+}
+
+// lookupSystemIPs looks up the addresses of the host name with the system resolver.
+func lookupSystemIPs(ctx context.Context, name string) ([]Target, time.Duration, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+
+	var targets []Target
+	for _, addr := range addrs {
+		targets = append(targets, Target{IP: addr.IP.String()})
+	}
+
+	sortTargets(targets)
+	return targets, systemTTL, nil
+}
+
+// lookupSystemSRV looks up the SRV records of the name with the system resolver,
+// and the addresses of the targets of those with the lowest priority.
+func lookupSystemSRV(ctx context.Context, name string) ([]Target, time.Duration, error) {
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+
+	var targets []Target
+	for _, record := range records {
+		// The records are sorted by priority.
+		if record.Priority != records[0].Priority {
+			break
+		}
+
+		ips, _, err := lookupSystemIPs(ctx, record.Target)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, ip := range ips {
+			targets = append(targets, Target{IP: ip.IP, Port: int(record.Port), Weight: int(record.Weight)})
+		}
+	}
+
+	sortTargets(targets)
+	return targets, systemTTL, nil
+}
+
+// getConfig returns the name servers to query, and how to expand the relative host names,
+// or nil if the host names must be resolved by the system resolver.
+// The name servers are given with their port, and no port is set in the returned configuration.
+func (r *Resolver) getConfig() (*dns.ClientConfig, error) {
+	if len(r.nameservers) > 0 {
+		return &dns.ClientConfig{Servers: r.nameservers, Search: r.search, Ndots: r.ndots}, nil
+	}
+
+	config, err := dns.ClientConfigFromFile(r.resolvConf)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid resolver configuration file %s: %w", r.resolvConf, err)
+	}
+
+	if len(config.Servers) == 0 {
+		return nil, nil
+	}
+
+	return config, nil
+}
+
+// lookupHosts returns the addresses of the host name in the hosts file.
+func (r *Resolver) lookupHosts(name string) ([]Target, error) {
+	if r.hostsFile == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(r.hostsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	name = strings.TrimSuffix(name, ".")
+
+	var targets []Target
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		// The zone of the link-local IPv6 addresses is not kept.
+		ip := net.ParseIP(strings.SplitN(fields[0], "%", 2)[0])
+		if ip == nil {
+			continue
+		}
+
+		for _, host := range fields[1:] {
+			if strings.EqualFold(strings.TrimSuffix(host, "."), name) {
+				targets = append(targets, Target{IP: ip.String()})
+				break
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", r.hostsFile, err)
+	}
+
+	sortTargets(targets)
+	return targets, nil
+}
+
+func minTTL(ttl, other uint32) uint32 {
+	if other < ttl {
+		return other
+	}
+	return ttl
+}
+
+func sortTargets(targets []Target) {
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].IP != targets[j].IP {
+			return targets[i].IP < targets[j].IP
+		}
+		return targets[i].Port < targets[j].Port
+	})
+}
@@ -0,0 +1,338 @@
+package resolver
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNameserver is a name server answering with the records it is given.
+type testNameserver struct {
+	mu      sync.Mutex
+	records []string
+	// failedType is the type of the queries answered with a server failure.
+	failedType uint16
+}
+
+func (n *testNameserver) setRecords(records ...string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.records = records
+}
+
+func (n *testNameserver) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	resp := &dns.Msg{}
+	resp.SetReply(req)
+
+	question := req.Question[0]
+	if question.Qtype == n.failedType {
+		resp.Rcode = dns.RcodeServerFailure
+		_ = w.WriteMsg(resp)
+		return
+	}
+
+	known := false
+	for _, record := range n.records {
+		rr, err := dns.NewRR(record)
+		if err != nil {
+			panic(err)
+		}
+
+		if rr.Header().Name != question.Name {
+			continue
+		}
+		known = true
+
+		if rr.Header().Rrtype == question.Qtype {
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+
+	if !known {
+		resp.Rcode = dns.RcodeNameError
+	}
+
+	_ = w.WriteMsg(resp)
+}
+
+func startNameserver(t *testing.T, records ...string) (*testNameserver, string) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	nameserver := &testNameserver{records: records}
+	server := &dns.Server{PacketConn: conn, Handler: nameserver}
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	return nameserver, conn.LocalAddr().String()
+}
+
+func TestResolver_Lookup(t *testing.T) {
+	_, addr := startNameserver(t,
+		"foo.example.com. 30 IN A 10.0.0.2",
+		"foo.example.com. 20 IN A 10.0.0.1",
+		"foo.example.com. 60 IN AAAA ::1",
+		"bar.example.com. 60 IN A 10.0.0.3",
+		"_http._tcp.example.com. 10 IN SRV 10 5 8080 foo.example.com.",
+		"_http._tcp.example.com. 10 IN SRV 10 0 8081 bar.example.com.",
+		"_http._tcp.example.com. 10 IN SRV 20 5 8082 baz.example.com.",
+	)
+
+	testCases := []struct {
+		desc            string
+		name            string
+		recordType      string
+		expectedTargets []Target
+		expectedTTL     time.Duration
+		expectedErr     bool
+	}{
+		{
+			desc:       "A and AAAA records",
+			name:       "foo.example.com",
+			recordType: TypeA,
+			expectedTargets: []Target{
+				{IP: "10.0.0.1"},
+				{IP: "10.0.0.2"},
+				{IP: "::1"},
+			},
+			expectedTTL: 20 * time.Second,
+		},
+		{
+			desc:       "lowest priority SRV records",
+			name:       "_http._tcp.example.com",
+			recordType: TypeSRV,
+			expectedTargets: []Target{
+				{IP: "10.0.0.1", Port: 8080, Weight: 5},
+				{IP: "10.0.0.2", Port: 8080, Weight: 5},
+				{IP: "10.0.0.3", Port: 8081},
+				{IP: "::1", Port: 8080, Weight: 5},
+			},
+			expectedTTL: 10 * time.Second,
+		},
+		{
+			desc:        "unknown host",
+			name:        "unknown.example.com",
+			recordType:  TypeA,
+			expectedErr: true,
+		},
+		{
+			desc:        "no records of the type",
+			name:        "foo.example.com",
+			recordType:  TypeSRV,
+			expectedErr: true,
+		},
+		{
+			desc:        "unsupported record type",
+			name:        "foo.example.com",
+			recordType:  "MX",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			targets, ttl, err := NewResolver([]string{addr}).Lookup(context.Background(), test.name, test.recordType)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedTargets, targets)
+			assert.Equal(t, test.expectedTTL, ttl)
+		})
+	}
+}
+
+func TestResolver_Lookup_search(t *testing.T) {
+	_, addr := startNameserver(t,
+		"foo.example.com. 60 IN A 10.0.0.1",
+		"foo.bar.example.com. 60 IN A 10.0.0.2",
+		"_http._tcp.example.com. 10 IN SRV 10 5 8080 foo.example.com.",
+	)
+
+	resolver := NewResolver([]string{addr})
+	resolver.search = []string{"example.com"}
+
+	targets, _, err := resolver.Lookup(context.Background(), "foo", TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{IP: "10.0.0.1"}}, targets)
+
+	// The name with at least ndots dots is tried as is first.
+	targets, _, err = resolver.Lookup(context.Background(), "foo.bar", TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{IP: "10.0.0.2"}}, targets)
+
+	targets, _, err = resolver.Lookup(context.Background(), "_http._tcp", TypeSRV)
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{IP: "10.0.0.1", Port: 8080, Weight: 5}}, targets)
+
+	_, _, err = resolver.Lookup(context.Background(), "foo.example.com.", TypeA)
+	require.NoError(t, err)
+
+	_, _, err = resolver.Lookup(context.Background(), "unknown", TypeA)
+	assert.Error(t, err)
+}
+
+func TestResolver_Lookup_failedAAAA(t *testing.T) {
+	nameserver, addr := startNameserver(t, "foo.example.com. 60 IN A 10.0.0.1")
+	nameserver.mu.Lock()
+	nameserver.failedType = dns.TypeAAAA
+	nameserver.mu.Unlock()
+
+	targets, _, err := NewResolver([]string{addr}).Lookup(context.Background(), "foo.example.com", TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{IP: "10.0.0.1"}}, targets)
+
+	// Without A records, the failure of the AAAA query is reported.
+	nameserver.setRecords("foo.example.com. 60 IN AAAA ::1")
+
+	_, _, err = NewResolver([]string{addr}).Lookup(context.Background(), "foo.example.com", TypeA)
+	assert.Error(t, err)
+}
+
+func TestResolver_Lookup_hosts(t *testing.T) {
+	_, addr := startNameserver(t, "foo.example.com. 60 IN A 10.0.0.1")
+
+	hosts := filepath.Join(t.TempDir(), "hosts")
+	err := ioutil.WriteFile(hosts, []byte("# comment\n10.0.0.2 bar bar.example.com # bar\n::2 bar.example.com\n"), 0600)
+	require.NoError(t, err)
+
+	resolver := NewResolver([]string{addr})
+	resolver.hostsFile = hosts
+
+	targets, ttl, err := resolver.Lookup(context.Background(), "bar.example.com", TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{IP: "10.0.0.2"}, {IP: "::2"}}, targets)
+	assert.Equal(t, hostsTTL, ttl)
+
+	targets, _, err = resolver.Lookup(context.Background(), "foo.example.com", TypeA)
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{IP: "10.0.0.1"}}, targets)
+}
+
+func TestResolver_Lookup_system(t *testing.T) {
+	resolver := NewResolver(nil)
+	// Without name servers, the host names are resolved by the system resolver.
+	resolver.resolvConf = filepath.Join(t.TempDir(), "resolv.conf")
+
+	targets, ttl, err := resolver.Lookup(context.Background(), "localhost", TypeA)
+	require.NoError(t, err)
+	assert.Contains(t, targets, Target{IP: "127.0.0.1"})
+	assert.Equal(t, systemTTL, ttl)
+}
+
+func TestTarget_Address(t *testing.T) {
+	assert.Equal(t, "10.0.0.1:80", Target{IP: "10.0.0.1"}.Address("80"))
+	assert.Equal(t, "10.0.0.1:8080", Target{IP: "10.0.0.1", Port: 8080}.Address("80"))
+	assert.Equal(t, "[::1]:80", Target{IP: "::1"}.Address("80"))
+	assert.Equal(t, "[::1]", Target{IP: "::1"}.Address(""))
+}
+
+func TestWatchers(t *testing.T) {
+	nameserver, addr := startNameserver(t, "foo.example.com. 60 IN A 10.0.0.1")
+
+	watchers := NewWatchers(NewResolver([]string{addr}))
+
+	updates := make(chan []Target, 10)
+	err := watchers.Watch(context.Background(), "foo.example.com", TypeA, 10*time.Millisecond, func(targets []Target) {
+		updates <- targets
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Target{{IP: "10.0.0.1"}}, <-updates)
+
+	nameserver.setRecords("foo.example.com. 60 IN A 10.0.0.1", "foo.example.com. 60 IN A 10.0.0.2")
+
+	select {
+	case targets := <-updates:
+		assert.Equal(t, []Target{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}}, targets)
+	case <-time.After(time.Second):
+		t.Fatal("the change of the records was not notified")
+	}
+
+	watchers.NewGeneration()
+	// Lets a refresh in progress complete.
+	time.Sleep(50 * time.Millisecond)
+	for len(updates) > 0 {
+		<-updates
+	}
+
+	nameserver.setRecords("foo.example.com. 60 IN A 10.0.0.3")
+
+	select {
+	case targets := <-updates:
+		t.Fatalf("unexpected update after the next generation: %v", targets)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatchers_firstResolutionFailure(t *testing.T) {
+	nameserver, addr := startNameserver(t)
+
+	watchers := NewWatchers(NewResolver([]string{addr}))
+
+	updates := make(chan []Target, 10)
+	err := watchers.Watch(context.Background(), "foo.example.com", TypeA, 10*time.Millisecond, func(targets []Target) {
+		updates <- targets
+	})
+	require.Error(t, err)
+
+	nameserver.setRecords("foo.example.com. 60 IN A 10.0.0.1")
+
+	select {
+	case targets := <-updates:
+		assert.Equal(t, []Target{{IP: "10.0.0.1"}}, targets)
+	case <-time.After(time.Second):
+		t.Fatal("the host name was not resolved again")
+	}
+
+	watchers.NewGeneration()
+}
+
+func TestWatchers_firstResolutionTimeout(t *testing.T) {
+	nameserver, addr := startNameserver(t, "foo.example.com. 60 IN A 10.0.0.1")
+
+	// The name server answers only once the deadline of the first resolutions is over.
+	nameserver.mu.Lock()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		nameserver.mu.Unlock()
+	}()
+
+	watchers := NewWatchers(NewResolver([]string{addr}))
+	watchers.deadline = time.Now().Add(50 * time.Millisecond)
+	defer watchers.NewGeneration()
+
+	updates := make(chan []Target, 10)
+	start := time.Now()
+	err := watchers.Watch(context.Background(), "foo.example.com", TypeA, time.Minute, func(targets []Target) {
+		updates <- targets
+	})
+	require.NoError(t, err)
+	assert.Less(t, int64(time.Since(start)), int64(200*time.Millisecond))
+
+	select {
+	case targets := <-updates:
+		assert.Equal(t, []Target{{IP: "10.0.0.1"}}, targets)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the host name was not resolved in the background")
+	}
+}
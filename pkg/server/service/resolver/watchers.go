@@ -0,0 +1,146 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+	"github.com/containous/traefik/v2/pkg/safe"
+)
+
+const (
+	// minRefreshInterval bounds the refreshes driven by the TTL of the records, which can be zero.
+	minRefreshInterval = time.Second
+	// retryInterval is the delay before resolving again a host name whose resolution failed,
+	// when no refresh interval is configured.
+	retryInterval = 5 * time.Second
+	// firstLookupTimeout bounds the time spent by all the first resolutions of a generation,
+	// which delay the building of the configuration.
+	firstLookupTimeout = 2 * time.Second
+)
+
+// Watchers keeps the targets of the host names of the servers up to date.
+// The host names of a configuration are watched until the next configuration.
+type Watchers struct {
+	resolver *Resolver
+
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	// deadline is the deadline of the first resolutions of the generation.
+	deadline time.Time
+}
+
+// NewWatchers creates a new Watchers, resolving the host names with the given resolver.
+func NewWatchers(resolver *Resolver) *Watchers {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Watchers{
+		resolver: resolver,
+		ctx:      ctx,
+		cancel:   cancel,
+		deadline: time.Now().Add(firstLookupTimeout),
+	}
+}
+
+// NewGeneration stops watching the host names of the previous configuration.
+func (w *Watchers) NewGeneration() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.cancel()
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	w.deadline = time.Now().Add(firstLookupTimeout)
+}
+
+// Watch resolves the given host name, and calls onChange with its targets.
+// It then resolves it again every refreshInterval, or when its records expire if refreshInterval is zero,
+// and calls onChange with the new targets whenever they change, until the next generation.
+// The first resolutions of a generation share a deadline, past which they go on in the background,
+// so that a slow name server does not hold up the building of the configuration.
+// The host name keeps being watched even if the first resolution fails.
+func (w *Watchers) Watch(ctx context.Context, name, recordType string, refreshInterval time.Duration, onChange func([]Target)) error {
+	if w == nil {
+		return fmt.Errorf("cannot resolve %q: no DNS resolution configured", name)
+	}
+
+	switch strings.ToUpper(recordType) {
+	case TypeA, TypeSRV, "":
+	default:
+		return fmt.Errorf("unsupported record type %q", recordType)
+	}
+
+	w.mu.Lock()
+	genCtx, deadline := w.ctx, w.deadline
+	w.mu.Unlock()
+
+	firstCtx, cancel := context.WithDeadline(ctx, deadline)
+	targets, ttl, err := w.resolver.Lookup(firstCtx, name, recordType)
+	// The name server client can time out on the deadline before the context does.
+	timedOut := err != nil && !time.Now().Before(deadline)
+	cancel()
+
+	if err == nil {
+		onChange(targets)
+	}
+
+	logger := log.FromContext(ctx)
+	safe.Go(func() {
+		current, ttl, lookupErr, retryNow := targets, ttl, err, timedOut
+		for {
+			delay := nextRefresh(refreshInterval, ttl, lookupErr)
+			if retryNow {
+				// The first resolution was cut short, and is done again right away.
+				delay, retryNow = 0, false
+			}
+			timer := time.NewTimer(delay)
+
+			select {
+			case <-genCtx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			var newTargets []Target
+			newTargets, ttl, lookupErr = w.resolver.Lookup(genCtx, name, recordType)
+			if lookupErr != nil {
+				if genCtx.Err() == nil {
+					logger.Errorf("Failed to resolve %s, keeping its previous addresses: %v", name, lookupErr)
+				}
+				continue
+			}
+
+			if reflect.DeepEqual(newTargets, current) {
+				continue
+			}
+
+			logger.Debugf("Addresses of %s changed: %v", name, newTargets)
+			current = newTargets
+			onChange(newTargets)
+		}
+	})
+
+	if timedOut {
+		logger.Warnf("Resolution of %s is taking too long, its servers are added once resolved", name)
+		return nil
+	}
+	return err
+}
+
+func nextRefresh(refreshInterval, ttl time.Duration, err error) time.Duration {
+	switch {
+	case refreshInterval > 0:
+		return refreshInterval
+	case err != nil:
+		return retryInterval
+	case ttl < minRefreshInterval:
+		return minRefreshInterval
+	default:
+		return ttl
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
+	"reflect"
+	"sync"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/static"
 	"github.com/containous/traefik/v2/pkg/log"
 	traefiktls "github.com/containous/traefik/v2/pkg/tls"
@@ -23,13 +27,108 @@ func (t *h2cTransportWrapper) RoundTrip(req *http.Request) (*http.Response, erro
 	return t.Transport.RoundTrip(req)
 }
 
-// createRoundtripper creates an http.Roundtripper configured with the Transport configuration settings.
+// RoundTripperManager handles the round trippers used to reach the servers:
+// the default one, built from the static configuration,
+// and the ones built from the ServersTransports of the dynamic configuration.
+type RoundTripperManager struct {
+	defaultRoundTripper http.RoundTripper
+
+	rtLock        sync.RWMutex
+	roundTrippers map[string]http.RoundTripper
+	configs       map[string]*dynamic.ServersTransport
+}
+
+// NewRoundTripperManager creates a new RoundTripperManager.
+func NewRoundTripperManager(defaultRoundTripper http.RoundTripper) *RoundTripperManager {
+	return &RoundTripperManager{
+		defaultRoundTripper: defaultRoundTripper,
+		roundTrippers:       make(map[string]http.RoundTripper),
+		configs:             make(map[string]*dynamic.ServersTransport),
+	}
+}
+
+// Update updates the round trippers with the given ServersTransports, keyed by qualified name.
+// The round trippers whose configuration did not change are kept,
+// so that their idle connections survive a configuration reload.
+func (r *RoundTripperManager) Update(newConfigs map[string]*dynamic.ServersTransport) {
+	r.rtLock.Lock()
+	defer r.rtLock.Unlock()
+
+	for configName, config := range r.configs {
+		newConfig, ok := newConfigs[configName]
+		if !ok {
+			r.closeIdleConnections(r.roundTrippers[configName])
+			delete(r.configs, configName)
+			delete(r.roundTrippers, configName)
+			continue
+		}
+
+		if reflect.DeepEqual(newConfig, config) {
+			continue
+		}
+
+		roundTripper, err := createRoundTripper(newConfig)
+		if err != nil {
+			log.WithoutContext().Errorf("Could not configure HTTP Transport %s, fallbacking on default transport: %v", configName, err)
+			roundTripper = r.defaultRoundTripper
+		}
+		r.closeIdleConnections(r.roundTrippers[configName])
+		r.configs[configName] = newConfig
+		r.roundTrippers[configName] = roundTripper
+	}
+
+	for newConfigName, newConfig := range newConfigs {
+		if _, ok := r.configs[newConfigName]; ok {
+			continue
+		}
+
+		roundTripper, err := createRoundTripper(newConfig)
+		if err != nil {
+			log.WithoutContext().Errorf("Could not configure HTTP Transport %s, fallbacking on default transport: %v", newConfigName, err)
+			roundTripper = r.defaultRoundTripper
+		}
+		r.configs[newConfigName] = newConfig
+		r.roundTrippers[newConfigName] = roundTripper
+	}
+}
+
+// closeIdleConnections closes the idle connections of a round tripper no longer used,
+// which would otherwise be kept open until their idle timeout.
+// The default round tripper, used as a fallback, is left untouched.
+func (r *RoundTripperManager) closeIdleConnections(roundTripper http.RoundTripper) {
+	if roundTripper == r.defaultRoundTripper {
+		return
+	}
+
+	if closer, ok := roundTripper.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// Get gets a round tripper by its qualified name.
+// An empty name gives the default round tripper.
+func (r *RoundTripperManager) Get(name string) (http.RoundTripper, error) {
+	if len(name) == 0 {
+		return r.defaultRoundTripper, nil
+	}
+
+	r.rtLock.RLock()
+	defer r.rtLock.RUnlock()
+
+	if rt, ok := r.roundTrippers[name]; ok {
+		return rt, nil
+	}
+
+	return nil, fmt.Errorf("servers transport not found %s", name)
+}
+
+// createRoundTripper creates an http.RoundTripper configured with the Transport configuration settings.
 // For the settings that can't be configured in Traefik it uses the default http.Transport settings.
 // An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost
 // in Traefik at this point in time. Setting this value to the default of 100 could lead to confusing
 // behavior and backwards compatibility issues.
-func createRoundtripper(transportConfiguration *static.ServersTransport) (http.RoundTripper, error) {
-	if transportConfiguration == nil {
+func createRoundTripper(cfg *dynamic.ServersTransport) (http.RoundTripper, error) {
+	if cfg == nil {
 		return nil, errors.New("no transport configuration given")
 	}
 
@@ -39,14 +138,14 @@ func createRoundtripper(transportConfiguration *static.ServersTransport) (http.R
 		DualStack: true,
 	}
 
-	if transportConfiguration.ForwardingTimeouts != nil {
-		dialer.Timeout = time.Duration(transportConfiguration.ForwardingTimeouts.DialTimeout)
+	if cfg.ForwardingTimeouts != nil {
+		dialer.Timeout = time.Duration(cfg.ForwardingTimeouts.DialTimeout)
 	}
 
 	transport := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
 		DialContext:           dialer.DialContext,
-		MaxIdleConnsPerHost:   transportConfiguration.MaxIdleConnsPerHost,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
@@ -61,24 +160,30 @@ func createRoundtripper(transportConfiguration *static.ServersTransport) (http.R
 		},
 	})
 
-	if transportConfiguration.ForwardingTimeouts != nil {
-		transport.ResponseHeaderTimeout = time.Duration(transportConfiguration.ForwardingTimeouts.ResponseHeaderTimeout)
-		transport.IdleConnTimeout = time.Duration(transportConfiguration.ForwardingTimeouts.IdleConnTimeout)
+	if cfg.ForwardingTimeouts != nil {
+		transport.ResponseHeaderTimeout = time.Duration(cfg.ForwardingTimeouts.ResponseHeaderTimeout)
+		transport.IdleConnTimeout = time.Duration(cfg.ForwardingTimeouts.IdleConnTimeout)
 	}
 
-	if transportConfiguration.InsecureSkipVerify || len(transportConfiguration.RootCAs) > 0 {
+	if cfg.InsecureSkipVerify || len(cfg.RootCAs) > 0 || len(cfg.ServerName) > 0 || len(cfg.Certificates) > 0 {
+		certificates, err := cfg.Certificates.GetCertificates()
+		if err != nil {
+			return nil, err
+		}
+
 		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: transportConfiguration.InsecureSkipVerify,
-			RootCAs:            createRootCACertPool(transportConfiguration.RootCAs),
+			ServerName:         cfg.ServerName,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+			RootCAs:            createRootCACertPool(cfg.RootCAs),
+			Certificates:       certificates,
 		}
 	}
 
-	smartTransport, err := newSmartRoundTripper(transport)
-	if err != nil {
-		return nil, err
+	if cfg.DisableHTTP2 {
+		return transport, nil
 	}
 
-	return smartTransport, nil
+	return newSmartRoundTripper(transport)
 }
 
 func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
@@ -101,7 +206,17 @@ func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
 }
 
 func setupDefaultRoundTripper(conf *static.ServersTransport) http.RoundTripper {
-	transport, err := createRoundtripper(conf)
+	if conf == nil {
+		log.WithoutContext().Error("Could not configure HTTP Transport, fallbacking on default transport: no transport configuration given")
+		return http.DefaultTransport
+	}
+
+	transport, err := createRoundTripper(&dynamic.ServersTransport{
+		InsecureSkipVerify:  conf.InsecureSkipVerify,
+		RootCAs:             conf.RootCAs,
+		MaxIdleConnsPerHost: conf.MaxIdleConnsPerHost,
+		ForwardingTimeouts:  convertForwardingTimeouts(conf.ForwardingTimeouts),
+	})
 	if err != nil {
 		log.WithoutContext().Errorf("Could not configure HTTP Transport, fallbacking on default transport: %v", err)
 		return http.DefaultTransport
@@ -109,3 +224,15 @@ func setupDefaultRoundTripper(conf *static.ServersTransport) http.RoundTripper {
 
 	return transport
 }
+
+func convertForwardingTimeouts(timeouts *static.ForwardingTimeouts) *dynamic.ForwardingTimeouts {
+	if timeouts == nil {
+		return nil
+	}
+
+	return &dynamic.ForwardingTimeouts{
+		DialTimeout:           timeouts.DialTimeout,
+		ResponseHeaderTimeout: timeouts.ResponseHeaderTimeout,
+		IdleConnTimeout:       timeouts.IdleConnTimeout,
+	}
+}
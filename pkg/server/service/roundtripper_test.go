@@ -0,0 +1,207 @@
+package service
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	traefiktls "github.com/containous/traefik/v2/pkg/tls"
+	"github.com/containous/traefik/v2/pkg/tls/generate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTripperManager_Update(t *testing.T) {
+	rtManager := NewRoundTripperManager(http.DefaultTransport)
+
+	rtManager.Update(map[string]*dynamic.ServersTransport{
+		"test@file": {MaxIdleConnsPerHost: 42},
+	})
+
+	roundTripper, err := rtManager.Get("test@file")
+	require.NoError(t, err)
+	assert.NotSame(t, http.DefaultTransport, roundTripper)
+
+	// An unchanged configuration keeps the round tripper, and therefore its idle connections.
+	rtManager.Update(map[string]*dynamic.ServersTransport{
+		"test@file": {MaxIdleConnsPerHost: 42},
+	})
+
+	unchanged, err := rtManager.Get("test@file")
+	require.NoError(t, err)
+	assert.Same(t, roundTripper, unchanged)
+
+	rtManager.Update(map[string]*dynamic.ServersTransport{
+		"test@file": {MaxIdleConnsPerHost: 10},
+	})
+
+	changed, err := rtManager.Get("test@file")
+	require.NoError(t, err)
+	assert.NotSame(t, roundTripper, changed)
+
+	rtManager.Update(nil)
+
+	_, err = rtManager.Get("test@file")
+	assert.Error(t, err)
+
+	defaultRoundTripper, err := rtManager.Get("")
+	require.NoError(t, err)
+	assert.Same(t, http.DefaultTransport, defaultRoundTripper)
+}
+
+func TestRoundTripperManager_UpdateClosesIdleConnections(t *testing.T) {
+	closed := make(chan struct{}, 10)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed <- struct{}{}
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	rtManager := NewRoundTripperManager(http.DefaultTransport)
+
+	for _, configs := range []map[string]*dynamic.ServersTransport{
+		// The changed configuration replaces the round tripper.
+		{"test@file": {MaxIdleConnsPerHost: 10}},
+		{"test@file": {MaxIdleConnsPerHost: 20}},
+		// The removed configuration drops it.
+		nil,
+	} {
+		roundTripper, err := rtManager.Get("test@file")
+		if err == nil {
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := roundTripper.RoundTrip(req)
+			require.NoError(t, err)
+			_, _ = ioutil.ReadAll(resp.Body)
+			require.NoError(t, resp.Body.Close())
+		}
+
+		rtManager.Update(configs)
+
+		if err == nil {
+			select {
+			case <-closed:
+			case <-time.After(time.Second):
+				t.Fatal("the idle connection of the replaced round tripper was not closed")
+			}
+		}
+	}
+}
+
+func TestMTLS(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	rootCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	certPEM, keyPEM, err := generate.KeyPair("client.example.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc         string
+		certificates traefiktls.Certificates
+		expectError  bool
+	}{
+		{
+			desc: "with a client certificate",
+			certificates: traefiktls.Certificates{
+				{CertFile: traefiktls.FileOrContent(certPEM), KeyFile: traefiktls.FileOrContent(keyPEM)},
+			},
+		},
+		{
+			desc:        "without client certificate",
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			rtManager := NewRoundTripperManager(http.DefaultTransport)
+			rtManager.Update(map[string]*dynamic.ServersTransport{
+				"test": {
+					ServerName:   "example.com",
+					RootCAs:      []traefiktls.FileOrContent{traefiktls.FileOrContent(rootCA)},
+					Certificates: test.certificates,
+				},
+			})
+
+			roundTripper, err := rtManager.Get("test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, srv.URL, nil)
+			resp, err := roundTripper.RoundTrip(req)
+			if test.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestDisableHTTP2(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		disableHTTP2  bool
+		expectedProto string
+	}{
+		{
+			desc:          "HTTP/2 enabled",
+			expectedProto: "HTTP/2.0",
+		},
+		{
+			desc:          "HTTP/2 disabled",
+			disableHTTP2:  true,
+			expectedProto: "HTTP/1.1",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}))
+			srv.EnableHTTP2 = true
+			srv.StartTLS()
+			defer srv.Close()
+
+			rtManager := NewRoundTripperManager(http.DefaultTransport)
+			rtManager.Update(map[string]*dynamic.ServersTransport{
+				"test": {
+					InsecureSkipVerify: true,
+					DisableHTTP2:       test.disableHTTP2,
+				},
+			})
+
+			roundTripper, err := rtManager.Get("test")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, srv.URL, nil)
+			resp, err := roundTripper.RoundTrip(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedProto, resp.Proto)
+		})
+	}
+}
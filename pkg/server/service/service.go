@@ -8,6 +8,8 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/containous/alice"
@@ -23,8 +25,17 @@ import (
 	"github.com/containous/traefik/v2/pkg/safe"
 	"github.com/containous/traefik/v2/pkg/server/cookie"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/failover"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/hashring"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/hedging"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/leastrequests"
 	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/mirror"
+	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/slowstart"
 	"github.com/containous/traefik/v2/pkg/server/service/loadbalancer/wrr"
+	"github.com/containous/traefik/v2/pkg/server/service/resolver"
+	"github.com/containous/traefik/v2/pkg/types"
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/vulcand/oxy/roundrobin"
 )
 
@@ -35,15 +46,31 @@ const (
 
 const defaultMaxBodySize int64 = -1
 
+// defaultCompareMaxBodySize is the maximum size of the response bodies buffered by a mirroring to compare them.
+const defaultCompareMaxBodySize int64 = 1 << 20
+
+const (
+	serverUp   = "UP"
+	serverDown = "DOWN"
+)
+
+const (
+	wrrStrategy           = "wrr"
+	leastRequestsStrategy = "leastrequests"
+	p2cStrategy           = "p2c"
+	hashStrategy          = "consistenthash"
+)
+
 // NewManager creates a new Manager.
-func NewManager(configs map[string]*runtime.ServiceInfo, defaultRoundTripper http.RoundTripper, metricsRegistry metrics.Registry, routinePool *safe.Pool) *Manager {
+func NewManager(configs map[string]*runtime.ServiceInfo, roundTripperManager *RoundTripperManager, metricsRegistry metrics.Registry, routinePool *safe.Pool) *Manager {
 	return &Manager{
 		routinePool:         routinePool,
 		metricsRegistry:     metricsRegistry,
 		bufferPool:          newBufferPool(),
-		defaultRoundTripper: defaultRoundTripper,
+		roundTripperManager: roundTripperManager,
 		balancers:           make(map[string]healthcheck.Balancers),
 		configs:             configs,
+		healthChecks:        make(map[string][]healthcheck.ServerForgetter),
 	}
 }
 
@@ -52,13 +79,32 @@ type Manager struct {
 	routinePool         *safe.Pool
 	metricsRegistry     metrics.Registry
 	bufferPool          httputil.BufferPool
-	defaultRoundTripper http.RoundTripper
+	roundTripperManager *RoundTripperManager
 	// balancers is the map of all Balancers, keyed by service name.
 	// There is one Balancer per service handler, and there is one service handler per reference to a service
 	// (e.g. if 2 routers refer to the same service name, 2 service handlers are created),
 	// which is why there is not just one Balancer per service name.
 	balancers map[string]healthcheck.Balancers
 	configs   map[string]*runtime.ServiceInfo
+	// slowStartTracker keeps track of the servers already warmed up, across configuration reloads.
+	slowStartTracker *slowstart.Tracker
+	// dnsWatchers keeps the servers resolved from a host name up to date, until the next configuration.
+	// The servers are not resolved without it.
+	dnsWatchers *resolver.Watchers
+
+	// healthChecks are the health checks of each service, which forget about the servers no longer resolved.
+	healthChecksMu sync.Mutex
+	healthChecks   map[string][]healthcheck.ServerForgetter
+}
+
+// SetSlowStartTracker sets the slow start tracker shared with the managers of the previous configurations.
+func (m *Manager) SetSlowStartTracker(tracker *slowstart.Tracker) {
+	m.slowStartTracker = tracker
+}
+
+// SetDNSWatchers sets the watchers of the server host names, shared with the managers of the previous configurations.
+func (m *Manager) SetDNSWatchers(watchers *resolver.Watchers) {
+	m.dnsWatchers = watchers
 }
 
 // BuildHTTP Creates a http.Handler for a service configuration.
@@ -105,7 +151,14 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string, respons
 		}
 	case conf.Mirroring != nil:
 		var err error
-		lb, err = m.getMirrorServiceHandler(ctx, conf.Mirroring, responseModifier)
+		lb, err = m.getMirrorServiceHandler(ctx, serviceName, conf.Mirroring, responseModifier)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
+	case conf.Failover != nil:
+		var err error
+		lb, err = m.getFailoverServiceHandler(ctx, serviceName, conf.Failover, responseModifier)
 		if err != nil {
 			conf.AddError(err, true)
 			return nil, err
@@ -119,28 +172,89 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string, respons
 	return lb, nil
 }
 
-func (m *Manager) getMirrorServiceHandler(ctx context.Context, config *dynamic.Mirroring, responseModifier func(*http.Response) error) (http.Handler, error) {
+func (m *Manager) getMirrorServiceHandler(ctx context.Context, serviceName string, config *dynamic.Mirroring, responseModifier func(*http.Response) error) (http.Handler, error) {
 	serviceHandler, err := m.BuildHTTP(ctx, config.Service, responseModifier)
 	if err != nil {
 		return nil, err
 	}
 
+	m.watchChildStatus(ctx, serviceName, config.Service, serviceHandler, nil)
+
 	maxBodySize := defaultMaxBodySize
 	if config.MaxBodySize != nil {
 		maxBodySize = *config.MaxBodySize
 	}
 	handler := mirror.New(serviceHandler, m.routinePool, maxBodySize)
+
+	if config.Compare != nil {
+		opts := mirror.CompareOptions{
+			Headers:     config.Compare.Headers,
+			Body:        strings.ToLower(config.Compare.Body),
+			IgnorePaths: config.Compare.IgnorePaths,
+			MaxBodySize: defaultCompareMaxBodySize,
+		}
+		if config.Compare.MaxBodySize != nil {
+			opts.MaxBodySize = *config.Compare.MaxBodySize
+		}
+		if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
+			opts.Mismatches = m.metricsRegistry.ServiceMirrorMismatchesCounter()
+		}
+
+		if err := handler.EnableComparison(serviceName, opts); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, mirrorConfig := range config.Mirrors {
 		mirrorHandler, err := m.BuildHTTP(ctx, mirrorConfig.Name, responseModifier)
 		if err != nil {
 			return nil, err
 		}
 
-		err = handler.AddMirror(mirrorHandler, mirrorConfig.Percent)
+		err = handler.AddNamedMirror(mirrorConfig.Name, mirrorHandler, mirrorConfig.Percent)
+		if err != nil {
+			return nil, err
+		}
+
+		m.watchChildStatus(ctx, serviceName, mirrorConfig.Name, mirrorHandler, nil)
+	}
+	return handler, nil
+}
+
+func (m *Manager) getFailoverServiceHandler(ctx context.Context, serviceName string, config *dynamic.Failover, responseModifier func(*http.Response) error) (http.Handler, error) {
+	serviceHandler, err := m.BuildHTTP(ctx, config.Service, responseModifier)
+	if err != nil {
+		return nil, err
+	}
+
+	fallbackHandler, err := m.BuildHTTP(ctx, config.Fallback, responseModifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses types.HTTPCodeRanges
+	maxBodySize := defaultMaxBodySize
+	if config.Errors != nil {
+		statuses, err = types.NewHTTPCodeRanges(config.Errors.Status)
 		if err != nil {
 			return nil, err
 		}
+
+		if config.Errors.MaxBodySize != nil {
+			maxBodySize = *config.Errors.MaxBodySize
+		}
 	}
+
+	handler := failover.New(serviceHandler, fallbackHandler, statuses, maxBodySize)
+
+	m.watchChildStatus(ctx, serviceName, config.Service, serviceHandler, func(up bool) {
+		handler.SetHandlerStatus(ctx, up)
+	})
+
+	m.watchChildStatus(ctx, serviceName, config.Fallback, fallbackHandler, func(up bool) {
+		handler.SetFallbackHandlerStatus(ctx, up)
+	})
+
 	return handler, nil
 }
 
@@ -158,10 +272,40 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 		}
 
 		balancer.AddService(service.Name, serviceHandler, service.Weight)
+
+		childName := service.Name
+		m.watchChildStatus(ctx, serviceName, childName, serviceHandler, func(up bool) {
+			balancer.SetStatus(ctx, childName, up)
+		})
 	}
 	return balancer, nil
 }
 
+// watchChildStatus keeps the status of the given child service up to date in the server statuses of its parent service,
+// and calls onChange (if not nil) whenever it changes, provided that the child service is able to report its status.
+func (m *Manager) watchChildStatus(ctx context.Context, serviceName, childName string, child http.Handler, onChange func(up bool)) {
+	updater, ok := child.(healthcheck.StatusUpdater)
+	if !ok {
+		return
+	}
+
+	serviceInfo := m.configs[serviceName]
+	childName = provider.GetQualifiedName(ctx, childName)
+	serviceInfo.UpdateServerStatus(childName, serverUp)
+
+	updater.RegisterStatusUpdater(func(up bool) {
+		status := serverUp
+		if !up {
+			status = serverDown
+		}
+		serviceInfo.UpdateServerStatus(childName, status)
+
+		if onChange != nil {
+			onChange(up)
+		}
+	})
+}
+
 func (m *Manager) getLoadBalancerServiceHandler(
 	ctx context.Context,
 	serviceName string,
@@ -173,7 +317,16 @@ func (m *Manager) getLoadBalancerServiceHandler(
 		service.PassHostHeader = &defaultPassHostHeader
 	}
 
-	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, m.defaultRoundTripper, m.bufferPool, responseModifier)
+	if len(service.ServersTransport) > 0 {
+		service.ServersTransport = provider.GetQualifiedName(ctx, service.ServersTransport)
+	}
+
+	roundTripper, err := m.getServersRoundTripper(service)
+	if err != nil {
+		return nil, err
+	}
+
+	fwd, err := buildProxy(service.PassHostHeader, service.ResponseForwarding, roundTripper, m.bufferPool, responseModifier)
 	if err != nil {
 		return nil, err
 	}
@@ -191,16 +344,49 @@ func (m *Manager) getLoadBalancerServiceHandler(
 		return nil, err
 	}
 
+	var passiveHealthCheck *healthcheck.PassiveHealthCheck
+	if service.PassiveHealthCheck != nil {
+		passiveHealthCheck = m.buildPassiveHealthCheck(ctx, serviceName, service.PassiveHealthCheck, handler)
+		handler = passiveHealthCheck
+		m.addHealthCheck(serviceName, passiveHealthCheck)
+	}
+
+	// The retried requests avoid the servers which failed, unless they are bound to a server by a sticky session,
+	// and the hedged requests avoid the servers to which the request was already sent.
+	var stickyCookie string
+	if service.Sticky != nil && service.Sticky.Cookie != nil {
+		stickyCookie = cookie.GetName(service.Sticky.Cookie.Name, serviceName)
+	}
+	failedServersAvoider := loadbalancer.NewFailedServersAvoider(handler, stickyCookie)
+	handler = failedServersAvoider
+
 	balancer, err := m.getLoadBalancer(ctx, serviceName, service, handler)
 	if err != nil {
 		return nil, err
 	}
 
+	if passiveHealthCheck != nil {
+		passiveHealthCheck.SetBalancer(balancer)
+	}
+
+	failedServersAvoider.SetBalancer(balancer)
+
 	// TODO rename and checks
 	m.balancers[serviceName] = append(m.balancers[serviceName], balancer)
 
 	// Empty (backend with no servers)
-	return emptybackendhandler.New(balancer), nil
+	lbHandler := emptybackendhandler.New(balancer)
+
+	if service.Hedging != nil {
+		var hedges gokitmetrics.Counter
+		if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
+			hedges = m.metricsRegistry.ServiceHedgesCounter()
+		}
+
+		return hedging.New(lbHandler, serviceName, *service.Hedging, hedges)
+	}
+
+	return lbHandler, nil
 }
 
 // LaunchHealthCheck Launches the health checks.
@@ -218,12 +404,20 @@ func (m *Manager) LaunchHealthCheck() {
 		if hcOpts := buildHealthCheckOptions(ctx, balancers, serviceName, service.HealthCheck); hcOpts != nil {
 			log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, *hcOpts)
 
-			hcOpts.Transport = m.defaultRoundTripper
+			// The servers are checked over the same kind of connections as the forwarded requests.
+			roundTripper, err := m.getServersRoundTripper(service)
+			if err != nil {
+				log.FromContext(ctx).Errorf("Cannot set up healthcheck for service %s: %v", serviceName, err)
+				continue
+			}
+
+			hcOpts.Transport = roundTripper
 			backendHealthCheck = healthcheck.NewBackendConfig(*hcOpts, serviceName)
 		}
 
 		if backendHealthCheck != nil {
 			backendConfigs[serviceName] = backendHealthCheck
+			m.addHealthCheck(serviceName, backendHealthCheck)
 		}
 	}
 
@@ -231,13 +425,48 @@ func (m *Manager) LaunchHealthCheck() {
 	healthcheck.GetHealthCheck().SetBackendsConfiguration(context.Background(), backendConfigs)
 }
 
+// addHealthCheck records a health check of the servers of the given service.
+func (m *Manager) addHealthCheck(serviceName string, healthCheck healthcheck.ServerForgetter) {
+	m.healthChecksMu.Lock()
+	defer m.healthChecksMu.Unlock()
+
+	m.healthChecks[serviceName] = append(m.healthChecks[serviceName], healthCheck)
+}
+
+// forgetServer makes the health checks of the given service forget about the given server,
+// so that they do not put it back in the load-balancer.
+func (m *Manager) forgetServer(serviceName string, u *url.URL) {
+	m.healthChecksMu.Lock()
+	defer m.healthChecksMu.Unlock()
+
+	for _, healthCheck := range m.healthChecks[serviceName] {
+		healthCheck.ForgetServer(u)
+	}
+}
+
 func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backend string, hc *dynamic.HealthCheck) *healthcheck.Options {
-	if hc == nil || hc.Path == "" {
+	if hc == nil {
 		return nil
 	}
 
 	logger := log.FromContext(ctx)
 
+	mode := healthcheck.ModeHTTP
+	if hc.Mode != "" {
+		mode = strings.ToLower(hc.Mode)
+	}
+
+	switch mode {
+	case healthcheck.ModeHTTP:
+		if hc.Path == "" {
+			return nil
+		}
+	case healthcheck.ModeGRPC:
+	default:
+		logger.Errorf("Illegal health check mode for service '%s': %s", backend, hc.Mode)
+		return nil
+	}
+
 	interval := defaultHealthCheckInterval
 	if hc.Interval != "" {
 		intervalOverride, err := time.ParseDuration(hc.Interval)
@@ -274,8 +503,10 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backe
 	}
 
 	return &healthcheck.Options{
+		Mode:            mode,
 		Scheme:          hc.Scheme,
 		Path:            hc.Path,
+		ServiceName:     hc.ServiceName,
 		Port:            hc.Port,
 		Interval:        interval,
 		Timeout:         timeout,
@@ -286,15 +517,56 @@ func buildHealthCheckOptions(ctx context.Context, lb healthcheck.Balancer, backe
 	}
 }
 
+func (m *Manager) buildPassiveHealthCheck(ctx context.Context, serviceName string, phc *dynamic.PassiveHealthCheck, next http.Handler) *healthcheck.PassiveHealthCheck {
+	defaults := &dynamic.PassiveHealthCheck{}
+	defaults.SetDefaults()
+
+	opts := healthcheck.PassiveOptions{
+		ConsecutiveFailures: phc.ConsecutiveFailures,
+		FailureRate:         phc.FailureRate,
+		MinRequests:         phc.MinRequests,
+		Window:              time.Duration(phc.Window),
+		BaseEjectionTime:    time.Duration(phc.BaseEjectionTime),
+		MaxEjectionTime:     time.Duration(phc.MaxEjectionTime),
+		MaxEjectionPercent:  phc.MaxEjectionPercent,
+	}
+
+	if opts.ConsecutiveFailures <= 0 && opts.FailureRate <= 0 {
+		opts.ConsecutiveFailures = defaults.ConsecutiveFailures
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = defaults.MinRequests
+	}
+	if opts.Window <= 0 {
+		opts.Window = time.Duration(defaults.Window)
+	}
+	if opts.BaseEjectionTime <= 0 {
+		opts.BaseEjectionTime = time.Duration(defaults.BaseEjectionTime)
+	}
+	if opts.MaxEjectionTime <= 0 {
+		opts.MaxEjectionTime = time.Duration(defaults.MaxEjectionTime)
+	}
+	if opts.MaxEjectionPercent <= 0 {
+		opts.MaxEjectionPercent = defaults.MaxEjectionPercent
+	}
+
+	log.FromContext(ctx).Debugf("Setting up passive health check for service %s with %+v", serviceName, opts)
+
+	var gauge gokitmetrics.Gauge
+	if m.metricsRegistry != nil && m.metricsRegistry.IsSvcEnabled() {
+		gauge = m.metricsRegistry.ServiceServerUpGauge()
+	}
+
+	return healthcheck.NewPassiveHealthCheck(next, serviceName, opts, gauge)
+}
+
 func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, service *dynamic.ServersLoadBalancer, fwd http.Handler) (healthcheck.BalancerHandler, error) {
 	logger := log.FromContext(ctx)
 	logger.Debug("Creating load-balancer")
 
-	var options []roundrobin.LBOption
-
-	var cookieName string
+	var stickySession *roundrobin.StickySession
 	if service.Sticky != nil && service.Sticky.Cookie != nil {
-		cookieName = cookie.GetName(service.Sticky.Cookie.Name, serviceName)
+		cookieName := cookie.GetName(service.Sticky.Cookie.Name, serviceName)
 
 		opts := roundrobin.CookieOptions{
 			HTTPOnly: service.Sticky.Cookie.HTTPOnly,
@@ -302,25 +574,85 @@ func (m *Manager) getLoadBalancer(ctx context.Context, serviceName string, servi
 			SameSite: convertSameSite(service.Sticky.Cookie.SameSite),
 		}
 
-		options = append(options, roundrobin.EnableStickySession(roundrobin.NewStickySessionWithOptions(cookieName, opts)))
+		stickySession = roundrobin.NewStickySessionWithOptions(cookieName, opts)
 
 		logger.Debugf("Sticky session cookie name: %v", cookieName)
 	}
 
-	lb, err := roundrobin.New(fwd, options...)
-	if err != nil {
-		return nil, err
+	if service.ConsistentHash != nil && service.Strategy != hashStrategy {
+		return nil, fmt.Errorf("consistentHash can only be set with the %s strategy", hashStrategy)
+	}
+
+	// Each step of a slow start would rebuild the hash ring, and move keys between the servers until it is over.
+	if service.SlowStart > 0 && service.Strategy == hashStrategy {
+		return nil, fmt.Errorf("slowStart cannot be set with the %s strategy", hashStrategy)
+	}
+
+	var lb healthcheck.BalancerHandler
+	switch service.Strategy {
+	case "", wrrStrategy:
+		var options []roundrobin.LBOption
+		if stickySession != nil {
+			options = append(options, roundrobin.EnableStickySession(stickySession))
+		}
+
+		var err error
+		lb, err = roundrobin.New(fwd, options...)
+		if err != nil {
+			return nil, err
+		}
+	case leastRequestsStrategy:
+		lb = leastrequests.New(fwd, stickySession, 0)
+	case p2cStrategy:
+		lb = leastrequests.New(fwd, stickySession, 2)
+	case hashStrategy:
+		var err error
+		lb, err = hashring.New(fwd, stickySession, service.ConsistentHash)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown load-balancing strategy %q", service.Strategy)
+	}
+
+	if service.SlowStart > 0 {
+		lb = slowstart.New(lb, serviceName, time.Duration(service.SlowStart), m.slowStartTracker)
 	}
 
 	lbsu := healthcheck.NewLBStatusUpdater(lb, m.configs[serviceName])
-	if err := m.upsertServers(ctx, lbsu, service.Servers); err != nil {
+	if err := m.upsertServers(ctx, serviceName, lbsu, service.Servers); err != nil {
 		return nil, fmt.Errorf("error configuring load balancer for service %s: %w", serviceName, err)
 	}
 
 	return lbsu, nil
 }
 
-func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHandler, servers []dynamic.Server) error {
+// getServersRoundTripper returns the round tripper of the servers transport of the given service,
+// sending a PROXY protocol header and reaching the unix sockets when the service requires it.
+func (m *Manager) getServersRoundTripper(service *dynamic.ServersLoadBalancer) (http.RoundTripper, error) {
+	roundTripper, err := m.roundTripperManager.Get(service.ServersTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	if service.ProxyProtocol != nil {
+		roundTripper, err = newProxyProtocolRoundTripper(roundTripper, service.ProxyProtocol.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hasUnixSocketServers(service.Servers) {
+		roundTripper, err = newUnixSocketRoundTripper(roundTripper)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return roundTripper, nil
+}
+
+func (m *Manager) upsertServers(ctx context.Context, serviceName string, lb healthcheck.BalancerHandler, servers []dynamic.Server) error {
 	logger := log.FromContext(ctx)
 
 	for name, srv := range servers {
@@ -329,9 +661,30 @@ func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHand
 			return fmt.Errorf("error parsing server URL %s: %w", srv.URL, err)
 		}
 
-		logger.WithField(log.ServerName, name).Debugf("Creating server %d %s", name, u)
+		weight := 1
+		if srv.Weight != nil {
+			weight = *srv.Weight
+		}
+		if weight < 0 {
+			return fmt.Errorf("invalid weight %d for server %s: weight must not be negative", weight, srv.URL)
+		}
+		if weight == 0 {
+			logger.WithField(log.ServerName, name).Debugf("Skipping server %d %s with a zero weight", name, u)
+			continue
+		}
+
+		if srv.DNS != nil && u.Scheme == unixSocketScheme {
+			return fmt.Errorf("DNS resolution is not supported with the unix socket server %s", srv.URL)
+		}
+
+		if srv.DNS != nil {
+			m.upsertResolvedServers(ctx, serviceName, lb, u, weight, srv.DNS)
+			continue
+		}
+
+		logger.WithField(log.ServerName, name).Debugf("Creating server %d %s with weight %d", name, u, weight)
 
-		if err := lb.UpsertServer(u, roundrobin.Weight(1)); err != nil {
+		if err := lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
 			return fmt.Errorf("error adding server %s to load balancer: %w", srv.URL, err)
 		}
 
@@ -340,6 +693,86 @@ func (m *Manager) upsertServers(ctx context.Context, lb healthcheck.BalancerHand
 	return nil
 }
 
+// weightedBalancer is implemented by the balancers able to report the weight of their servers.
+type weightedBalancer interface {
+	ServerWeight(u *url.URL) (int, bool)
+}
+
+// resolvedServer is a server resolved from a host name.
+type resolvedServer struct {
+	url    *url.URL
+	weight int
+}
+
+// upsertResolvedServers adds to the load-balancer a server for each address the host name of the given server URL resolves to,
+// and keeps them up to date with its DNS records.
+// Only the added servers are upserted, so that the servers taken out of the load-balancer by a health check stay out of it.
+func (m *Manager) upsertResolvedServers(ctx context.Context, serviceName string, lb healthcheck.BalancerHandler, u *url.URL, weight int, dns *dynamic.ServerDNS) {
+	logger := log.FromContext(ctx)
+
+	var mu sync.Mutex
+	servers := make(map[string]resolvedServer)
+
+	update := func(targets []resolver.Target) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		newServers := make(map[string]resolvedServer)
+		for _, target := range targets {
+			serverURL := *u
+			serverURL.Host = target.Address(u.Port())
+
+			serverWeight := weight
+			if target.Weight > 0 {
+				serverWeight = target.Weight
+			}
+
+			key := serverURL.String()
+			if server, ok := servers[key]; ok {
+				newServers[key] = server
+				if server.weight == serverWeight {
+					continue
+				}
+
+				// The weight of a server taken out by a health check is not updated, as upserting it would put it back.
+				if wb, ok := lb.(weightedBalancer); ok {
+					if _, enabled := wb.ServerWeight(server.url); !enabled {
+						continue
+					}
+				}
+			}
+
+			logger.Debugf("Creating server %s resolved from %s with weight %d", &serverURL, u.Hostname(), serverWeight)
+
+			if err := lb.UpsertServer(&serverURL, roundrobin.Weight(serverWeight)); err != nil {
+				logger.Errorf("Error adding server %s to load balancer: %v", &serverURL, err)
+				continue
+			}
+			newServers[key] = resolvedServer{url: &serverURL, weight: serverWeight}
+		}
+
+		for key, server := range servers {
+			if _, ok := newServers[key]; ok {
+				continue
+			}
+
+			logger.Debugf("Removing server %s no longer resolved from %s", server.url, u.Hostname())
+
+			if err := lb.RemoveServer(server.url); err != nil {
+				logger.Debugf("Error removing server %s from load balancer: %v", server.url, err)
+			}
+			m.forgetServer(serviceName, server.url)
+		}
+
+		servers = newServers
+	}
+
+	err := m.dnsWatchers.Watch(ctx, u.Hostname(), dns.Type, time.Duration(dns.RefreshInterval), update)
+	if err != nil {
+		logger.Errorf("Error resolving server %s: %v", u, err)
+	}
+}
+
 func convertSameSite(sameSite string) http.SameSite {
 	switch sameSite {
 	case "none":
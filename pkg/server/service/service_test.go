@@ -2,21 +2,31 @@ package service
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/healthcheck"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/server/service/resolver"
 	"github.com/containous/traefik/v2/pkg/testhelpers"
+	"github.com/containous/traefik/v2/pkg/types"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 type MockForwarder struct{}
 
+func Int(v int) *int { return &v }
+
 func (MockForwarder) ServeHTTP(http.ResponseWriter, *http.Request) {
 	panic("implement me")
 }
@@ -60,6 +70,90 @@ func TestGetLoadBalancer(t *testing.T) {
 			fwd:         &MockForwarder{},
 			expectError: false,
 		},
+		{
+			desc:        "Succeeds with the least requests strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: "leastrequests",
+				Servers:  []dynamic.Server{{URL: "http://foo"}},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Succeeds with the p2c strategy and sticky.cookie",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: "p2c",
+				Sticky:   &dynamic.Sticky{Cookie: &dynamic.Cookie{}},
+				Servers:  []dynamic.Server{{URL: "http://foo"}},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Succeeds with the consistent hash strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy:       "consistenthash",
+				ConsistentHash: &dynamic.ConsistentHash{RequestHeaderName: "X-Key"},
+				Servers:        []dynamic.Server{{URL: "http://foo"}},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Fails with consistent hash options and another strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				ConsistentHash: &dynamic.ConsistentHash{RequestHeaderName: "X-Key"},
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
+		{
+			desc:        "Fails with the consistent hash strategy and a slow start",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy:       "consistenthash",
+				ConsistentHash: &dynamic.ConsistentHash{RequestHeaderName: "X-Key"},
+				SlowStart:      types.Duration(time.Minute),
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
+		{
+			desc:        "Succeeds with weighted servers",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{
+					{URL: "http://foo", Weight: Int(3)},
+					{URL: "http://bar", Weight: Int(0)},
+				},
+			},
+			fwd:         &MockForwarder{},
+			expectError: false,
+		},
+		{
+			desc:        "Fails with a negative server weight",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{
+					{URL: "http://foo", Weight: Int(-1)},
+				},
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
+		{
+			desc:        "Fails with an unknown strategy",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Strategy: "foobar",
+			},
+			fwd:         &MockForwarder{},
+			expectError: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -80,7 +174,7 @@ func TestGetLoadBalancer(t *testing.T) {
 }
 
 func TestGetLoadBalancerServiceHandler(t *testing.T) {
-	sm := NewManager(nil, http.DefaultTransport, nil, nil)
+	sm := NewManager(nil, NewRoundTripperManager(http.DefaultTransport), nil, nil)
 
 	server1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-From", "first")
@@ -143,6 +237,36 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:        "Load balances between the two servers according to their weight",
+			serviceName: "test",
+			service: &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{
+					{
+						URL:    server1.URL,
+						Weight: Int(2),
+					},
+					{
+						URL:    server2.URL,
+						Weight: Int(1),
+					},
+				},
+			},
+			expected: []ExpectedResult{
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "first",
+				},
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "first",
+				},
+				{
+					StatusCode: http.StatusOK,
+					XFrom:      "second",
+				},
+			},
+		},
 		{
 			desc:        "StatusBadGateway when the server is not reachable",
 			serviceName: "test",
@@ -332,7 +456,7 @@ func TestManager_Build(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			manager := NewManager(test.configs, http.DefaultTransport, nil, nil)
+			manager := NewManager(test.configs, NewRoundTripperManager(http.DefaultTransport), nil, nil)
 
 			ctx := context.Background()
 			if len(test.providerName) > 0 {
@@ -345,6 +469,327 @@ func TestManager_Build(t *testing.T) {
 	}
 }
 
+func TestManager_BuildWithServersTransport(t *testing.T) {
+	rtManager := NewRoundTripperManager(http.DefaultTransport)
+	rtManager.Update(map[string]*dynamic.ServersTransport{
+		"test@provider-1": {},
+	})
+
+	testCases := []struct {
+		desc             string
+		serversTransport string
+		expectError      bool
+	}{
+		{
+			desc:             "servers transport from the same provider",
+			serversTransport: "test",
+		},
+		{
+			desc:             "servers transport with provider",
+			serversTransport: "test@provider-1",
+		},
+		{
+			desc:             "unknown servers transport",
+			serversTransport: "test@provider-2",
+			expectError:      true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			manager := NewManager(map[string]*runtime.ServiceInfo{
+				"serviceName@provider-1": {
+					Service: &dynamic.Service{
+						LoadBalancer: &dynamic.ServersLoadBalancer{
+							ServersTransport: test.serversTransport,
+						},
+					},
+				},
+			}, rtManager, nil, nil)
+
+			_, err := manager.BuildHTTP(context.Background(), "serviceName@provider-1", nil)
+			if test.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestManager_BuildWithPassiveHealthCheck(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+
+	serviceInfo := &runtime.ServiceInfo{
+		Service: &dynamic.Service{
+			LoadBalancer: &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{
+					{URL: failing.URL},
+					{URL: healthy.URL},
+				},
+				PassiveHealthCheck: &dynamic.PassiveHealthCheck{
+					ConsecutiveFailures: 2,
+				},
+			},
+		},
+	}
+
+	manager := NewManager(map[string]*runtime.ServiceInfo{"serviceName@provider-1": serviceInfo}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+
+	handler, err := manager.BuildHTTP(context.Background(), "serviceName@provider-1", nil)
+	require.NoError(t, err)
+
+	var failures int
+	for i := 0; i < 10; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo/", nil))
+		if recorder.Code != http.StatusOK {
+			failures++
+		}
+	}
+
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, "DOWN", serviceInfo.GetAllStatus()[failing.URL])
+	assert.Equal(t, "UP", serviceInfo.GetAllStatus()[healthy.URL])
+}
+
+func TestManager_BuildWithSlowStart(t *testing.T) {
+	for _, strategy := range []string{"", leastRequestsStrategy, p2cStrategy} {
+		strategy := strategy
+		t.Run(strategy, func(t *testing.T) {
+			t.Parallel()
+
+			serviceInfo := &runtime.ServiceInfo{
+				Service: &dynamic.Service{
+					LoadBalancer: &dynamic.ServersLoadBalancer{
+						Strategy: strategy,
+						Servers: []dynamic.Server{
+							{URL: "http://foo", Weight: Int(3)},
+						},
+						SlowStart: types.Duration(time.Minute),
+					},
+				},
+			}
+
+			manager := NewManager(map[string]*runtime.ServiceInfo{"serviceName@provider-1": serviceInfo}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+
+			_, err := manager.BuildHTTP(context.Background(), "serviceName@provider-1", nil)
+			require.NoError(t, err)
+
+			balancers := manager.balancers["serviceName@provider-1"]
+			require.Len(t, balancers, 1)
+
+			// The configured weight is reported, and not the current weight of the warming up server.
+			weight, ok := balancers.ServerWeight(testhelpers.MustParseURL("http://foo"))
+			assert.True(t, ok)
+			assert.Equal(t, 3, weight)
+		})
+	}
+}
+
+type forgetter struct {
+	mu      sync.Mutex
+	servers []string
+}
+
+func (f *forgetter) ForgetServer(u *url.URL) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.servers = append(f.servers, u.String())
+}
+
+func (f *forgetter) forgotten() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return strings.Join(f.servers, ",")
+}
+
+func TestManager_BuildWithDNS(t *testing.T) {
+	var mu sync.Mutex
+	targets := []string{"foo.example.com."}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	nameserver := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		question := req.Question[0]
+		hdr := dns.RR_Header{Name: question.Name, Rrtype: question.Qtype, Class: dns.ClassINET, Ttl: 60}
+
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		switch {
+		case question.Qtype == dns.TypeSRV:
+			for i, target := range targets {
+				resp.Answer = append(resp.Answer, &dns.SRV{Hdr: hdr, Weight: uint16(i + 1), Port: 8080, Target: target})
+			}
+		case question.Qtype == dns.TypeA && question.Name == "foo.example.com.":
+			resp.Answer = append(resp.Answer, &dns.A{Hdr: hdr, A: net.ParseIP("10.0.0.1")})
+		case question.Qtype == dns.TypeA && question.Name == "bar.example.com.":
+			resp.Answer = append(resp.Answer, &dns.A{Hdr: hdr, A: net.ParseIP("10.0.0.2")})
+		}
+		_ = w.WriteMsg(resp)
+	})}
+	go func() { _ = nameserver.ActivateAndServe() }()
+	defer func() { _ = nameserver.Shutdown() }()
+
+	serviceInfo := &runtime.ServiceInfo{
+		Service: &dynamic.Service{
+			LoadBalancer: &dynamic.ServersLoadBalancer{
+				Servers: []dynamic.Server{
+					{
+						URL: "http://_http._tcp.example.com",
+						DNS: &dynamic.ServerDNS{Type: "SRV", RefreshInterval: types.Duration(10 * time.Millisecond)},
+					},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(map[string]*runtime.ServiceInfo{"serviceName@provider-1": serviceInfo}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+	manager.SetDNSWatchers(resolver.NewWatchers(resolver.NewResolver([]string{conn.LocalAddr().String()})))
+	defer manager.dnsWatchers.NewGeneration()
+
+	healthCheck := &forgetter{}
+	manager.addHealthCheck("serviceName@provider-1", healthCheck)
+
+	_, err = manager.BuildHTTP(context.Background(), "serviceName@provider-1", nil)
+	require.NoError(t, err)
+
+	balancers := manager.balancers["serviceName@provider-1"]
+	require.Len(t, balancers, 1)
+	assert.Equal(t, []*url.URL{testhelpers.MustParseURL("http://10.0.0.1:8080")}, balancers.Servers())
+
+	// A server taken out by a health check is not put back by the DNS updates.
+	require.NoError(t, balancers.RemoveServer(testhelpers.MustParseURL("http://10.0.0.1:8080")))
+
+	mu.Lock()
+	targets = []string{"foo.example.com.", "bar.example.com."}
+	mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		servers := balancers.Servers()
+		return len(servers) == 1 && servers[0].String() == "http://10.0.0.2:8080"
+	}, time.Second, 10*time.Millisecond)
+
+	// The health checks forget about the servers no longer resolved.
+	mu.Lock()
+	targets = []string{"bar.example.com."}
+	mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return healthCheck.forgotten() == "http://10.0.0.1:8080"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []*url.URL{testhelpers.MustParseURL("http://10.0.0.2:8080")}, balancers.Servers())
+
+	weight, ok := balancers.ServerWeight(testhelpers.MustParseURL("http://10.0.0.2:8080"))
+	assert.True(t, ok)
+	assert.Equal(t, 1, weight)
+}
+
+func TestManager_BuildFailover(t *testing.T) {
+	fallback := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "fallback")
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer fallback.Close()
+
+	manager := NewManager(map[string]*runtime.ServiceInfo{
+		"failover@provider-1": {
+			Service: &dynamic.Service{
+				Failover: &dynamic.Failover{
+					Service:  "main",
+					Fallback: "fallback",
+				},
+			},
+		},
+		"main@provider-1": {
+			Service: &dynamic.Service{
+				LoadBalancer: &dynamic.ServersLoadBalancer{},
+			},
+		},
+		"fallback@provider-1": {
+			Service: &dynamic.Service{
+				LoadBalancer: &dynamic.ServersLoadBalancer{
+					Servers: []dynamic.Server{{URL: fallback.URL}},
+				},
+			},
+		},
+	}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+
+	handler, err := manager.BuildHTTP(context.Background(), "failover@provider-1", nil)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo/", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "fallback", recorder.Header().Get("server"))
+}
+
+func TestManager_BuildWeightedPropagatesStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "green")
+		rw.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	wrrInfo := &runtime.ServiceInfo{
+		Service: &dynamic.Service{
+			Weighted: &dynamic.WeightedRoundRobin{
+				Services: []dynamic.WRRService{
+					{Name: "blue", Weight: func(i int) *int { return &i }(1)},
+					{Name: "green", Weight: func(i int) *int { return &i }(1)},
+				},
+			},
+		},
+	}
+
+	manager := NewManager(map[string]*runtime.ServiceInfo{
+		"wrr@provider-1": wrrInfo,
+		"blue@provider-1": {
+			Service: &dynamic.Service{
+				LoadBalancer: &dynamic.ServersLoadBalancer{},
+			},
+		},
+		"green@provider-1": {
+			Service: &dynamic.Service{
+				LoadBalancer: &dynamic.ServersLoadBalancer{
+					Servers: []dynamic.Server{{URL: server.URL}},
+				},
+			},
+		},
+	}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+
+	handler, err := manager.BuildHTTP(context.Background(), "wrr@provider-1", nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo/", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "green", recorder.Header().Get("server"))
+	}
+
+	assert.Equal(t, map[string]string{"blue@provider-1": "DOWN", "green@provider-1": "UP"}, wrrInfo.GetAllStatus())
+}
+
 func TestMultipleTypeOnBuildHTTP(t *testing.T) {
 	services := map[string]*runtime.ServiceInfo{
 		"test@file": {
@@ -355,10 +800,54 @@ func TestMultipleTypeOnBuildHTTP(t *testing.T) {
 		},
 	}
 
-	manager := NewManager(services, http.DefaultTransport, nil, nil)
+	manager := NewManager(services, NewRoundTripperManager(http.DefaultTransport), nil, nil)
 
 	_, err := manager.BuildHTTP(context.Background(), "test@file", nil)
 	assert.Error(t, err, "cannot create service: multi-types service not supported, consider declaring two different pieces of service instead")
 }
 
 // FIXME Add healthcheck tests
+
+func TestBuildHealthCheckOptions_mode(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		healthCheck  *dynamic.HealthCheck
+		expectedMode string
+	}{
+		{
+			desc:         "default mode",
+			healthCheck:  &dynamic.HealthCheck{Path: "/health"},
+			expectedMode: healthcheck.ModeHTTP,
+		},
+		{
+			desc:        "http mode without path",
+			healthCheck: &dynamic.HealthCheck{Mode: "http"},
+		},
+		{
+			desc:         "grpc mode without path",
+			healthCheck:  &dynamic.HealthCheck{Mode: "gRPC", ServiceName: "foo"},
+			expectedMode: healthcheck.ModeGRPC,
+		},
+		{
+			desc:        "unknown mode",
+			healthCheck: &dynamic.HealthCheck{Mode: "foo", Path: "/health"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			opts := buildHealthCheckOptions(context.Background(), nil, "foo", test.healthCheck)
+			if test.expectedMode == "" {
+				assert.Nil(t, opts)
+				return
+			}
+
+			require.NotNil(t, opts)
+			assert.Equal(t, test.expectedMode, opts.Mode)
+			assert.Equal(t, test.healthCheck.ServiceName, opts.ServiceName)
+		})
+	}
+}
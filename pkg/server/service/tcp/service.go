@@ -5,26 +5,47 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/affinity"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/healthcheck"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/server/service/resolver"
 	"github.com/containous/traefik/v2/pkg/tcp"
 )
 
 // Manager is the TCPHandlers factory.
 type Manager struct {
-	configs map[string]*runtime.TCPServiceInfo
+	configs        map[string]*runtime.TCPServiceInfo
+	healthCheckers map[string]*healthcheck.ServiceChecker
+	affinityTables *affinity.Tables
+	dnsWatchers    *resolver.Watchers
 }
 
 // NewManager creates a new manager.
 func NewManager(conf *runtime.Configuration) *Manager {
 	return &Manager{
-		configs: conf.TCPServices,
+		configs:        conf.TCPServices,
+		healthCheckers: make(map[string]*healthcheck.ServiceChecker),
 	}
 }
 
+// SetAffinityTables sets the affinity tables shared with the managers of the previous configurations.
+func (m *Manager) SetAffinityTables(tables *affinity.Tables) {
+	m.affinityTables = tables
+}
+
+// SetDNSWatchers sets the watchers of the server host names, shared with the managers of the previous configurations.
+func (m *Manager) SetDNSWatchers(watchers *resolver.Watchers) {
+	m.dnsWatchers = watchers
+}
+
 // BuildTCP Creates a tcp.Handler for a service configuration.
 func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Handler, error) {
 	serviceQualifiedName := provider.GetQualifiedName(rootCtx, serviceName)
@@ -36,7 +57,14 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 		return nil, fmt.Errorf("the service %q does not exist", serviceQualifiedName)
 	}
 
-	if conf.LoadBalancer != nil && conf.Weighted != nil {
+	value := reflect.ValueOf(*conf.TCPService)
+	var count int
+	for i := 0; i < value.NumField(); i++ {
+		if !value.Field(i).IsNil() {
+			count++
+		}
+	}
+	if count > 1 {
 		err := errors.New("cannot create service: multi-types service not supported, consider declaring two different pieces of service instead")
 		conf.AddError(err, true)
 		return nil, err
@@ -53,36 +81,238 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 		}
 		duration := time.Duration(*conf.LoadBalancer.TerminationDelay) * time.Millisecond
 
+		checker := m.getHealthChecker(ctx, serviceQualifiedName, conf)
+		if checker != nil {
+			checker.AddBalancer(loadBalancer)
+		}
+
+		m.setAffinity(loadBalancer, serviceQualifiedName, conf.LoadBalancer.Affinity)
+
 		for name, server := range conf.LoadBalancer.Servers {
-			if _, _, err := net.SplitHostPort(server.Address); err != nil {
-				logger.Errorf("In service %q: %v", serviceQualifiedName, err)
+			if server.Weight != nil && *server.Weight < 0 {
+				logger.Errorf("In service %q server %q: weight must not be negative", serviceQualifiedName, server.Address)
+				continue
+			}
+			if server.Weight != nil && *server.Weight == 0 {
+				logger.WithField(log.ServerName, name).Debugf("Skipping TCP server %d at %s with a zero weight", name, server.Address)
 				continue
 			}
 
-			handler, err := tcp.NewProxy(server.Address, duration)
+			network, address := tcp.SplitNetworkAddress(server.Address)
+
+			if server.DNS != nil && network == "unix" {
+				logger.Errorf("In service %q server %q: DNS resolution is not supported with unix sockets", serviceQualifiedName, server.Address)
+				continue
+			}
+
+			if server.DNS != nil {
+				m.addResolvedServers(ctx, serviceQualifiedName, loadBalancer, checker, server, duration, conf.LoadBalancer.ProxyProtocol)
+				continue
+			}
+
+			if network == "tcp" {
+				if _, _, err := net.SplitHostPort(address); err != nil {
+					logger.Errorf("In service %q: %v", serviceQualifiedName, err)
+					continue
+				}
+			}
+
+			handler, err := tcp.NewProxy(server.Address, duration, conf.LoadBalancer.ProxyProtocol)
 			if err != nil {
 				logger.Errorf("In service %q server %q: %v", serviceQualifiedName, server.Address, err)
 				continue
 			}
 
-			loadBalancer.AddServer(handler)
+			loadBalancer.AddNamedServer(server.Address, handler, server.Weight)
+			if checker != nil {
+				checker.AddServer(server.Address)
+			}
 			logger.WithField(log.ServerName, name).Debugf("Creating TCP server %d at %s", name, server.Address)
 		}
 		return loadBalancer, nil
 	case conf.Weighted != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer()
+		m.setAffinity(loadBalancer, serviceQualifiedName, conf.Weighted.Affinity)
+
 		for _, service := range conf.Weighted.Services {
 			handler, err := m.BuildTCP(rootCtx, service.Name)
 			if err != nil {
 				logger.Errorf("In service %q: %v", serviceQualifiedName, err)
 				return nil, err
 			}
-			loadBalancer.AddWeightServer(handler, service.Weight)
+			loadBalancer.AddNamedServer(service.Name, handler, service.Weight)
 		}
 		return loadBalancer, nil
+	case conf.Mirroring != nil:
+		handler, err := m.BuildTCP(rootCtx, conf.Mirroring.Service)
+		if err != nil {
+			logger.Errorf("In service %q: %v", serviceQualifiedName, err)
+			return nil, err
+		}
+
+		mirroring := tcp.NewMirroring(handler)
+		for _, mirror := range conf.Mirroring.Mirrors {
+			mirrorHandler, err := m.BuildTCP(rootCtx, mirror.Name)
+			if err != nil {
+				logger.Errorf("In service %q: %v", serviceQualifiedName, err)
+				return nil, err
+			}
+
+			if err := mirroring.AddMirror(mirrorHandler, mirror.Percent); err != nil {
+				conf.AddError(err, true)
+				return nil, err
+			}
+		}
+		return mirroring, nil
 	default:
 		err := fmt.Errorf("the service %q does not have any type defined", serviceQualifiedName)
 		conf.AddError(err, true)
 		return nil, err
 	}
 }
+
+// addResolvedServers adds to the load-balancer a server for each address the host name of the given server resolves to,
+// and keeps them up to date with its DNS records.
+func (m *Manager) addResolvedServers(ctx context.Context, serviceName string, loadBalancer *tcp.WRRLoadBalancer, checker *healthcheck.ServiceChecker, server dynamic.TCPServer, terminationDelay time.Duration, proxyProtocol *dynamic.ProxyProtocol) {
+	logger := log.FromContext(ctx)
+
+	host, port, err := net.SplitHostPort(server.Address)
+	if err != nil {
+		// The port of the servers can be given by the SRV records.
+		host, port = server.Address, ""
+	}
+	if port == "" && !strings.EqualFold(server.DNS.Type, resolver.TypeSRV) {
+		logger.Errorf("In service %q server %q: missing port in address", serviceName, server.Address)
+		return
+	}
+
+	var mu sync.Mutex
+	weights := make(map[string]int)
+
+	update := func(targets []resolver.Target) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		newWeights := make(map[string]int)
+		for _, target := range targets {
+			address := target.Address(port)
+
+			weight := 1
+			if server.Weight != nil {
+				weight = *server.Weight
+			}
+			if target.Weight > 0 {
+				weight = target.Weight
+			}
+			newWeights[address] = weight
+
+			if w, ok := weights[address]; ok {
+				if w == weight {
+					continue
+				}
+				loadBalancer.RemoveServer(address)
+			}
+
+			handler, err := tcp.NewProxy(address, terminationDelay, proxyProtocol)
+			if err != nil {
+				logger.Errorf("In service %q server %q: %v", serviceName, address, err)
+				delete(newWeights, address)
+				continue
+			}
+
+			loadBalancer.AddNamedServer(address, handler, &weight)
+			if checker != nil {
+				checker.AddServer(address)
+			}
+			logger.Debugf("Creating TCP server %s resolved from %s", address, host)
+		}
+
+		for address := range weights {
+			if _, ok := newWeights[address]; ok {
+				continue
+			}
+
+			loadBalancer.RemoveServer(address)
+			if checker != nil {
+				checker.RemoveServer(address)
+			}
+			logger.Debugf("Removing TCP server %s no longer resolved from %s", address, host)
+		}
+
+		weights = newWeights
+	}
+
+	err = m.dnsWatchers.Watch(ctx, host, server.DNS.Type, time.Duration(server.DNS.RefreshInterval), update)
+	if err != nil {
+		logger.Errorf("In service %q server %q: %v", serviceName, server.Address, err)
+	}
+}
+
+func (m *Manager) setAffinity(loadBalancer *tcp.WRRLoadBalancer, serviceName string, affinity *dynamic.TCPAffinity) {
+	if affinity == nil {
+		return
+	}
+
+	if affinity.TTL <= 0 {
+		loadBalancer.SetAffinity(nil)
+		return
+	}
+
+	loadBalancer.SetAffinity(m.affinityTables.Get(serviceName, time.Duration(affinity.TTL)))
+}
+
+// getHealthChecker returns the health checker of the given service, if it has a health check configured.
+// The checker is shared by all the load-balancers built for the service.
+func (m *Manager) getHealthChecker(ctx context.Context, serviceName string, conf *runtime.TCPServiceInfo) *healthcheck.ServiceChecker {
+	hc := conf.LoadBalancer.HealthCheck
+	if hc == nil {
+		return nil
+	}
+
+	if checker, ok := m.healthCheckers[serviceName]; ok {
+		return checker
+	}
+
+	opts := buildHealthCheckOptions(hc)
+	log.FromContext(ctx).Debugf("Setting up healthcheck for service %s with %s", serviceName, opts)
+
+	checker := healthcheck.NewTCPServiceChecker(serviceName, opts, conf)
+	m.healthCheckers[serviceName] = checker
+	return checker
+}
+
+func buildHealthCheckOptions(hc *dynamic.TCPHealthCheck) healthcheck.ServiceOptions {
+	defaults := &dynamic.TCPHealthCheck{}
+	defaults.SetDefaults()
+
+	opts := healthcheck.ServiceOptions{
+		Port:               hc.Port,
+		Interval:           time.Duration(hc.Interval),
+		Timeout:            time.Duration(hc.Timeout),
+		UnhealthyThreshold: hc.UnhealthyThreshold,
+		HealthyThreshold:   hc.HealthyThreshold,
+		Send:               hc.Send,
+		Expect:             hc.Expect,
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = time.Duration(defaults.Interval)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Duration(defaults.Timeout)
+	}
+	if opts.UnhealthyThreshold <= 0 {
+		opts.UnhealthyThreshold = defaults.UnhealthyThreshold
+	}
+	if opts.HealthyThreshold <= 0 {
+		opts.HealthyThreshold = defaults.HealthyThreshold
+	}
+
+	return opts
+}
+
+// LaunchHealthCheck launches the health checks of the services built by the manager,
+// and stops the ones of the previous configuration.
+func (m *Manager) LaunchHealthCheck() {
+	healthcheck.GetTCPHealthCheck().SetCheckers(context.Background(), m.healthCheckers)
+}
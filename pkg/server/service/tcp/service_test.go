@@ -2,11 +2,17 @@ package tcp
 
 import (
 	"context"
+	"net"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/server/service/resolver"
+	ptypes "github.com/containous/traefik/v2/pkg/types"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -135,6 +141,24 @@ func TestManager_BuildTCP(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "Server with unix socket as address",
+			serviceName: "serviceName",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"serviceName@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{
+								{
+									Address: "unix:///run/app.sock",
+								},
+							},
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
 		{
 			desc:        "missing port in address with hostname, server is skipped, error is logged",
 			serviceName: "serviceName",
@@ -171,6 +195,94 @@ func TestManager_BuildTCP(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "mirroring service",
+			serviceName: "mirrored",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"mirrored@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Mirroring: &dynamic.TCPMirroring{
+							Service: "main",
+							Mirrors: []dynamic.TCPMirrorService{{Name: "mirror", Percent: 10}},
+						},
+					},
+				},
+				"main@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{{Address: "192.168.0.12:80"}},
+						},
+					},
+				},
+				"mirror@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{{Address: "192.168.0.13:80"}},
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
+		{
+			desc:        "mirroring service with an unknown mirror",
+			serviceName: "mirrored",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"mirrored@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Mirroring: &dynamic.TCPMirroring{
+							Service: "main",
+							Mirrors: []dynamic.TCPMirrorService{{Name: "mirror", Percent: 10}},
+						},
+					},
+				},
+				"main@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{{Address: "192.168.0.12:80"}},
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: `the service "mirror@provider-1" does not exist`,
+		},
+		{
+			desc:        "mirroring service with an invalid percent",
+			serviceName: "mirrored",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"mirrored@provider-1": {
+					TCPService: &dynamic.TCPService{
+						Mirroring: &dynamic.TCPMirroring{
+							Service: "main",
+							Mirrors: []dynamic.TCPMirrorService{{Name: "main", Percent: 101}},
+						},
+					},
+				},
+				"main@provider-1": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{
+							Servers: []dynamic.TCPServer{{Address: "192.168.0.12:80"}},
+						},
+					},
+				},
+			},
+			providerName:  "provider-1",
+			expectedError: "percent must be between 0 and 100",
+		},
+		{
+			desc:        "multi-types service",
+			serviceName: "test",
+			configs: map[string]*runtime.TCPServiceInfo{
+				"test": {
+					TCPService: &dynamic.TCPService{
+						LoadBalancer: &dynamic.TCPServersLoadBalancer{},
+						Mirroring:    &dynamic.TCPMirroring{Service: "main"},
+					},
+				},
+			},
+			expectedError: "cannot create service: multi-types service not supported, consider declaring two different pieces of service instead",
+		},
 	}
 
 	for _, test := range testCases {
@@ -199,3 +311,106 @@ func TestManager_BuildTCP(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_BuildTCPWithHealthCheck(t *testing.T) {
+	serviceInfo := &runtime.TCPServiceInfo{
+		TCPService: &dynamic.TCPService{
+			LoadBalancer: &dynamic.TCPServersLoadBalancer{
+				Servers: []dynamic.TCPServer{
+					{Address: "127.0.0.1:8080"},
+					{Address: "127.0.0.1:8081"},
+				},
+				HealthCheck: &dynamic.TCPHealthCheck{Send: "PING"},
+			},
+		},
+	}
+
+	manager := NewManager(&runtime.Configuration{
+		TCPServices: map[string]*runtime.TCPServiceInfo{"test@file": serviceInfo},
+	})
+
+	ctx := provider.AddInContext(context.Background(), "foobar@file")
+
+	// The service is used by two routers, which share the same health checker.
+	for i := 0; i < 2; i++ {
+		handler, err := manager.BuildTCP(ctx, "test")
+		require.NoError(t, err)
+		require.NotNil(t, handler)
+	}
+
+	require.Len(t, manager.healthCheckers, 1)
+	assert.NotNil(t, manager.healthCheckers["test@file"])
+	assert.Equal(t, map[string]string{
+		"127.0.0.1:8080": "UP",
+		"127.0.0.1:8081": "UP",
+	}, serviceInfo.GetAllStatus())
+}
+
+func TestManager_BuildTCPWithDNS(t *testing.T) {
+	var mu sync.Mutex
+	ips := []string{"127.0.0.1", "127.0.0.2"}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	nameserver := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(func(w dns.ResponseWriter, req *dns.Msg) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		if req.Question[0].Qtype == dns.TypeA {
+			for _, ip := range ips {
+				resp.Answer = append(resp.Answer, &dns.A{
+					Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+					A:   net.ParseIP(ip),
+				})
+			}
+		}
+		_ = w.WriteMsg(resp)
+	})}
+	go func() { _ = nameserver.ActivateAndServe() }()
+	defer func() { _ = nameserver.Shutdown() }()
+
+	serviceInfo := &runtime.TCPServiceInfo{
+		TCPService: &dynamic.TCPService{
+			LoadBalancer: &dynamic.TCPServersLoadBalancer{
+				Servers: []dynamic.TCPServer{
+					{
+						Address: "backend.example.com:8080",
+						DNS:     &dynamic.ServerDNS{RefreshInterval: ptypes.Duration(10 * time.Millisecond)},
+					},
+				},
+				HealthCheck: &dynamic.TCPHealthCheck{},
+			},
+		},
+	}
+
+	manager := NewManager(&runtime.Configuration{
+		TCPServices: map[string]*runtime.TCPServiceInfo{"test@file": serviceInfo},
+	})
+	watchers := resolver.NewWatchers(resolver.NewResolver([]string{conn.LocalAddr().String()}))
+	defer watchers.NewGeneration()
+	manager.SetDNSWatchers(watchers)
+
+	handler, err := manager.BuildTCP(provider.AddInContext(context.Background(), "foobar@file"), "test")
+	require.NoError(t, err)
+	require.NotNil(t, handler)
+
+	assert.Equal(t, map[string]string{
+		"127.0.0.1:8080": "UP",
+		"127.0.0.2:8080": "UP",
+	}, serviceInfo.GetAllStatus())
+
+	mu.Lock()
+	ips = []string{"127.0.0.2", "127.0.0.3"}
+	mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(map[string]string{
+			"127.0.0.1:8080": "DOWN",
+			"127.0.0.2:8080": "UP",
+			"127.0.0.3:8080": "UP",
+		}, serviceInfo.GetAllStatus())
+	}, time.Second, 10*time.Millisecond)
+}
@@ -5,25 +5,46 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/containous/traefik/v2/pkg/affinity"
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/containous/traefik/v2/pkg/healthcheck"
 	"github.com/containous/traefik/v2/pkg/log"
 	"github.com/containous/traefik/v2/pkg/server/provider"
+	"github.com/containous/traefik/v2/pkg/server/service/resolver"
 	"github.com/containous/traefik/v2/pkg/udp"
 )
 
 // Manager handles UDP services creation.
 type Manager struct {
-	configs map[string]*runtime.UDPServiceInfo
+	configs        map[string]*runtime.UDPServiceInfo
+	healthCheckers map[string]*healthcheck.ServiceChecker
+	affinityTables *affinity.Tables
+	dnsWatchers    *resolver.Watchers
 }
 
 // NewManager creates a new manager.
 func NewManager(conf *runtime.Configuration) *Manager {
 	return &Manager{
-		configs: conf.UDPServices,
+		configs:        conf.UDPServices,
+		healthCheckers: make(map[string]*healthcheck.ServiceChecker),
 	}
 }
 
+// SetAffinityTables sets the affinity tables shared with the managers of the previous configurations.
+func (m *Manager) SetAffinityTables(tables *affinity.Tables) {
+	m.affinityTables = tables
+}
+
+// SetDNSWatchers sets the watchers of the server host names, shared with the managers of the previous configurations.
+func (m *Manager) SetDNSWatchers(watchers *resolver.Watchers) {
+	m.dnsWatchers = watchers
+}
+
 // BuildUDP creates the UDP handler for the given service name.
 func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Handler, error) {
 	serviceQualifiedName := provider.GetQualifiedName(rootCtx, serviceName)
@@ -46,7 +67,28 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 	case conf.LoadBalancer != nil:
 		loadBalancer := udp.NewWRRLoadBalancer()
 
+		checker := m.getHealthChecker(ctx, serviceQualifiedName, conf)
+		if checker != nil {
+			checker.AddBalancer(loadBalancer)
+		}
+
+		m.setAffinity(loadBalancer, serviceQualifiedName, conf.LoadBalancer.Affinity)
+
 		for name, server := range conf.LoadBalancer.Servers {
+			if server.Weight != nil && *server.Weight < 0 {
+				logger.Errorf("In udp service %q server %q: weight must not be negative", serviceQualifiedName, server.Address)
+				continue
+			}
+			if server.Weight != nil && *server.Weight == 0 {
+				logger.WithField(log.ServerName, name).Debugf("Skipping UDP server %d at %s with a zero weight", name, server.Address)
+				continue
+			}
+
+			if server.DNS != nil {
+				m.addResolvedServers(ctx, serviceQualifiedName, loadBalancer, checker, server)
+				continue
+			}
+
 			if _, _, err := net.SplitHostPort(server.Address); err != nil {
 				logger.Errorf("In udp service %q: %v", serviceQualifiedName, err)
 				continue
@@ -58,19 +100,24 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 				continue
 			}
 
-			loadBalancer.AddServer(handler)
+			loadBalancer.AddNamedServer(server.Address, handler, server.Weight)
+			if checker != nil {
+				checker.AddServer(server.Address)
+			}
 			logger.WithField(log.ServerName, name).Debugf("Creating UDP server %d at %s", name, server.Address)
 		}
 		return loadBalancer, nil
 	case conf.Weighted != nil:
 		loadBalancer := udp.NewWRRLoadBalancer()
+		m.setAffinity(loadBalancer, serviceQualifiedName, conf.Weighted.Affinity)
+
 		for _, service := range conf.Weighted.Services {
 			handler, err := m.BuildUDP(rootCtx, service.Name)
 			if err != nil {
 				logger.Errorf("In udp service %q: %v", serviceQualifiedName, err)
 				return nil, err
 			}
-			loadBalancer.AddWeightedServer(handler, service.Weight)
+			loadBalancer.AddNamedServer(service.Name, handler, service.Weight)
 		}
 		return loadBalancer, nil
 	default:
@@ -79,3 +126,156 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 		return nil, err
 	}
 }
+
+// addResolvedServers adds to the load-balancer a server for each address the host name of the given server resolves to,
+// and keeps them up to date with its DNS records.
+func (m *Manager) addResolvedServers(ctx context.Context, serviceName string, loadBalancer *udp.WRRLoadBalancer, checker *healthcheck.ServiceChecker, server dynamic.UDPServer) {
+	logger := log.FromContext(ctx)
+
+	host, port, err := net.SplitHostPort(server.Address)
+	if err != nil {
+		// The port of the servers can be given by the SRV records.
+		host, port = server.Address, ""
+	}
+	if port == "" && !strings.EqualFold(server.DNS.Type, resolver.TypeSRV) {
+		logger.Errorf("In udp service %q server %q: missing port in address", serviceName, server.Address)
+		return
+	}
+
+	var mu sync.Mutex
+	weights := make(map[string]int)
+
+	update := func(targets []resolver.Target) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		newWeights := make(map[string]int)
+		for _, target := range targets {
+			address := target.Address(port)
+
+			weight := 1
+			if server.Weight != nil {
+				weight = *server.Weight
+			}
+			if target.Weight > 0 {
+				weight = target.Weight
+			}
+			newWeights[address] = weight
+
+			if w, ok := weights[address]; ok {
+				if w == weight {
+					continue
+				}
+				loadBalancer.RemoveServer(address)
+			}
+
+			handler, err := udp.NewProxy(address)
+			if err != nil {
+				logger.Errorf("In udp service %q server %q: %v", serviceName, address, err)
+				delete(newWeights, address)
+				continue
+			}
+
+			loadBalancer.AddNamedServer(address, handler, &weight)
+			if checker != nil {
+				checker.AddServer(address)
+			}
+			logger.Debugf("Creating UDP server %s resolved from %s", address, host)
+		}
+
+		for address := range weights {
+			if _, ok := newWeights[address]; ok {
+				continue
+			}
+
+			loadBalancer.RemoveServer(address)
+			if checker != nil {
+				checker.RemoveServer(address)
+			}
+			logger.Debugf("Removing UDP server %s no longer resolved from %s", address, host)
+		}
+
+		weights = newWeights
+	}
+
+	err = m.dnsWatchers.Watch(ctx, host, server.DNS.Type, time.Duration(server.DNS.RefreshInterval), update)
+	if err != nil {
+		logger.Errorf("In udp service %q server %q: %v", serviceName, server.Address, err)
+	}
+}
+
+func (m *Manager) setAffinity(loadBalancer *udp.WRRLoadBalancer, serviceName string, affinity *dynamic.UDPAffinity) {
+	if affinity == nil {
+		return
+	}
+
+	if affinity.TTL <= 0 {
+		loadBalancer.SetAffinity(nil)
+		return
+	}
+
+	loadBalancer.SetAffinity(m.affinityTables.Get(serviceName, time.Duration(affinity.TTL)))
+}
+
+// getHealthChecker returns the health checker of the given service, if it has a valid health check configured.
+// The checker is shared by all the load-balancers built for the service.
+func (m *Manager) getHealthChecker(ctx context.Context, serviceName string, conf *runtime.UDPServiceInfo) *healthcheck.ServiceChecker {
+	hc := conf.LoadBalancer.HealthCheck
+	if hc == nil {
+		return nil
+	}
+
+	if checker, ok := m.healthCheckers[serviceName]; ok {
+		return checker
+	}
+
+	if hc.Send == "" {
+		err := errors.New("the health check of a UDP service requires a payload to send")
+		log.FromContext(ctx).Errorf("In udp service %q: %v", serviceName, err)
+		conf.AddError(err, false)
+		return nil
+	}
+
+	opts := buildHealthCheckOptions(hc)
+	log.FromContext(ctx).Debugf("Setting up healthcheck for udp service %s with %s", serviceName, opts)
+
+	checker := healthcheck.NewUDPServiceChecker(serviceName, opts, conf)
+	m.healthCheckers[serviceName] = checker
+	return checker
+}
+
+func buildHealthCheckOptions(hc *dynamic.UDPHealthCheck) healthcheck.ServiceOptions {
+	defaults := &dynamic.UDPHealthCheck{}
+	defaults.SetDefaults()
+
+	opts := healthcheck.ServiceOptions{
+		Port:               hc.Port,
+		Interval:           time.Duration(hc.Interval),
+		Timeout:            time.Duration(hc.Timeout),
+		UnhealthyThreshold: hc.UnhealthyThreshold,
+		HealthyThreshold:   hc.HealthyThreshold,
+		Send:               hc.Send,
+		Expect:             hc.Expect,
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = time.Duration(defaults.Interval)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Duration(defaults.Timeout)
+	}
+	if opts.UnhealthyThreshold <= 0 {
+		opts.UnhealthyThreshold = defaults.UnhealthyThreshold
+	}
+	if opts.HealthyThreshold <= 0 {
+		opts.HealthyThreshold = defaults.HealthyThreshold
+	}
+
+	return opts
+}
+
+// LaunchHealthCheck launches the health checks of the services built by the manager,
+// and stops the ones of the previous configuration.
+func (m *Manager) LaunchHealthCheck() {
+	healthcheck.GetUDPHealthCheck().SetCheckers(context.Background(), m.healthCheckers)
+}
@@ -199,3 +199,53 @@ func TestManager_BuildUDP(t *testing.T) {
 		})
 	}
 }
+
+func TestManager_BuildUDPWithHealthCheck(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		healthCheck     *dynamic.UDPHealthCheck
+		expectedChecker bool
+		expectedErr     []string
+	}{
+		{
+			desc:            "with a payload to send",
+			healthCheck:     &dynamic.UDPHealthCheck{Send: "ping"},
+			expectedChecker: true,
+		},
+		{
+			desc:        "without a payload to send",
+			healthCheck: &dynamic.UDPHealthCheck{},
+			expectedErr: []string{"the health check of a UDP service requires a payload to send"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			serviceInfo := &runtime.UDPServiceInfo{
+				UDPService: &dynamic.UDPService{
+					LoadBalancer: &dynamic.UDPServersLoadBalancer{
+						Servers: []dynamic.UDPServer{
+							{Address: "127.0.0.1:8080"},
+						},
+						HealthCheck: test.healthCheck,
+					},
+				},
+			}
+
+			manager := NewManager(&runtime.Configuration{
+				UDPServices: map[string]*runtime.UDPServiceInfo{"test@file": serviceInfo},
+			})
+
+			handler, err := manager.BuildUDP(provider.AddInContext(context.Background(), "foobar@file"), "test")
+			require.NoError(t, err)
+			require.NotNil(t, handler)
+
+			_, ok := manager.healthCheckers["test@file"]
+			assert.Equal(t, test.expectedChecker, ok)
+			assert.Equal(t, test.expectedErr, serviceInfo.Err)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+)
+
+// unixSocketScheme is the scheme of the URLs of the servers listening on a unix socket, such as unix:///run/app.sock.
+// The requests to these servers carry the path of the socket as their host, as their path is the one of the forwarded request.
+const unixSocketScheme = "unix"
+
+type unixSocketKey struct{}
+
+// unixSocketRoundTripper sends the requests to the servers listening on a unix socket over HTTP/1.1,
+// and the other requests to the next round tripper.
+type unixSocketRoundTripper struct {
+	next http.RoundTripper
+	unix http.RoundTripper
+}
+
+func newUnixSocketRoundTripper(roundTripper http.RoundTripper) (http.RoundTripper, error) {
+	// The PROXY protocol header is also written on the connections to the sockets.
+	if rt, ok := roundTripper.(*proxyProtocolRoundTripper); ok {
+		return &unixSocketRoundTripper{
+			next: rt,
+			unix: &proxyProtocolRoundTripper{transport: newUnixSocketTransport(rt.transport)},
+		}, nil
+	}
+
+	transport, err := httpTransport(roundTripper)
+	if err != nil {
+		return nil, fmt.Errorf("unix sockets are not supported by the round tripper %T", roundTripper)
+	}
+
+	return &unixSocketRoundTripper{next: roundTripper, unix: newUnixSocketTransport(transport)}, nil
+}
+
+// newUnixSocketTransport returns a copy of the given transport dialing the socket given by the request context.
+func newUnixSocketTransport(transport *http.Transport) *http.Transport {
+	transport = transport.Clone()
+	transport.Proxy = nil
+
+	dialContext := transport.DialContext
+	if dialContext == nil {
+		dialContext = (&net.Dialer{}).DialContext
+	}
+
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		socket, ok := ctx.Value(unixSocketKey{}).(string)
+		if !ok {
+			return nil, errors.New("no unix socket to dial")
+		}
+
+		return dialContext(ctx, "unix", socket)
+	}
+
+	return transport
+}
+
+func (u *unixSocketRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != unixSocketScheme {
+		return u.next.RoundTrip(req)
+	}
+
+	socket := req.URL.Host
+	if socket == "" {
+		return nil, fmt.Errorf("no unix socket in URL %s", req.URL)
+	}
+
+	outReq := req.WithContext(context.WithValue(req.Context(), unixSocketKey{}, socket))
+
+	// The transport pools the connections by host, hence one host per socket.
+	outURL := *req.URL
+	outURL.Scheme = "http"
+	outURL.Host = hex.EncodeToString([]byte(socket))
+	outReq.URL = &outURL
+
+	if outReq.Host == "" || outReq.Host == socket {
+		outReq.Host = "localhost"
+	}
+
+	return u.unix.RoundTrip(outReq)
+}
+
+// hasUnixSocketServers returns whether any of the given servers listens on a unix socket.
+func hasUnixSocketServers(servers []dynamic.Server) bool {
+	for _, server := range servers {
+		if strings.HasPrefix(server.URL, unixSocketScheme+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// httpTransport returns the transport used by the given round tripper.
+func httpTransport(roundTripper http.RoundTripper) (*http.Transport, error) {
+	switch rt := roundTripper.(type) {
+	case *http.Transport:
+		return rt, nil
+	case *smartRoundTripper:
+		return rt.http, nil
+	default:
+		return nil, fmt.Errorf("unsupported round tripper %T", roundTripper)
+	}
+}
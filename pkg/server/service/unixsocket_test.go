@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/containous/traefik/v2/pkg/config/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_BuildWithUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(req.Host + req.URL.RequestURI()))
+	}))
+	_ = server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	testCases := []struct {
+		desc           string
+		passHostHeader bool
+		expected       string
+	}{
+		{
+			desc:           "pass host header",
+			passHostHeader: true,
+			expected:       "foo.com/bar?baz=qux",
+		},
+		{
+			desc:     "do not pass host header",
+			expected: "localhost/bar?baz=qux",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			manager := NewManager(map[string]*runtime.ServiceInfo{
+				"test@file": {
+					Service: &dynamic.Service{
+						LoadBalancer: &dynamic.ServersLoadBalancer{
+							Servers:        []dynamic.Server{{URL: "unix://" + socket}},
+							PassHostHeader: &test.passHostHeader,
+						},
+					},
+				},
+			}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+
+			handler, err := manager.BuildHTTP(context.Background(), "test@file", nil)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.com/bar?baz=qux", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, test.expected, recorder.Body.String())
+		})
+	}
+}
+
+func TestManager_BuildWithUnixSocketAndDNS(t *testing.T) {
+	manager := NewManager(map[string]*runtime.ServiceInfo{
+		"test@file": {
+			Service: &dynamic.Service{
+				LoadBalancer: &dynamic.ServersLoadBalancer{
+					Servers: []dynamic.Server{{URL: "unix:///run/app.sock", DNS: &dynamic.ServerDNS{Type: "A"}}},
+				},
+			},
+		},
+	}, NewRoundTripperManager(http.DefaultTransport), nil, nil)
+
+	_, err := manager.BuildHTTP(context.Background(), "test@file", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package tcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/affinity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type addrConn struct {
+	WriteCloser
+	remoteAddr net.Addr
+}
+
+func (c addrConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+func clientConn(ip string) WriteCloser {
+	return addrConn{remoteAddr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 4242}}
+}
+
+func newAffinityBalancer(served *string, weights map[string]int) *WRRLoadBalancer {
+	balancer := NewWRRLoadBalancer()
+	for _, name := range []string{"h1", "h2", "h3"} {
+		weight, ok := weights[name]
+		if !ok {
+			continue
+		}
+
+		name := name
+		balancer.AddNamedServer(name, HandlerFunc(func(conn WriteCloser) {
+			*served = name
+		}), &weight)
+	}
+	return balancer
+}
+
+func TestAffinity_hash(t *testing.T) {
+	var served string
+	balancer := newAffinityBalancer(&served, map[string]int{"h1": 1, "h2": 1, "h3": 1})
+	balancer.SetAffinity(nil)
+
+	servers := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		ip := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+
+		balancer.ServeTCP(clientConn(ip))
+		servers[ip] = served
+		counts[served]++
+
+		// The same client always gets the same server.
+		balancer.ServeTCP(clientConn(ip))
+		assert.Equal(t, servers[ip], served)
+	}
+
+	// The clients are spread over the servers.
+	for _, name := range []string{"h1", "h2", "h3"} {
+		assert.Greater(t, counts[name], 50, name)
+	}
+
+	// Only the clients of a server going down are moved.
+	balancer.SetStatus(context.Background(), "h2", false)
+	for ip, server := range servers {
+		balancer.ServeTCP(clientConn(ip))
+		if server == "h2" {
+			assert.NotEqual(t, "h2", served)
+		} else {
+			assert.Equal(t, server, served)
+		}
+	}
+
+	// The clients get their server back once it is up again.
+	balancer.SetStatus(context.Background(), "h2", true)
+	for ip, server := range servers {
+		balancer.ServeTCP(clientConn(ip))
+		assert.Equal(t, server, served)
+	}
+}
+
+func TestAffinity_hashRemovedServer(t *testing.T) {
+	var served string
+	balancer := newAffinityBalancer(&served, map[string]int{"h1": 1, "h2": 1, "h3": 1})
+	balancer.SetAffinity(nil)
+
+	servers := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		ip := fmt.Sprintf("10.0.0.%d", i)
+		balancer.ServeTCP(clientConn(ip))
+		servers[ip] = served
+	}
+
+	// The load-balancer of the new configuration, without h3.
+	balancer = newAffinityBalancer(&served, map[string]int{"h1": 1, "h2": 1})
+	balancer.SetAffinity(nil)
+
+	for ip, server := range servers {
+		balancer.ServeTCP(clientConn(ip))
+		if server != "h3" {
+			assert.Equal(t, server, served)
+		}
+	}
+}
+
+func TestAffinity_table(t *testing.T) {
+	table := affinity.NewTable(time.Minute)
+
+	var served string
+	balancer := newAffinityBalancer(&served, map[string]int{"h1": 1, "h2": 1})
+	balancer.SetAffinity(table)
+
+	balancer.ServeTCP(clientConn("10.0.0.1"))
+	require.Equal(t, "h1", served)
+	balancer.ServeTCP(clientConn("10.0.0.2"))
+	require.Equal(t, "h2", served)
+
+	balancer.ServeTCP(clientConn("10.0.0.1"))
+	assert.Equal(t, "h1", served)
+
+	// The table is shared with the load-balancer of the new configuration.
+	balancer = newAffinityBalancer(&served, map[string]int{"h1": 1, "h2": 1})
+	balancer.SetAffinity(table)
+
+	balancer.ServeTCP(clientConn("10.0.0.1"))
+	assert.Equal(t, "h1", served)
+
+	// A client is moved when its server is down.
+	balancer.SetStatus(context.Background(), "h1", false)
+	balancer.ServeTCP(clientConn("10.0.0.1"))
+	assert.Equal(t, "h2", served)
+
+	balancer.SetStatus(context.Background(), "h1", true)
+	balancer.ServeTCP(clientConn("10.0.0.1"))
+	assert.Equal(t, "h2", served)
+}
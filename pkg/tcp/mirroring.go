@@ -0,0 +1,235 @@
+package tcp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/v2/pkg/log"
+)
+
+// mirrorBufferSize is the maximum amount of data from the client waiting to be sent to a mirror.
+// A mirror lagging further behind is disconnected, so that it never slows down the main connection.
+const mirrorBufferSize = 1 << 20
+
+var errMirrorLagging = errors.New("mirror connection is lagging behind, dropping it")
+
+// Mirroring is a Handler that duplicates the data sent by the clients to mirror handlers,
+// and discards the data sent back by the mirrors.
+type Mirroring struct {
+	handler Handler
+	mirrors []*mirrorHandler
+
+	lock  sync.Mutex
+	total uint64
+}
+
+type mirrorHandler struct {
+	Handler
+	percent int
+	count   uint64
+}
+
+// NewMirroring creates a new Mirroring, sending the connections to handler.
+func NewMirroring(handler Handler) *Mirroring {
+	return &Mirroring{handler: handler}
+}
+
+// AddMirror adds a handler to mirror the given percentage of the connections to.
+func (m *Mirroring) AddMirror(handler Handler, percent int) error {
+	if percent < 0 || percent > 100 {
+		return errors.New("percent must be between 0 and 100")
+	}
+
+	m.mirrors = append(m.mirrors, &mirrorHandler{Handler: handler, percent: percent})
+	return nil
+}
+
+func (m *Mirroring) getActiveMirrors() []Handler {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.total++
+
+	var mirrors []Handler
+	for _, mirror := range m.mirrors {
+		if mirror.count*100 < m.total*uint64(mirror.percent) {
+			mirror.count++
+			mirrors = append(mirrors, mirror)
+		}
+	}
+	return mirrors
+}
+
+// ServeTCP forwards the connection to the main handler, and to the active mirrors.
+func (m *Mirroring) ServeTCP(conn WriteCloser) {
+	mirrors := m.getActiveMirrors()
+	if len(mirrors) == 0 {
+		m.handler.ServeTCP(conn)
+		return
+	}
+
+	tee := &teeConn{WriteCloser: conn}
+	for _, mirror := range mirrors {
+		mc := &mirrorConn{WriteCloser: conn, stream: newMirrorStream()}
+		tee.streams = append(tee.streams, mc.stream)
+
+		go mirror.ServeTCP(mc)
+	}
+
+	m.handler.ServeTCP(tee)
+
+	// The main handler may return without having read the whole stream from the client.
+	tee.closeStreams()
+}
+
+// teeConn copies the data read from the client connection to the streams of the mirrors.
+type teeConn struct {
+	WriteCloser
+
+	streams   []*mirrorStream
+	closeOnce sync.Once
+}
+
+func (c *teeConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	if n > 0 {
+		for _, stream := range c.streams {
+			stream.write(p[:n])
+		}
+	}
+
+	if err != nil {
+		c.closeStreams()
+	}
+
+	return n, err
+}
+
+func (c *teeConn) Close() error {
+	c.closeStreams()
+	return c.WriteCloser.Close()
+}
+
+func (c *teeConn) closeStreams() {
+	c.closeOnce.Do(func() {
+		for _, stream := range c.streams {
+			stream.close(nil)
+		}
+	})
+}
+
+// mirrorStream is the bounded buffer of the data from the client waiting to be sent to a mirror.
+// Writing to it never blocks.
+type mirrorStream struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    bytes.Buffer
+	closed bool
+	err    error
+}
+
+func newMirrorStream() *mirrorStream {
+	s := &mirrorStream{}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *mirrorStream) write(p []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	if s.buf.Len()+len(p) > mirrorBufferSize {
+		s.closed = true
+		s.err = errMirrorLagging
+		s.buf.Reset()
+		s.cond.Broadcast()
+		return
+	}
+
+	s.buf.Write(p)
+	s.cond.Broadcast()
+}
+
+func (s *mirrorStream) read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.buf.Len() == 0 && !s.closed {
+		s.cond.Wait()
+	}
+
+	if s.buf.Len() > 0 {
+		return s.buf.Read(p)
+	}
+
+	if s.err != nil {
+		return 0, s.err
+	}
+	return 0, io.EOF
+}
+
+// close closes the stream, the pending data being still readable unless err is not nil.
+func (s *mirrorStream) close(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+	s.err = err
+	if err != nil {
+		s.buf.Reset()
+	}
+	s.cond.Broadcast()
+}
+
+// mirrorConn is the connection given to a mirror handler:
+// it reads the data sent by the client, and discards the data written to it.
+// Its addresses are the ones of the client connection, which it never closes.
+type mirrorConn struct {
+	WriteCloser
+
+	stream *mirrorStream
+}
+
+func (c *mirrorConn) Read(p []byte) (int, error) {
+	n, err := c.stream.read(p)
+	if errors.Is(err, errMirrorLagging) {
+		log.WithoutContext().Debugf("Error while mirroring connection from %s: %v", c.RemoteAddr(), err)
+	}
+	return n, err
+}
+
+func (c *mirrorConn) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (c *mirrorConn) Close() error {
+	c.stream.close(io.ErrClosedPipe)
+	return nil
+}
+
+func (c *mirrorConn) CloseWrite() error {
+	return nil
+}
+
+func (c *mirrorConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *mirrorConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *mirrorConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
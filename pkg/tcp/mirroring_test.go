@@ -0,0 +1,131 @@
+package tcp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connPair returns the two ends of a TCP connection.
+func connPair(t *testing.T) (net.Conn, *net.TCPConn) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+
+	server, err := listener.Accept()
+	require.NoError(t, err)
+
+	return client, server.(*net.TCPConn)
+}
+
+func TestMirroring(t *testing.T) {
+	main := HandlerFunc(func(conn WriteCloser) {
+		data, err := ioutil.ReadAll(conn)
+		require.NoError(t, err)
+
+		_, _ = conn.Write(append([]byte("main:"), data...))
+		_ = conn.Close()
+	})
+
+	received := make(chan []byte, 1)
+	mirror := HandlerFunc(func(conn WriteCloser) {
+		data, err := ioutil.ReadAll(conn)
+		require.NoError(t, err)
+
+		// The data sent by the mirror is discarded.
+		_, err = conn.Write([]byte("mirror"))
+		require.NoError(t, err)
+
+		received <- data
+	})
+
+	mirroring := NewMirroring(main)
+	require.NoError(t, mirroring.AddMirror(mirror, 100))
+
+	client, server := connPair(t)
+	go mirroring.ServeTCP(server)
+
+	_, err := client.Write([]byte("ping"))
+	require.NoError(t, err)
+	require.NoError(t, client.(*net.TCPConn).CloseWrite())
+
+	response, err := ioutil.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, "main:ping", string(response))
+
+	select {
+	case data := <-received:
+		assert.Equal(t, "ping", string(data))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the connection has not been mirrored")
+	}
+}
+
+func TestMirroring_percent(t *testing.T) {
+	var countMirror int
+	mirroring := NewMirroring(HandlerFunc(func(conn WriteCloser) {}))
+	require.NoError(t, mirroring.AddMirror(HandlerFunc(func(conn WriteCloser) {}), 10))
+
+	for i := 0; i < 100; i++ {
+		if len(mirroring.getActiveMirrors()) > 0 {
+			countMirror++
+		}
+	}
+
+	assert.Equal(t, 10, countMirror)
+	assert.Error(t, mirroring.AddMirror(nil, 101))
+	assert.Error(t, mirroring.AddMirror(nil, -1))
+}
+
+func TestMirroring_laggingMirror(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 2*mirrorBufferSize)
+
+	main := HandlerFunc(func(conn WriteCloser) {
+		data, err := ioutil.ReadAll(conn)
+		require.NoError(t, err)
+
+		_, _ = conn.Write([]byte{byte(len(data) / mirrorBufferSize)})
+		_ = conn.Close()
+	})
+
+	unblock := make(chan struct{})
+	mirrorErr := make(chan error, 1)
+	mirror := HandlerFunc(func(conn WriteCloser) {
+		<-unblock
+		_, err := ioutil.ReadAll(conn)
+		mirrorErr <- err
+	})
+
+	mirroring := NewMirroring(main)
+	require.NoError(t, mirroring.AddMirror(mirror, 100))
+
+	client, server := connPair(t)
+	go mirroring.ServeTCP(server)
+
+	// The main connection goes on, while the mirror does not read anything.
+	_, err := client.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, client.(*net.TCPConn).CloseWrite())
+
+	response, err := ioutil.ReadAll(client)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2}, response)
+
+	close(unblock)
+	select {
+	case err := <-mirrorErr:
+		assert.Equal(t, errMirrorLagging, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the mirror has not been dropped")
+	}
+}
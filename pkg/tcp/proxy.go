@@ -1,27 +1,55 @@
 package tcp
 
 import (
+	"fmt"
 	"io"
 	"net"
+	"strings"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
 	"github.com/containous/traefik/v2/pkg/log"
 )
 
+// unixSocketPrefix starts the addresses of the servers listening on a unix socket, such as unix:///run/app.sock.
+const unixSocketPrefix = "unix://"
+
+// SplitNetworkAddress returns the network and the address to dial for the given server address,
+// which is either a TCP address, or the URL of a unix socket such as unix:///run/app.sock.
+func SplitNetworkAddress(address string) (network, addr string) {
+	if strings.HasPrefix(address, unixSocketPrefix) {
+		return "unix", strings.TrimPrefix(address, unixSocketPrefix)
+	}
+	return "tcp", address
+}
+
 // Proxy forwards a TCP request to a TCP service.
 type Proxy struct {
-	target           *net.TCPAddr
+	target           net.Addr
 	terminationDelay time.Duration
+	proxyProtocol    *dynamic.ProxyProtocol
 }
 
 // NewProxy creates a new Proxy.
-func NewProxy(address string, terminationDelay time.Duration) (*Proxy, error) {
-	tcpAddr, err := net.ResolveTCPAddr("tcp", address)
+// If proxyProtocol is not nil, a PROXY protocol header is sent to the backend on each connection.
+func NewProxy(address string, terminationDelay time.Duration, proxyProtocol *dynamic.ProxyProtocol) (*Proxy, error) {
+	if proxyProtocol != nil && (proxyProtocol.Version < 1 || proxyProtocol.Version > 2) {
+		return nil, fmt.Errorf("unknown PROXY protocol version %d", proxyProtocol.Version)
+	}
+
+	var target net.Addr
+	var err error
+	switch network, addr := SplitNetworkAddress(address); network {
+	case "unix":
+		target, err = net.ResolveUnixAddr(network, addr)
+	default:
+		target, err = net.ResolveTCPAddr(network, addr)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &Proxy{target: tcpAddr, terminationDelay: terminationDelay}, nil
+	return &Proxy{target: target, terminationDelay: terminationDelay, proxyProtocol: proxyProtocol}, nil
 }
 
 // ServeTCP forwards the connection to a service.
@@ -31,7 +59,7 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	// needed because of e.g. server.trackedConnection
 	defer conn.Close()
 
-	connBackend, err := net.DialTCP("tcp", nil, p.target)
+	connBackend, err := p.dialBackend()
 	if err != nil {
 		log.Errorf("Error while connection to backend: %v", err)
 		return
@@ -40,6 +68,13 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	// maybe not needed, but just in case
 	defer connBackend.Close()
 
+	if p.proxyProtocol != nil {
+		if err := WriteProxyProtocolHeader(connBackend, p.proxyProtocol.Version, conn.RemoteAddr(), conn.LocalAddr()); err != nil {
+			log.WithoutContext().Errorf("Error while writing PROXY protocol header: %v", err)
+			return
+		}
+	}
+
 	errChan := make(chan error)
 	go p.connCopy(conn, connBackend, errChan)
 	go p.connCopy(connBackend, conn, errChan)
@@ -52,6 +87,16 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	<-errChan
 }
 
+func (p Proxy) dialBackend() (WriteCloser, error) {
+	conn, err := net.Dial(p.target.Network(), p.target.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Both the TCP and unix connections can be closed for writing only.
+	return conn.(WriteCloser), nil
+}
+
 func (p Proxy) connCopy(dst, src WriteCloser, errCh chan error) {
 	_, err := io.Copy(dst, src)
 	errCh <- err
@@ -1,13 +1,17 @@
 package tcp
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io"
 	"net"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/containous/traefik/v2/pkg/config/dynamic"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -46,7 +50,7 @@ func TestCloseWrite(t *testing.T) {
 	_, port, err := net.SplitHostPort(backendListener.Addr().String())
 	require.NoError(t, err)
 
-	proxy, err := NewProxy(":"+port, 10*time.Millisecond)
+	proxy, err := NewProxy(":"+port, 10*time.Millisecond, nil)
 	require.NoError(t, err)
 
 	proxyListener, err := net.Listen("tcp", ":0")
@@ -79,3 +83,110 @@ func TestCloseWrite(t *testing.T) {
 	require.Equal(t, int64(4), n)
 	require.Equal(t, "PONG", buffer.String())
 }
+
+func TestProxyProtocol(t *testing.T) {
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer backendListener.Close()
+
+	headers := make(chan string, 1)
+	go func() {
+		conn, err := backendListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		header, _ := bufio.NewReader(conn).ReadString('\n')
+		headers <- header
+	}()
+
+	proxy, err := NewProxy(backendListener.Addr().String(), 10*time.Millisecond, &dynamic.ProxyProtocol{Version: 1})
+	require.NoError(t, err)
+
+	proxyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer proxyListener.Close()
+
+	go func() {
+		conn, err := proxyListener.Accept()
+		if err != nil {
+			return
+		}
+		proxy.ServeTCP(conn.(*net.TCPConn))
+	}()
+
+	conn, err := net.Dial("tcp", proxyListener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	clientAddr := conn.LocalAddr().(*net.TCPAddr)
+	proxyAddr := proxyListener.Addr().(*net.TCPAddr)
+
+	select {
+	case header := <-headers:
+		assert.Equal(t, fmt.Sprintf("PROXY TCP4 127.0.0.1 127.0.0.1 %d %d\r\n", clientAddr.Port, proxyAddr.Port), header)
+	case <-time.After(time.Second):
+		t.Fatal("no PROXY protocol header received")
+	}
+}
+
+func TestNewProxy_invalidProxyProtocolVersion(t *testing.T) {
+	_, err := NewProxy("127.0.0.1:80", 0, &dynamic.ProxyProtocol{Version: 3})
+	assert.Error(t, err)
+}
+
+func TestProxy_unixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+
+	backendListener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer backendListener.Close()
+
+	go func() {
+		conn, err := backendListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	proxy, err := NewProxy("unix://"+socket, 10*time.Millisecond, nil)
+	require.NoError(t, err)
+
+	proxyListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer proxyListener.Close()
+
+	go func() {
+		conn, err := proxyListener.Accept()
+		if err != nil {
+			return
+		}
+		proxy.ServeTCP(conn.(*net.TCPConn))
+	}()
+
+	conn, err := net.Dial("tcp", proxyListener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+}
+
+func TestSplitNetworkAddress(t *testing.T) {
+	network, addr := SplitNetworkAddress("unix:///run/app.sock")
+	assert.Equal(t, "unix", network)
+	assert.Equal(t, "/run/app.sock", addr)
+
+	network, addr = SplitNetworkAddress("127.0.0.1:80")
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "127.0.0.1:80", addr)
+}
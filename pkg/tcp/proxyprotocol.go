@@ -0,0 +1,113 @@
+package tcp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// proxyProtocolV2Signature starts every PROXY protocol version 2 header.
+var proxyProtocolV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// WriteProxyProtocolHeader writes the PROXY protocol header of the given version (1 or 2),
+// describing a connection from the client address src to the proxy address dst.
+// When the addresses are not both TCP addresses, the header tells that the connection is not relayed from a client.
+func WriteProxyProtocolHeader(w io.Writer, version int, src, dst net.Addr) error {
+	var header []byte
+	switch version {
+	case 1:
+		header = proxyProtocolV1Header(src, dst)
+	case 2:
+		header = proxyProtocolV2Header(src, dst)
+	default:
+		return fmt.Errorf("unknown PROXY protocol version %d", version)
+	}
+
+	_, err := w.Write(header)
+	return err
+}
+
+func proxyProtocolV1Header(src, dst net.Addr) []byte {
+	addrs, ok := newProxiedAddrs(src, dst)
+	if !ok {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	if !addrs.ipv6 {
+		return []byte(fmt.Sprintf("PROXY TCP4 %s %s %d %d\r\n", addrs.srcIP, addrs.dstIP, addrs.srcPort, addrs.dstPort))
+	}
+
+	return []byte(fmt.Sprintf("PROXY TCP6 %s %s %d %d\r\n", ipv6String(addrs.srcIP), ipv6String(addrs.dstIP), addrs.srcPort, addrs.dstPort))
+}
+
+func proxyProtocolV2Header(src, dst net.Addr) []byte {
+	buf := bytes.NewBuffer(proxyProtocolV2Signature[:len(proxyProtocolV2Signature):len(proxyProtocolV2Signature)])
+
+	addrs, ok := newProxiedAddrs(src, dst)
+	if !ok {
+		// LOCAL command, without addresses.
+		buf.Write([]byte{0x20, 0x00, 0x00, 0x00})
+		return buf.Bytes()
+	}
+
+	// PROXY command, over TCP, from an IPv4 or IPv6 address.
+	buf.WriteByte(0x21)
+	if addrs.ipv6 {
+		buf.WriteByte(0x21)
+	} else {
+		buf.WriteByte(0x11)
+	}
+
+	_ = binary.Write(buf, binary.BigEndian, uint16(2*len(addrs.srcIP)+4))
+	buf.Write(addrs.srcIP)
+	buf.Write(addrs.dstIP)
+	_ = binary.Write(buf, binary.BigEndian, uint16(addrs.srcPort))
+	_ = binary.Write(buf, binary.BigEndian, uint16(addrs.dstPort))
+
+	return buf.Bytes()
+}
+
+// proxiedAddrs are the addresses of a proxied connection, in the same IP family.
+type proxiedAddrs struct {
+	srcIP, dstIP     net.IP
+	srcPort, dstPort int
+	ipv6             bool
+}
+
+// newProxiedAddrs returns the given addresses, if they are TCP addresses,
+// as 4 bytes IPv4 addresses, or as 16 bytes IPv6 addresses if any of them is an IPv6 one.
+func newProxiedAddrs(src, dst net.Addr) (proxiedAddrs, bool) {
+	srcAddr, ok := src.(*net.TCPAddr)
+	if !ok || srcAddr.IP == nil {
+		return proxiedAddrs{}, false
+	}
+
+	dstAddr, ok := dst.(*net.TCPAddr)
+	if !ok || dstAddr.IP == nil {
+		return proxiedAddrs{}, false
+	}
+
+	addrs := proxiedAddrs{
+		srcIP:   srcAddr.IP.To4(),
+		dstIP:   dstAddr.IP.To4(),
+		srcPort: srcAddr.Port,
+		dstPort: dstAddr.Port,
+	}
+
+	if addrs.srcIP == nil || addrs.dstIP == nil {
+		addrs.srcIP, addrs.dstIP = srcAddr.IP.To16(), dstAddr.IP.To16()
+		addrs.ipv6 = true
+	}
+
+	return addrs, true
+}
+
+// ipv6String formats the given IP in the IPv6 notation, even if it is an IPv4-mapped address.
+func ipv6String(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return "::ffff:" + ip4.String()
+	}
+	return ip.String()
+}
@@ -0,0 +1,110 @@
+package tcp
+
+import (
+	"bufio"
+	"bytes"
+	"log"
+	"net"
+	"testing"
+
+	proxyprotocol "github.com/c0va23/go-proxyprotocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteProxyProtocolHeader(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		version     int
+		src         net.Addr
+		dst         net.Addr
+		expected    string
+		expectedSrc net.Addr
+		expectedDst net.Addr
+	}{
+		{
+			desc:        "version 1 with IPv4 addresses",
+			version:     1,
+			src:         &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			dst:         &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 443},
+			expected:    "PROXY TCP4 10.0.0.1 10.0.0.2 1234 443\r\n",
+			expectedSrc: &net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 1234},
+			expectedDst: &net.TCPAddr{IP: net.ParseIP("10.0.0.2").To4(), Port: 443},
+		},
+		{
+			desc:        "version 1 with IPv4 and IPv6 addresses",
+			version:     1,
+			src:         &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			dst:         &net.TCPAddr{IP: net.ParseIP("::1"), Port: 443},
+			expected:    "PROXY TCP6 ::ffff:10.0.0.1 ::1 1234 443\r\n",
+			expectedSrc: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			expectedDst: &net.TCPAddr{IP: net.ParseIP("::1"), Port: 443},
+		},
+		{
+			desc:     "version 1 with unknown addresses",
+			version:  1,
+			src:      &net.UnixAddr{Name: "foo", Net: "unix"},
+			dst:      &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 443},
+			expected: "PROXY UNKNOWN\r\n",
+		},
+		{
+			desc:        "version 2 with IPv4 addresses",
+			version:     2,
+			src:         &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			dst:         &net.TCPAddr{IP: net.ParseIP("10.0.0.2"), Port: 443},
+			expected:    "\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x0c\x0a\x00\x00\x01\x0a\x00\x00\x02\x04\xd2\x01\xbb",
+			expectedSrc: &net.TCPAddr{IP: net.ParseIP("10.0.0.1").To4(), Port: 1234},
+			expectedDst: &net.TCPAddr{IP: net.ParseIP("10.0.0.2").To4(), Port: 443},
+		},
+		{
+			desc:        "version 2 with IPv6 addresses",
+			version:     2,
+			src:         &net.TCPAddr{IP: net.ParseIP("::1"), Port: 1234},
+			dst:         &net.TCPAddr{IP: net.ParseIP("::2"), Port: 443},
+			expectedSrc: &net.TCPAddr{IP: net.ParseIP("::1"), Port: 1234},
+			expectedDst: &net.TCPAddr{IP: net.ParseIP("::2"), Port: 443},
+		},
+		{
+			desc:     "version 2 with unknown addresses",
+			version:  2,
+			src:      &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			dst:      &net.UnixAddr{Name: "foo", Net: "unix"},
+			expected: "\r\n\r\n\x00\r\nQUIT\n\x20\x00\x00\x00",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := WriteProxyProtocolHeader(&buf, test.version, test.src, test.dst)
+			require.NoError(t, err)
+
+			if test.expected != "" {
+				assert.Equal(t, test.expected, buf.String())
+			}
+
+			// The header is parsed back as the entry points do.
+			logger := log.New(&bytes.Buffer{}, "", 0)
+			parser := proxyprotocol.NewFallbackHeaderParser(logger, proxyprotocol.NewTextHeaderParser(logger), proxyprotocol.NewBinaryHeaderParser(logger))
+			header, err := parser.Parse(bufio.NewReader(&buf))
+			require.NoError(t, err)
+
+			if test.expectedSrc == nil {
+				assert.Nil(t, header)
+				return
+			}
+
+			require.NotNil(t, header)
+			assert.Equal(t, test.expectedSrc.String(), header.SrcAddr.String())
+			assert.Equal(t, test.expectedDst.String(), header.DstAddr.String())
+		})
+	}
+}
+
+func TestWriteProxyProtocolHeader_unknownVersion(t *testing.T) {
+	err := WriteProxyProtocolHeader(&bytes.Buffer{}, 3, &net.TCPAddr{}, &net.TCPAddr{})
+	assert.Error(t, err)
+}
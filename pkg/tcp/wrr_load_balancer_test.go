@@ -1,6 +1,7 @@
 package tcp
 
 import (
+	"context"
 	"net"
 	"testing"
 	"time"
@@ -129,3 +130,72 @@ func TestLoadBalancing(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadBalancing_serverDown(t *testing.T) {
+	balancer := NewWRRLoadBalancer()
+	for _, server := range []string{"h1", "h2"} {
+		server := server
+		balancer.AddNamedServer(server, HandlerFunc(func(conn WriteCloser) {
+			_, err := conn.Write([]byte(server))
+			require.NoError(t, err)
+		}), nil)
+	}
+
+	balancer.SetStatus(context.Background(), "h1", false)
+
+	conn := &fakeConn{call: make(map[string]int)}
+	for i := 0; i < 4; i++ {
+		balancer.ServeTCP(conn)
+	}
+	assert.Equal(t, map[string]int{"h2": 4}, conn.call)
+
+	balancer.SetStatus(context.Background(), "h1", true)
+
+	conn = &fakeConn{call: make(map[string]int)}
+	for i := 0; i < 4; i++ {
+		balancer.ServeTCP(conn)
+	}
+	assert.Equal(t, map[string]int{"h1": 2, "h2": 2}, conn.call)
+}
+
+func TestLoadBalancing_allServersDown(t *testing.T) {
+	balancer := NewWRRLoadBalancer()
+	balancer.AddNamedServer("h1", HandlerFunc(func(conn WriteCloser) {
+		t.Error("the server is down and should not be called")
+	}), nil)
+
+	balancer.SetStatus(context.Background(), "h1", false)
+
+	conn := &closedConn{}
+	balancer.ServeTCP(conn)
+	assert.True(t, conn.closed)
+}
+
+type closedConn struct {
+	fakeConn
+	closed bool
+}
+
+func (c *closedConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestLoadBalancing_removeServer(t *testing.T) {
+	balancer := NewWRRLoadBalancer()
+	for _, server := range []string{"h1", "h2"} {
+		server := server
+		balancer.AddNamedServer(server, HandlerFunc(func(conn WriteCloser) {
+			_, err := conn.Write([]byte(server))
+			require.NoError(t, err)
+		}), nil)
+	}
+
+	balancer.RemoveServer("h1")
+
+	conn := &fakeConn{call: make(map[string]int)}
+	for i := 0; i < 4; i++ {
+		balancer.ServeTCP(conn)
+	}
+	assert.Equal(t, map[string]int{"h2": 4}, conn.call)
+}
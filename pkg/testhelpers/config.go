@@ -7,7 +7,8 @@ import (
 // BuildConfiguration is a helper to create a configuration.
 func BuildConfiguration(dynamicConfigBuilders ...func(*dynamic.HTTPConfiguration)) *dynamic.HTTPConfiguration {
 	conf := &dynamic.HTTPConfiguration{
-		Models: map[string]*dynamic.Model{},
+		Models:            map[string]*dynamic.Model{},
+		ServersTransports: map[string]*dynamic.ServersTransport{},
 	}
 
 	for _, build := range dynamicConfigBuilders {
@@ -132,18 +132,9 @@ func (c *Certificates) isEmpty() bool {
 
 // AppendCertificate appends a Certificate to a certificates map keyed by entrypoint.
 func (c *Certificate) AppendCertificate(certs map[string]map[string]*tls.Certificate, ep string) error {
-	certContent, err := c.CertFile.Read()
-	if err != nil {
-		return fmt.Errorf("unable to read CertFile : %w", err)
-	}
-
-	keyContent, err := c.KeyFile.Read()
-	if err != nil {
-		return fmt.Errorf("unable to read KeyFile : %w", err)
-	}
-	tlsCert, err := tls.X509KeyPair(certContent, keyContent)
+	tlsCert, err := c.GetCertificate()
 	if err != nil {
-		return fmt.Errorf("unable to generate TLS certificate : %w", err)
+		return err
 	}
 
 	parsedCert, _ := x509.ParseCertificate(tlsCert.Certificate[0])
@@ -190,6 +181,40 @@ func (c *Certificate) AppendCertificate(certs map[string]map[string]*tls.Certifi
 	return err
 }
 
+// GetCertificate returns the TLS certificate built from the cert/key pair.
+func (c *Certificate) GetCertificate() (tls.Certificate, error) {
+	certContent, err := c.CertFile.Read()
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to read CertFile : %w", err)
+	}
+
+	keyContent, err := c.KeyFile.Read()
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to read KeyFile : %w", err)
+	}
+
+	tlsCert, err := tls.X509KeyPair(certContent, keyContent)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to generate TLS certificate : %w", err)
+	}
+
+	return tlsCert, nil
+}
+
+// GetCertificates returns the TLS certificates built from the cert/key pairs,
+// e.g. to be presented as client certificates.
+func (c Certificates) GetCertificates() ([]tls.Certificate, error) {
+	var certs []tls.Certificate
+	for _, certificate := range c {
+		cert, err := certificate.GetCertificate()
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
 // GetTruncatedCertificateName truncates the certificate name.
 func (c *Certificate) GetTruncatedCertificateName() string {
 	certName := c.CertFile.String()
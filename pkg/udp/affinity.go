@@ -0,0 +1,32 @@
+package udp
+
+import "github.com/containous/traefik/v2/pkg/affinity"
+
+// SetAffinity makes the load-balancer send the sessions of a client, identified by its IP, to the same server,
+// as long as this server is up.
+// With a nil table, the server is chosen from a hash of the client IP, so that the clients only move when their server goes away.
+// Otherwise, the server chosen by round robin for the first session of a client is recorded in the table.
+func (b *WRRLoadBalancer) SetAffinity(table *affinity.Table) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.affinity = true
+	b.affinityTable = table
+}
+
+func (b *WRRLoadBalancer) nextForClient(ip string) (Handler, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	index, err := affinity.Choose(b.affinityTable, ip, len(b.servers), b.affinityServer, b.nextIndex)
+	if err != nil {
+		return nil, err
+	}
+	return b.servers[index], nil
+}
+
+// affinityServer must be called with the lock held.
+func (b *WRRLoadBalancer) affinityServer(index int) affinity.Server {
+	s := b.servers[index]
+	return affinity.Server{Name: s.name, Weight: s.weight, Up: b.isUp(s)}
+}
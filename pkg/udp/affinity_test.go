@@ -0,0 +1,36 @@
+package udp
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffinity(t *testing.T) {
+	var served string
+	balancer := NewWRRLoadBalancer()
+	for _, name := range []string{"h1", "h2"} {
+		name := name
+		balancer.AddNamedServer(name, HandlerFunc(func(conn *Conn) {
+			served = name
+		}), nil)
+	}
+	balancer.SetAffinity(nil)
+
+	conn := &Conn{rAddr: &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4242}}
+
+	balancer.ServeUDP(conn)
+	first := served
+
+	for i := 0; i < 10; i++ {
+		balancer.ServeUDP(conn)
+		assert.Equal(t, first, served)
+	}
+
+	// The client is moved when its server is down.
+	balancer.SetStatus(context.Background(), first, false)
+	balancer.ServeUDP(conn)
+	assert.NotEqual(t, first, served)
+}
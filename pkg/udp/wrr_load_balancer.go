@@ -1,14 +1,17 @@
 package udp
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
+	"github.com/containous/traefik/v2/pkg/affinity"
 	"github.com/containous/traefik/v2/pkg/log"
 )
 
 type server struct {
 	Handler
+	name   string
 	weight int
 }
 
@@ -18,26 +21,46 @@ type WRRLoadBalancer struct {
 	lock          sync.RWMutex
 	currentWeight int
 	index         int
+
+	// down holds the names of the servers marked as down, which are skipped.
+	down map[string]struct{}
+
+	// affinity makes the sessions of a client go to the same server,
+	// chosen from a hash of the client IP, or recorded in affinityTable if it is not nil.
+	affinity      bool
+	affinityTable *affinity.Table
 }
 
 // NewWRRLoadBalancer creates a new WRRLoadBalancer.
 func NewWRRLoadBalancer() *WRRLoadBalancer {
 	return &WRRLoadBalancer{
 		index: -1,
+		down:  make(map[string]struct{}),
 	}
 }
 
 // ServeUDP forwards the connection to the right service.
 func (b *WRRLoadBalancer) ServeUDP(conn *Conn) {
-	if len(b.servers) == 0 {
+	b.lock.RLock()
+	empty := len(b.servers) == 0
+	b.lock.RUnlock()
+
+	if empty {
 		log.WithoutContext().Error("no available server")
 		return
 	}
 
-	next, err := b.next()
+	var next Handler
+	var err error
+	if b.affinity {
+		next, err = b.nextForClient(affinity.ClientIP(conn.rAddr))
+	} else {
+		next, err = b.next()
+	}
 	if err != nil {
 		log.WithoutContext().Errorf("Error during load balancing: %v", err)
 		conn.Close()
+		return
 	}
 	next.ServeUDP(conn)
 }
@@ -50,17 +73,68 @@ func (b *WRRLoadBalancer) AddServer(serverHandler Handler) {
 
 // AddWeightedServer appends a handler to the existing list with a weight.
 func (b *WRRLoadBalancer) AddWeightedServer(serverHandler Handler, weight *int) {
+	b.AddNamedServer("", serverHandler, weight)
+}
+
+// AddNamedServer appends a handler to the existing list with a weight,
+// under a name that can be used to change its status with SetStatus.
+func (b *WRRLoadBalancer) AddNamedServer(name string, serverHandler Handler, weight *int) {
 	w := 1
 	if weight != nil {
 		w = *weight
 	}
-	b.servers = append(b.servers, server{Handler: serverHandler, weight: w})
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.servers = append(b.servers, server{Handler: serverHandler, name: name, weight: w})
+}
+
+// RemoveServer removes the servers added under the given name.
+func (b *WRRLoadBalancer) RemoveServer(name string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	servers := make([]server, 0, len(b.servers))
+	for _, s := range b.servers {
+		if s.name != name {
+			servers = append(servers, s)
+		}
+	}
+
+	b.servers = servers
+	delete(b.down, name)
+}
+
+// SetStatus sets the status (UP or DOWN) of the servers added under the given name.
+// The servers marked as down are skipped until they are back up.
+func (b *WRRLoadBalancer) SetStatus(ctx context.Context, name string, up bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	_, wasDown := b.down[name]
+	if wasDown != up {
+		return
+	}
+
+	log.FromContext(ctx).Debugf("Server %s status changed, up: %v", name, up)
+
+	if up {
+		delete(b.down, name)
+		return
+	}
+	b.down[name] = struct{}{}
+}
+
+func (b *WRRLoadBalancer) isUp(s server) bool {
+	_, down := b.down[s.name]
+	return !down
 }
 
 func (b *WRRLoadBalancer) maxWeight() int {
 	max := -1
 	for _, s := range b.servers {
-		if s.weight > max {
+		if b.isUp(s) && s.weight > max {
 			max = s.weight
 		}
 	}
@@ -70,6 +144,9 @@ func (b *WRRLoadBalancer) maxWeight() int {
 func (b *WRRLoadBalancer) weightGcd() int {
 	divisor := -1
 	for _, s := range b.servers {
+		if !b.isUp(s) {
+			continue
+		}
 		if divisor == -1 {
 			divisor = s.weight
 		} else {
@@ -90,8 +167,17 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
+	index, err := b.nextIndex()
+	if err != nil {
+		return nil, err
+	}
+	return b.servers[index], nil
+}
+
+// nextIndex must be called with the lock held.
+func (b *WRRLoadBalancer) nextIndex() (int, error) {
 	if len(b.servers) == 0 {
-		return nil, fmt.Errorf("no servers in the pool")
+		return -1, fmt.Errorf("no servers in the pool")
 	}
 
 	// The algorithm below may look messy,
@@ -102,6 +188,9 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 	gcd := b.weightGcd()
 	// Maximum weight across all enabled servers
 	max := b.maxWeight()
+	if max == -1 {
+		return -1, fmt.Errorf("all servers are down")
+	}
 
 	for {
 		b.index = (b.index + 1) % len(b.servers)
@@ -110,13 +199,13 @@ func (b *WRRLoadBalancer) next() (Handler, error) {
 			if b.currentWeight <= 0 {
 				b.currentWeight = max
 				if b.currentWeight == 0 {
-					return nil, fmt.Errorf("all servers have 0 weight")
+					return -1, fmt.Errorf("all servers have 0 weight")
 				}
 			}
 		}
 		srv := b.servers[b.index]
-		if srv.weight >= b.currentWeight {
-			return srv, nil
+		if b.isUp(srv) && srv.weight >= b.currentWeight {
+			return b.index, nil
 		}
 	}
 }